@@ -0,0 +1,103 @@
+package sqlr
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+)
+
+// AggSpec describes the aggregate columns for SelectAggregates. Each key is
+// the name of a result column, and its value is the aggregate expression, eg
+//  AggSpec{
+//      "total_amount": "sum(amount)",
+//      "count":        "count(*)",
+//  }
+type AggSpec map[string]string
+
+// SelectAggregates executes a SELECT query that returns a single row of aggregate
+// values from the table for the row type, and returns the values in a map indexed
+// by the keys of aggSpec. The row argument is used only to determine the table name
+// and for renaming identifiers. The table name is obtained from the row type's
+// TableName() method if it has one, otherwise it is the name of the row type
+// converted using the schema's naming convention.
+//
+// The where argument is the contents of the where clause, without the "where"
+// keyword, and can be empty. For example:
+//  totals, err := schema.SelectAggregates(db, OrderRow{}, AggSpec{
+//      "total_amount": "sum(amount)",
+//      "count":        "count(*)",
+//  }, "customer_id = ?", customerID)
+//
+// Values of type []byte are returned as strings, because many drivers return
+// numeric values as text.
+func (s *Schema) SelectAggregates(db DB, row interface{}, aggSpec AggSpec, where string, args ...interface{}) (map[string]interface{}, error) {
+	if len(aggSpec) == 0 {
+		return nil, errors.New("no aggregate columns")
+	}
+	rowType, err := inferRowType(row)
+	if err != nil {
+		return nil, err
+	}
+
+	// sort the names so that the query is the same every time
+	names := make([]string, 0, len(aggSpec))
+	for name := range aggSpec {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dialect := s.getDialect()
+	var buf bytes.Buffer
+	buf.WriteString("select ")
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(aggSpec[name])
+		buf.WriteString(" as ")
+		buf.WriteString(s.quoteMode.quote(dialect, name))
+	}
+	buf.WriteString(" from ")
+	buf.WriteString(s.tableName(rowType))
+	if where != "" {
+		buf.WriteString(" where ")
+		buf.WriteString(where)
+	}
+
+	stmt, err := s.Prepare(row, buf.String())
+	if err != nil {
+		return nil, err
+	}
+	query, err := stmt.expandQuery(stmt.query, args)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := query.QueryOn(db)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]interface{}, len(names))
+	scanValues := make([]interface{}, len(names))
+	for i := range values {
+		scanValues[i] = &values[i]
+	}
+	result := make(map[string]interface{}, len(names))
+	if rows.Next() {
+		if err := rows.Scan(scanValues...); err != nil {
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		if b, ok := values[i].([]byte); ok {
+			result[name] = string(b)
+		} else {
+			result[name] = values[i]
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package sqlr
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestSelectAggregates(t *testing.T) {
+	type OrderRow struct {
+		ID         int `sql:"primary key"`
+		CustomerID int
+		Amount     float64
+		Price      float64
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`select avg(price) as "avg_price", count(*) as "count", sum(amount) as "total_amount" from order_row where customer_id = $1`)).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"avg_price", "count", "total_amount"}).AddRow([]byte("2.5"), int64(4), 10.0))
+
+	got, err := schema.SelectAggregates(db, OrderRow{}, AggSpec{
+		"total_amount": "sum(amount)",
+		"avg_price":    "avg(price)",
+		"count":        "count(*)",
+	}, "customer_id = ?", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"avg_price":    "2.5",
+		"count":        int64(4),
+		"total_amount": 10.0,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`select count(*) as "count" from order_row`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	if _, err := schema.SelectAggregates(db, OrderRow{}, AggSpec{"count": "count(*)"}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := schema.SelectAggregates(db, OrderRow{}, nil, ""); err == nil {
+		t.Error("want error for empty aggregate spec, got nil")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,43 @@
+package sqlr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// coerceArg converts arg to the kind of value expected by typ, if arg is a
+// string and typ is an integer, floating point or boolean type. Otherwise arg
+// is returned unchanged. If typ is nil, the expected type is not known.
+func coerceArg(arg interface{}, typ reflect.Type) (interface{}, error) {
+	s, ok := arg.(string)
+	if !ok || typ == nil {
+		return arg, nil
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	text := strings.TrimSpace(s)
+	var v interface{}
+	var err error
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err = strconv.ParseInt(text, 10, typ.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err = strconv.ParseUint(text, 10, typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		v, err = strconv.ParseFloat(text, typ.Bits())
+	case reflect.Bool:
+		v, err = strconv.ParseBool(text)
+	default:
+		return arg, nil
+	}
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok {
+			err = numErr.Err
+		}
+		return nil, fmt.Errorf("cannot convert %q to %s: %v", s, typ, err)
+	}
+	return v, nil
+}
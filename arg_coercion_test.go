@@ -0,0 +1,105 @@
+package sqlr
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestCoerceArg(t *testing.T) {
+	var (
+		intPtr *int
+		u8     uint8
+		f32    float32
+		b      bool
+		s      string
+	)
+	tests := []struct {
+		arg     interface{}
+		typ     reflect.Type
+		want    interface{}
+		wantErr string
+	}{
+		{arg: "42", typ: reflect.TypeOf(0), want: int64(42)},
+		{arg: " -7 ", typ: reflect.TypeOf(intPtr), want: int64(-7)},
+		{arg: "255", typ: reflect.TypeOf(u8), want: uint64(255)},
+		{arg: "1.5", typ: reflect.TypeOf(f32), want: float64(1.5)},
+		{arg: "true", typ: reflect.TypeOf(b), want: true},
+		{arg: "abc", typ: reflect.TypeOf(s), want: "abc"},
+		{arg: "abc", typ: nil, want: "abc"},
+		{arg: 42, typ: reflect.TypeOf(s), want: 42},
+		{arg: "abc", typ: reflect.TypeOf(0), wantErr: `cannot convert "abc" to int: invalid syntax`},
+		{arg: "256", typ: reflect.TypeOf(u8), wantErr: `cannot convert "256" to uint8: value out of range`},
+		{arg: "yes", typ: reflect.TypeOf(b), wantErr: `cannot convert "yes" to bool: invalid syntax`},
+	}
+	for i, tt := range tests {
+		got, err := coerceArg(tt.arg, tt.typ)
+		if tt.wantErr != "" {
+			if err == nil {
+				t.Errorf("%d: want error %q, got nil", i, tt.wantErr)
+			} else if got := err.Error(); got != tt.wantErr {
+				t.Errorf("%d: want=%q, got=%q", i, tt.wantErr, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d: want=%#v, got=%#v", i, tt.want, got)
+		}
+	}
+}
+
+func TestWithArgCoercion(t *testing.T) {
+	type Row struct {
+		ID     int `sql:"primary key"`
+		Name   string
+		Active bool
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL), WithArgCoercion())
+
+	mock.ExpectQuery("select `id`,`name`,`active` from rows where `id`=\\?").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active"}).AddRow(1, "one", true))
+
+	var row Row
+	if _, err := schema.Select(db, &row, "select {} from rows where {}", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("select r.`id`,r.`name`,r.`active` from rows r where r.name = ? and r.active = ? and id >= ?")).
+		WithArgs("two", true, int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active"}))
+
+	var rows []Row
+	query := "select {alias r} from rows r where r.name = ? and r.active = ? and id >= ?"
+	if _, err := schema.Select(db, &rows, query, "two", "true", "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("update rows set `name`=?,`active`=? where `id`=? and active = ?")).
+		WithArgs("one", true, 1, false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if _, err := schema.Exec(db, &row, "update rows set {} where {} and active = ?", "false"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = schema.Select(db, &rows, "select {} from rows where id = ?", "x")
+	if got, want := err.Error(), `arg 1: cannot convert "x" to int: invalid syntax`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,60 @@
+package sqlr
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+
+	"github.com/jjeffery/sqlr/private/wherein"
+)
+
+// AsValue marks an arg to be passed to the database as a single value,
+// instead of being expanded into a list of values. By default a slice arg
+// is expanded, so that it can be used in an "in (?)" clause. AsValue is for
+// slice args that are intended as a single value, such as a JSON column:
+//  n, err := schema.Select(db, &docs, "select {} from docs where tags @> ?",
+//      sqlr.AsValue([]string{"go", "sql"}))
+//
+// If arg implements driver.Valuer, or is a value that can be passed to the
+// driver, it is passed unchanged. Slices, arrays, maps and structs are
+// marshaled as JSON, and other values are converted by the default driver
+// parameter converter.
+func AsValue(arg interface{}) driver.Valuer {
+	return valueArg{arg: arg}
+}
+
+// AsList marks an arg to be expanded into a list of values, for use in an
+// "in (?)" clause. Slice args are expanded by default, so AsList is only
+// necessary for slice types that would otherwise be passed as a single value,
+// such as []byte, or a slice type that implements driver.Valuer:
+//  n, err := schema.Select(db, &rows, "select {} from files where flags in (?)",
+//      sqlr.AsList([]byte{1, 2, 4}))
+//
+// The arg should be a slice or an array, otherwise it is passed as a single value.
+func AsList(arg interface{}) interface{} {
+	return wherein.List{Values: arg}
+}
+
+// valueArg is an arg that is passed as a single value, see AsValue.
+type valueArg struct {
+	arg interface{}
+}
+
+// Value implements the driver.Valuer interface.
+func (v valueArg) Value() (driver.Value, error) {
+	if valuer, ok := v.arg.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	if v.arg == nil || driver.IsValue(v.arg) {
+		return v.arg, nil
+	}
+	switch reflect.ValueOf(v.arg).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		data, err := json.Marshal(v.arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v.arg)
+}
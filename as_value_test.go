@@ -0,0 +1,74 @@
+package sqlr
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+type testValuer []string
+
+func (v testValuer) Value() (driver.Value, error) {
+	return "{" + v[0] + "}", nil
+}
+
+func TestAsValue(t *testing.T) {
+	type Role string
+	tests := []struct {
+		arg  interface{}
+		want driver.Value
+	}{
+		{[]string{"go", "sql"}, `["go","sql"]`},
+		{map[string]int{"a": 1}, `{"a":1}`},
+		{[]byte("raw"), []byte("raw")},
+		{"text", "text"},
+		{Role("admin"), "admin"},
+		{int32(42), int64(42)},
+		{nil, nil},
+		{testValuer{"x"}, "{x}"},
+	}
+	for i, tt := range tests {
+		got, err := AsValue(tt.arg).Value()
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if b, ok := got.([]byte); ok {
+			got = string(b)
+			if want, ok := tt.want.([]byte); ok {
+				tt.want = string(want)
+			}
+		}
+		if got != tt.want {
+			t.Errorf("%d: want=%v, got=%v", i, tt.want, got)
+		}
+	}
+}
+
+func TestAsList(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Tags string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`select "id","tags" from rows where tags = $1 and id in ($2,$3) and flags in ($4,$5)`)).
+		WithArgs(`["a","b"]`, 1, 2, "{x}", "{y}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tags"}))
+	var rows []Row
+	_, err = schema.Select(db, &rows, "select {} from rows where tags = ? and id in (?) and flags in (?)",
+		AsValue([]string{"a", "b"}), []int{1, 2}, AsList([]testValuer{{"x"}, {"y"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
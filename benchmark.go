@@ -0,0 +1,198 @@
+package sqlr
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// BenchmarkResult contains the latencies measured by Schema.Benchmark.
+type BenchmarkResult struct {
+	Iterations int
+	Insert     LatencyStats
+	Get        LatencyStats
+	Update     LatencyStats
+	Delete     LatencyStats
+}
+
+// LatencyStats summarizes the latencies measured for one operation.
+type LatencyStats struct {
+	Average time.Duration
+	P50     time.Duration
+	P95     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+}
+
+// String returns the benchmark result as a table, with one line for each operation.
+func (r *BenchmarkResult) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%-8s %12s %12s %12s %12s %12s\n", "op", "avg", "p50", "p95", "p99", "max")
+	for _, op := range []struct {
+		name  string
+		stats LatencyStats
+	}{
+		{"insert", r.Insert},
+		{"get", r.Get},
+		{"update", r.Update},
+		{"delete", r.Delete},
+	} {
+		fmt.Fprintf(&buf, "%-8s %12s %12s %12s %12s %12s\n", op.name,
+			op.stats.Average, op.stats.P50, op.stats.P95, op.stats.P99, op.stats.Max)
+	}
+	fmt.Fprintf(&buf, "iterations: %d\n", r.Iterations)
+	return buf.String()
+}
+
+// Benchmark measures the latency of inserting, getting, updating and deleting a
+// row in the table. Each iteration inserts a copy of row, selects it by primary
+// key, updates it and then deletes it, so the table is left as it was found.
+// The row type must have a primary key, and the table must be writable.
+//
+// If the row has an auto-increment column, it is set by each insert. Otherwise
+// the primary key of row must not already exist in the table.
+//
+// Benchmark is intended as a development and testing tool for checking
+// assumptions about the performance of a database and schema design. It
+// should not be run against a production database.
+func (s *Schema) Benchmark(db *sql.DB, row interface{}, tableName string, iterations int) (*BenchmarkResult, error) {
+	if iterations <= 0 {
+		return nil, errors.New("expected iterations to be greater than zero")
+	}
+	rowType, err := inferRowType(row)
+	if err != nil {
+		return nil, err
+	}
+	if tableName == "" {
+		tableName = s.tableName(rowType)
+	}
+	var keyColumns []int
+	columns := s.columnsForType(rowType)
+	for i, col := range columns {
+		if col.Tag.PrimaryKey {
+			keyColumns = append(keyColumns, i)
+		}
+	}
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("no primary key for type %s", rowType.Name())
+	}
+
+	prepare := func(format string) (*Stmt, error) {
+		return s.Prepare(row, fmt.Sprintf(format, tableName))
+	}
+	insertStmt, err := prepare(insertFormat)
+	if err != nil {
+		return nil, err
+	}
+	getStmt, err := prepare("select {} from %s where {}")
+	if err != nil {
+		return nil, err
+	}
+	updateStmt, err := prepare(updateFormat)
+	if err != nil {
+		return nil, err
+	}
+	deleteStmt, err := prepare(deleteFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	template := reflect.Indirect(reflect.ValueOf(row))
+	if template.Kind() != reflect.Struct {
+		return nil, errors.New("expected row to be a struct or a pointer to a struct")
+	}
+	var inserts, gets, updates, deletes []time.Duration
+
+	for i := 0; i < iterations; i++ {
+		rowValue := reflect.New(rowType)
+		rowValue.Elem().Set(template)
+		testRow := rowValue.Interface()
+
+		start := time.Now()
+		if _, err := insertStmt.Exec(db, testRow); err != nil {
+			return nil, fmt.Errorf("insert: %v", err)
+		}
+		inserts = append(inserts, time.Since(start))
+
+		// remove the test row if any of the following operations fail
+		cleanup := func(err error) error {
+			deleteStmt.Exec(db, testRow)
+			return err
+		}
+
+		var keys []interface{}
+		for _, index := range keyColumns {
+			keys = append(keys, columns[index].Index.ValueRO(rowValue).Interface())
+		}
+		start = time.Now()
+		n, err := getStmt.Select(db, reflect.New(rowType).Interface(), keys...)
+		if err != nil {
+			return nil, cleanup(fmt.Errorf("get: %v", err))
+		}
+		if n != 1 {
+			return nil, cleanup(fmt.Errorf("get: expected 1 row, got %d", n))
+		}
+		gets = append(gets, time.Since(start))
+
+		start = time.Now()
+		if _, err := updateStmt.Exec(db, testRow); err != nil {
+			return nil, cleanup(fmt.Errorf("update: %v", err))
+		}
+		updates = append(updates, time.Since(start))
+
+		start = time.Now()
+		if _, err := deleteStmt.Exec(db, testRow); err != nil {
+			return nil, fmt.Errorf("delete: %v", err)
+		}
+		deletes = append(deletes, time.Since(start))
+	}
+
+	return &BenchmarkResult{
+		Iterations: iterations,
+		Insert:     newLatencyStats(inserts),
+		Get:        newLatencyStats(gets),
+		Update:     newLatencyStats(updates),
+		Delete:     newLatencyStats(deletes),
+	}, nil
+}
+
+// durations implements sort.Interface.
+type durations []time.Duration
+
+func (d durations) Len() int           { return len(d) }
+func (d durations) Less(i, j int) bool { return d[i] < d[j] }
+func (d durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+// newLatencyStats calculates the statistics for the latencies. The
+// percentiles are calculated using the nearest-rank method.
+func newLatencyStats(latencies []time.Duration) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+	sorted := make(durations, len(latencies))
+	copy(sorted, latencies)
+	sort.Sort(sorted)
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	percentile := func(p int) time.Duration {
+		rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+		if rank < 1 {
+			rank = 1
+		}
+		return sorted[rank-1]
+	}
+	return LatencyStats{
+		Average: total / time.Duration(len(sorted)),
+		P50:     percentile(50),
+		P95:     percentile(95),
+		P99:     percentile(99),
+		Max:     sorted[len(sorted)-1],
+	}
+}
@@ -0,0 +1,92 @@
+package sqlr
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestBenchmark(t *testing.T) {
+	type Widget struct {
+		ID   int64 `sql:"primary key autoincrement"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL))
+
+	for i := 1; i <= 2; i++ {
+		mock.ExpectExec(regexp.QuoteMeta("insert into widgets(`name`) values(?)")).
+			WithArgs("test").
+			WillReturnResult(sqlmock.NewResult(int64(i), 1))
+		mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name` from widgets where `id`=?")).
+			WithArgs(i).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(i, "test"))
+		mock.ExpectExec(regexp.QuoteMeta("update widgets set `name`=? where `id`=?")).
+			WithArgs("test", i).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("delete from widgets where `id`=?")).
+			WithArgs(i).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	result, err := schema.Benchmark(db, Widget{Name: "test"}, "widgets", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if got, want := result.Iterations, 2; got != want {
+		t.Errorf("want=%d, got=%d", want, got)
+	}
+	if result.Insert.Max == 0 || result.Insert.Max < result.Insert.P50 {
+		t.Errorf("unexpected insert stats: %+v", result.Insert)
+	}
+	lines := strings.Split(strings.TrimSpace(result.String()), "\n")
+	if got, want := len(lines), 6; got != want {
+		t.Errorf("want=%d lines, got=%d:\n%s", want, got, result.String())
+	}
+
+	// get fails, test row is removed
+	mock.ExpectExec(regexp.QuoteMeta("insert into widgets(`name`) values(?)")).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name` from widgets where `id`=?")).
+		WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectExec(regexp.QuoteMeta("delete from widgets where `id`=?")).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := schema.Benchmark(db, Widget{Name: "test"}, "widgets", 1); err == nil {
+		t.Error("want error, got nil")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewLatencyStats(t *testing.T) {
+	var latencies []time.Duration
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	stats := newLatencyStats(latencies)
+	want := LatencyStats{
+		Average: 50500 * time.Microsecond,
+		P50:     50 * time.Millisecond,
+		P95:     95 * time.Millisecond,
+		P99:     99 * time.Millisecond,
+		Max:     100 * time.Millisecond,
+	}
+	if stats != want {
+		t.Errorf("want=%+v, got=%+v", want, stats)
+	}
+	if got := newLatencyStats(nil); got != (LatencyStats{}) {
+		t.Errorf("want zero stats, got=%+v", got)
+	}
+}
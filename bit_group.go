@@ -0,0 +1,75 @@
+package sqlr
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/jjeffery/sqlr/private/column"
+)
+
+// bitGroupColumns returns the bool field columns that are packed into the
+// integer column for the bit group, eg all fields tagged "xor_group:flags".
+func (stmt *Stmt) bitGroupColumns(group string) []*column.Info {
+	var cols []*column.Info
+	for _, col := range stmt.columns {
+		if col.BitGroup == group {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// bitGroupValue returns the value of the integer column for the bit group,
+// which has the bit set for each of the group's bool fields that is true.
+func (stmt *Stmt) bitGroupValue(rowValue reflect.Value, group string) int64 {
+	var bits uint64
+	for _, col := range stmt.bitGroupColumns(group) {
+		if v := fieldValue(col, rowValue); v.IsValid() && v.Bool() {
+			bits |= col.BitMask
+		}
+	}
+	return int64(bits)
+}
+
+// checkBitGroups returns an error if a field has an invalid tag, or if two
+// fields in the same bit group are tagged with the same bit.
+func checkBitGroups(columns []*column.Info) error {
+	bits := make(map[string]map[uint64]string)
+	for _, col := range columns {
+		if col.Tag.Err != nil {
+			return fmt.Errorf("invalid tag for field %s: %v", col.Field.Name, col.Tag.Err)
+		}
+		if col.BitGroup == "" {
+			continue
+		}
+		if bits[col.BitGroup] == nil {
+			bits[col.BitGroup] = make(map[uint64]string)
+		}
+		if other, ok := bits[col.BitGroup][col.BitMask]; ok {
+			return fmt.Errorf("invalid tag for field %s: same bit as field %s in %q",
+				col.Field.Name, other, col.BitGroup)
+		}
+		bits[col.BitGroup][col.BitMask] = col.Field.Name
+	}
+	return nil
+}
+
+// bitGroupCell scans an integer column into the bool fields of its bit group.
+type bitGroupCell struct {
+	colname  string
+	cols     []*column.Info
+	rowValue reflect.Value
+}
+
+func (bc *bitGroupCell) Scan(v interface{}) error {
+	var nullable sql.NullInt64
+	if err := nullable.Scan(v); err != nil {
+		return fmt.Errorf("cannot scan column %q: %v", bc.colname, err)
+	}
+	bits := uint64(nullable.Int64)
+	for _, col := range bc.cols {
+		col.Index.ValueRW(bc.rowValue).SetBool(bits&col.BitMask != 0)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package sqlr
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestBitGroup(t *testing.T) {
+	type Document struct {
+		ID       int `sql:"primary key"`
+		Name     string
+		Archived bool `sql:"xor_group:flags bit:0"`
+		Public   bool `sql:"xor_group:flags bit:1"`
+		Locked   bool `sql:"xor_group:flags bit:3"`
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	tests := []struct {
+		query string
+		sql   string
+		row   Document
+		args  []driver.Value
+	}{
+		{
+			query: "insert into documents({}) values({})",
+			sql:   `insert into documents("id","name","flags") values($1,$2,$3)`,
+			row:   Document{ID: 1, Name: "a", Public: true, Locked: true},
+			args:  []driver.Value{1, "a", int64(10)},
+		},
+		{
+			query: "update documents set {} where {}",
+			sql:   `update documents set "name"=$1,"flags"=$2 where "id"=$3`,
+			row:   Document{ID: 2, Name: "b", Archived: true},
+			args:  []driver.Value{"b", int64(1), 2},
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := schema.Prepare(Document{}, tt.query)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := stmt.String(), tt.sql; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+			continue
+		}
+		mock.ExpectExec(regexp.QuoteMeta(tt.sql)).WithArgs(tt.args...).WillReturnResult(sqlmock.NewResult(0, 1))
+		if _, err := stmt.Exec(db, &tt.row); err != nil {
+			t.Errorf("%d: %v", i, err)
+		}
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`select "id","name","flags" from documents`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "flags"}).
+			AddRow(1, "a", 10).
+			AddRow(2, "b", nil))
+	var docs []Document
+	if _, err := schema.Select(db, &docs, "select {} from documents"); err != nil {
+		t.Fatal(err)
+	}
+	want := []Document{
+		{ID: 1, Name: "a", Public: true, Locked: true},
+		{ID: 2, Name: "b"},
+	}
+	if len(docs) != len(want) {
+		t.Fatalf("want=%d rows, got=%d", len(want), len(docs))
+	}
+	for i := range want {
+		if docs[i] != want[i] {
+			t.Errorf("%d: want=%+v, got=%+v", i, want[i], docs[i])
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBitGroupInvalid(t *testing.T) {
+	type BadBit struct {
+		ID       int  `sql:"primary key"`
+		Archived bool `sql:"xor_group:flags bit:64"`
+	}
+	type NotNumber struct {
+		ID       int  `sql:"primary key"`
+		Archived bool `sql:"xor_group:flags bit:one"`
+	}
+	type SameBit struct {
+		ID       int  `sql:"primary key"`
+		Archived bool `sql:"xor_group:flags bit:1"`
+		Public   bool `sql:"xor_group:flags bit:1"`
+	}
+	schema := NewSchema(WithDialect(Postgres))
+
+	tests := []struct {
+		row     interface{}
+		wantErr string
+	}{
+		{
+			row:     BadBit{},
+			wantErr: `invalid tag for field Archived: invalid bit "64": expected a number from 0 to 63`,
+		},
+		{
+			row:     NotNumber{},
+			wantErr: `invalid tag for field Archived: invalid bit "one": expected a number from 0 to 63`,
+		},
+		{
+			row:     SameBit{},
+			wantErr: `invalid tag for field Public: same bit as field Archived in "flags"`,
+		},
+	}
+	for i, tt := range tests {
+		_, err := schema.Prepare(tt.row, "select {} from documents")
+		if err == nil {
+			t.Errorf("%d: want error, got nil", i)
+			continue
+		}
+		if got, want := err.Error(), tt.wantErr; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
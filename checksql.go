@@ -13,7 +13,7 @@ const (
 	insertFormat = "insert into %s({}) values({})"
 	updateFormat = "update %s set {} where {}"
 	deleteFormat = "delete from %s where {}"
-	selectFormat = "select{} from %s where {}"
+	selectFormat = "select {} from %s where {}"
 )
 
 var whiteSpaceRE = regexp.MustCompile(`\s`)
@@ -26,10 +26,14 @@ var whiteSpaceRE = regexp.MustCompile(`\s`)
 //  UPDATE <table>      => update <table> set({}) where({})
 //  SELECT FROM <table>      => select {} from <table> where {}
 //  SELECT <table>      => select {} from <table> where {}
+// If the table name is omitted (eg "INSERT", "SELECT FROM"), then tableName
+// is used, which is usually obtained from the row type's TableName method.
+// It is an error if the table name is omitted and tableName is empty.
+//
 // Note that we do not allow "DELETE FROM <table>" or "DELETE <table>"
 // similar because that is actually valid SQL, and has the rather uncommon
 // effect of deleting everything in the table.
-func checkSQL(sql string) (string, error) {
+func checkSQL(sql string, tableName string) (string, error) {
 	const maxWords = 3 // if the SQL has more than this number of words, leave it alone
 	scan := scanner.New(strings.NewReader(sql))
 	scan.IgnoreWhiteSpace = true
@@ -57,6 +61,14 @@ func checkSQL(sql string) (string, error) {
 		}
 		return true
 	}
+	if match("insert") || match("update") || match("select") || match("insert", "into") || match("select", "from") {
+		// table name omitted, so use the table name for the row type
+		if tableName == "" {
+			return "", fmt.Errorf("missing table name in %q: row type does not implement TableName() string", strings.TrimSpace(sql))
+		}
+		words = append(words, tableName)
+	}
+
 	deleteTableError := func(tableName string) error {
 		return fmt.Errorf("will not delete all rows in table %s: use database/sql if you want to do this", tableName)
 	}
@@ -4,9 +4,10 @@ import "testing"
 
 func TestCheckSQL(t *testing.T) {
 	tests := []struct {
-		in      string
-		out     string
-		errText string
+		in        string
+		tableName string
+		out       string
+		errText   string
 	}{
 		{
 			in:  "insert into table_name",
@@ -36,10 +37,44 @@ func TestCheckSQL(t *testing.T) {
 			in:      "delete  [my table]",
 			errText: `will not delete all rows in table [my table]: use database/sql if you want to do this`,
 		},
+		{
+			in:        "insert",
+			tableName: "users",
+			out:       "insert into users({}) values({})",
+		},
+		{
+			in:        "Insert Into",
+			tableName: "users",
+			out:       "insert into users({}) values({})",
+		},
+		{
+			in:        "update",
+			tableName: "users",
+			out:       "update users set {} where {}",
+		},
+		{
+			in:        "select from",
+			tableName: "users",
+			out:       "select {} from users where {}",
+		},
+		{
+			in:        "select other_table",
+			tableName: "users",
+			out:       "select {} from other_table where {}",
+		},
+		{
+			in:      "select",
+			errText: `missing table name in "select": row type does not implement TableName() string`,
+		},
+		{
+			in:        "delete",
+			tableName: "users",
+			out:       "delete",
+		},
 	}
 
 	for i, tt := range tests {
-		sql, err := checkSQL(tt.in)
+		sql, err := checkSQL(tt.in, tt.tableName)
 		if got, want := sql, tt.out; got != want {
 			t.Errorf("%d: want=%q, got=%q", i, want, got)
 		}
@@ -0,0 +1,24 @@
+package sqlr
+
+import "github.com/jjeffery/sqlr/private/column"
+
+// ColumnInfo describes a column associated with a field of a row type.
+type ColumnInfo struct {
+	Field         string // field name, or names joined by periods for embedded structs
+	Column        string // column name, after naming conventions and field mappings
+	PrimaryKey    bool   // column is part of the primary key
+	AutoIncrement bool   // column is an auto-increment (identity) column
+	Version       bool   // column is used for optimistic locking
+}
+
+// newColumnInfo returns information about the column for use
+// outside the package.
+func newColumnInfo(col *column.Info, namer columnNamer) ColumnInfo {
+	return ColumnInfo{
+		Field:         col.FieldNames,
+		Column:        namer.ColumnName(col),
+		PrimaryKey:    col.Tag.PrimaryKey,
+		AutoIncrement: col.Tag.AutoIncrement,
+		Version:       col.Tag.Version,
+	}
+}
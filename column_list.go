@@ -31,6 +31,9 @@ type columnList struct {
 	filter     func(col *column.Info) bool
 	clause     sqlClause
 	alias      string
+	tableAlias string          // from the row type's TableAlias method, used by "alias" without an ident
+	omit       map[string]bool // field names excluded from the list
+	lazy       bool            // include lazy columns in a select list, set by "all"
 }
 
 func newColumns(allColumns []*column.Info) columnList {
@@ -44,8 +47,11 @@ func newColumns(allColumns []*column.Info) columnList {
 // about how to render the column list. It is not very sophisticated at the moment,
 // currently the only recognised values are:
 //  "alias n" => use alias "n" for each column in the list
+//  "alias"   => use the alias from the row type's TableAlias method
 //  "pk"      => primary key columns only
-//  "all"     => all columns
+//  "nopk"    => all columns except primary key columns
+//  "all"     => all columns, including lazy columns in a select list
+//  "omit a,b" => exclude the columns for fields a and b, must be last
 func (cols columnList) Parse(clause sqlClause, text string) (columnList, error) {
 	cols2 := cols
 	cols2.clause = clause
@@ -53,31 +59,66 @@ func (cols columnList) Parse(clause sqlClause, text string) (columnList, error)
 
 	// TODO: update filter based on text
 	scan := scanner.New(strings.NewReader(text))
-	scan.AddKeywords("alias", "all", "pk")
+	scan.AddKeywords("alias", "all", "pk", "nopk", "omit")
 	scan.IgnoreWhiteSpace = true
 
+	// aliasPending is set after "alias", until it is known
+	// whether an ident follows
+	var aliasPending bool
+	useTableAlias := func() error {
+		if cols.tableAlias == "" {
+			return fmt.Errorf("missing ident after 'alias'")
+		}
+		cols2.alias = cols.tableAlias
+		return nil
+	}
+
 	for scan.Scan() {
 		tok, lit := scan.Token(), scan.Text()
 
+		if aliasPending {
+			aliasPending = false
+			if tok == scanner.IDENT {
+				cols2.alias = lit
+				continue
+			}
+			if err := useTableAlias(); err != nil {
+				return columnList{}, err
+			}
+		}
+
 		// TODO: dodgy job to get going quickly
 		if tok == scanner.KEYWORD {
 			switch strings.ToLower(lit) {
 			case "alias":
-				if scan.Scan() {
-					cols2.alias = scan.Text()
-				} else {
-					return columnList{}, fmt.Errorf("missing ident after 'alias'")
-				}
+				aliasPending = true
 			case "all":
 				cols2.filter = columnFilterAll
+				cols2.lazy = true
 			case "pk":
 				cols2.filter = columnFilterPK
+			case "nopk":
+				cols2.filter = columnFilterNonPK
+			case "omit":
+				var buf bytes.Buffer
+				for scan.Scan() {
+					buf.WriteString(scan.Text())
+				}
+				var err error
+				if cols2, err = cols2.omitting(strings.Split(buf.String(), ",")); err != nil {
+					return columnList{}, err
+				}
 			}
 		}
 	}
 	if err := scan.Err(); err != nil {
 		return columnList{}, err
 	}
+	if aliasPending {
+		if err := useTableAlias(); err != nil {
+			return columnList{}, err
+		}
+	}
 
 	return cols2, nil
 }
@@ -85,11 +126,11 @@ func (cols columnList) Parse(clause sqlClause, text string) (columnList, error)
 // String returns a string representation of the columns.
 // The string returned depends on the SQL clause in which the
 // columns appear.
-func (cols columnList) String(dialect Dialect, columnNamer columnNamer, counter func() int) string {
+func (cols columnList) String(dialect Dialect, quoteMode QuoteMode, columnNamer columnNamer, counter func() int) string {
 	var buf bytes.Buffer
 
 	quotedColumnName := func(col *column.Info) string {
-		return dialect.Quote(columnNamer.ColumnName(col))
+		return quoteMode.quote(dialect, columnNamer.ColumnName(col))
 	}
 	placeholder := func() string {
 		return dialect.Placeholder(counter())
@@ -118,22 +159,93 @@ func (cols columnList) String(dialect Dialect, columnNamer columnNamer, counter
 		case clauseInsertValues:
 			buf.WriteString(placeholder())
 		case clauseUpdateSet, clauseUpdateWhere, clauseDeleteWhere, clauseSelectWhere:
+			columnName := quotedColumnName(col)
 			if cols.alias != "" {
-				buf.WriteString(cols.alias)
-				buf.WriteRune('.')
+				columnName = cols.alias + "." + columnName
+			}
+			if col.Tag.NullSafe && cols.clause != clauseUpdateSet {
+				buf.WriteString(nullSafeEqual(dialect, columnName, placeholder()))
+			} else {
+				buf.WriteString(columnName)
+				buf.WriteRune('=')
+				buf.WriteString(placeholder())
 			}
-			buf.WriteString(quotedColumnName(col))
-			buf.WriteRune('=')
-			buf.WriteString(placeholder())
 		}
 	}
 	return buf.String()
 }
 
+// nullSafeEqual returns an expression for the dialect that compares the column
+// with the placeholder for equality, where NULL is considered equal to NULL.
+func nullSafeEqual(dialect Dialect, columnName, placeholder string) string {
+	if d, ok := dialect.(interface {
+		NullSafeEqual(column, placeholder string) string
+	}); ok {
+		return d.NullSafeEqual(columnName, placeholder)
+	}
+	return columnName + " is not distinct from " + placeholder
+}
+
+// omitting returns a copy of cols that excludes the columns for the
+// named fields. It is an error if a field name is not recognised.
+func (cols columnList) omitting(fieldNames []string) (columnList, error) {
+	omit := make(map[string]bool, len(cols.omit)+len(fieldNames))
+	for fieldName := range cols.omit {
+		omit[fieldName] = true
+	}
+	for _, fieldName := range fieldNames {
+		if fieldName = strings.TrimSpace(fieldName); fieldName == "" {
+			continue
+		}
+		var found bool
+		for _, col := range cols.allColumns {
+			if col.FieldNames == fieldName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return columnList{}, fmt.Errorf("cannot omit unknown field %q", fieldName)
+		}
+		omit[fieldName] = true
+	}
+	if len(omit) == 0 {
+		return columnList{}, fmt.Errorf("missing field names after 'omit'")
+	}
+	cols.omit = omit
+	return cols, nil
+}
+
 // filtered returns the columns after the filter has been applied
 func (cols columnList) filtered() []*column.Info {
 	v := make([]*column.Info, 0, len(cols.allColumns))
+	var bitGroups map[string]bool
 	for _, col := range cols.allColumns {
+		if cols.omit[col.FieldNames] {
+			continue
+		}
+		if col.BitGroup != "" {
+			// the fields in a bit group share one column
+			if bitGroups[col.BitGroup] {
+				continue
+			}
+			if bitGroups == nil {
+				bitGroups = make(map[string]bool)
+			}
+			bitGroups[col.BitGroup] = true
+		}
+		if col.Tag.Window {
+			// window columns are written in the query, and only scanned from the results
+			continue
+		}
+		if col.Tag.Immutable && cols.clause == clauseUpdateSet {
+			// immutable columns are set on insert, never updated
+			continue
+		}
+		if col.Tag.Lazy && cols.clause == clauseSelectColumns && !cols.lazy {
+			// lazy columns are only selected when asked for with "all"
+			continue
+		}
 		if cols.filter == nil || cols.filter(col) {
 			v = append(v, col)
 		}
@@ -151,6 +263,11 @@ func columnFilterPK(col *column.Info) bool {
 	return col.Tag.PrimaryKey
 }
 
+// columnFilterNonPK is the filter for all columns except primary key columns
+func columnFilterNonPK(col *column.Info) bool {
+	return !col.Tag.PrimaryKey
+}
+
 // columnFilterInsertable is the filter for all columns except the autoincrement
 // column (if it exists)
 func columnFilterInsertable(col *column.Info) bool {
@@ -158,7 +275,7 @@ func columnFilterInsertable(col *column.Info) bool {
 }
 
 // columnFitlerUpdateable is the filter for all columns not part of the primary key,
-// and not autoincrement
+// not autoincrement, and not updated by the database
 func columnFilterUpdateable(col *column.Info) bool {
-	return !col.Tag.PrimaryKey && !col.Tag.AutoIncrement
+	return !col.Tag.PrimaryKey && !col.Tag.AutoIncrement && col.Tag.OnUpdate == ""
 }
@@ -0,0 +1,38 @@
+package sqlr
+
+// ColumnOptions contains column information for a field of a row type. It is
+// an alternative to the struct tag for row types that cannot be tagged, such as
+// types generated by other tools.
+//
+// A row type supplies column options by implementing a ColumnMeta method, which
+// returns the options keyed by field name. Fields of embedded structs are keyed
+// by their field names joined by periods, eg "HomeAddress.Street":
+//  func (u *User) ColumnMeta() map[string]sqlr.ColumnOptions {
+//      return map[string]sqlr.ColumnOptions{
+//          "ID":    {PrimaryKey: true, AutoIncrement: true},
+//          "DOB":   {Name: "date_of_birth"},
+//          "Prefs": {JSON: true},
+//      }
+//  }
+//
+// The method can have a value or pointer receiver, and is called once for the
+// row type on a zero value, so the options must not depend on field values.
+//
+// Column options are merged with the struct tag, and take precedence over it:
+// a non-empty Name replaces the column name in the tag, and each option that
+// is set is added to the tag keywords. An option that is not set does not clear
+// the corresponding keyword in the tag.
+type ColumnOptions struct {
+	Name          string // column name, instead of the naming convention
+	PrimaryKey    bool   // column is part of the primary key
+	AutoIncrement bool   // column is an auto-increment (identity) column
+	Version       bool   // column is used for optimistic locking
+	NaturalKey    bool   // column is part of the natural key
+	JSON          bool   // column is marshaled as JSON
+	Null          bool   // empty value is stored as NULL
+	NullSafe      bool   // where clauses use NULL-safe comparison
+	NotNull       bool   // empty value is not stored as NULL
+	Immutable     bool   // column is set on insert, but never updated
+	Window        bool   // column is the result of a window function in a query
+	Lazy          bool   // column is only selected by "select {all}", not "select {}"
+}
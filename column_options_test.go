@@ -0,0 +1,74 @@
+package sqlr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jjeffery/sqlr/private/column"
+)
+
+type columnMetaAddress struct {
+	Street   string
+	Locality string
+}
+
+type columnMetaRow struct {
+	ID          int64
+	Name        string `sql:"full_name"`
+	DOB         string `sql:"null"`
+	Prefs       map[string]string
+	CreatedAt   string
+	HomeAddress columnMetaAddress
+}
+
+func (r *columnMetaRow) ColumnMeta() map[string]ColumnOptions {
+	return map[string]ColumnOptions{
+		"ID":                   {PrimaryKey: true, AutoIncrement: true},
+		"Name":                 {Name: "name"},
+		"DOB":                  {Name: "date_of_birth", NotNull: true},
+		"Prefs":                {JSON: true},
+		"CreatedAt":            {Immutable: true},
+		"HomeAddress.Locality": {Name: "suburb"},
+	}
+}
+
+func TestColumnOptionsConvertible(t *testing.T) {
+	// column.Options must have exactly the same fields as ColumnOptions
+	if !reflect.TypeOf(ColumnOptions{}).ConvertibleTo(reflect.TypeOf(column.Options{})) {
+		t.Fatal("ColumnOptions cannot be converted to column.Options")
+	}
+}
+
+func TestColumnMeta(t *testing.T) {
+	schema := NewSchema(WithDialect(MySQL))
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: "insert rows",
+			want:  "insert into rows(`name`,`date_of_birth`,`prefs`,`created_at`,`home_address_street`,`home_address_suburb`) values(?,?,?,?,?,?)",
+		},
+		{
+			query: "update rows",
+			want:  "update rows set `name`=?,`date_of_birth`=?,`prefs`=?,`home_address_street`=?,`home_address_suburb`=? where `id`=?",
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := schema.Prepare(columnMetaRow{}, tt.query)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := stmt.String(), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+
+	// options are merged with the struct tag
+	for _, col := range column.ListForType(reflect.TypeOf(columnMetaRow{})) {
+		if col.FieldNames == "DOB" && !(col.Tag.EmptyNull && col.Tag.NotNull) {
+			t.Errorf("want DOB to be null and not null, got %+v", col.Tag)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package sqlr
+
+import (
+	"sync"
+
+	"github.com/jjeffery/sqlr/private/column"
+)
+
+// columnRewriter rewrites column names using a function supplied
+// by the calling program. The rewritten names are memoized, so the
+// function is called at most once for each column.
+type columnRewriter struct {
+	fn    func(field, col string) string
+	mu    sync.RWMutex
+	names map[*column.Info]string
+}
+
+func newColumnRewriter(fn func(field, col string) string) *columnRewriter {
+	return &columnRewriter{
+		fn:    fn,
+		names: make(map[*column.Info]string),
+	}
+}
+
+// columnNamer returns a column namer that rewrites the names
+// returned by namer.
+func (r *columnRewriter) columnNamer(namer columnNamer) columnNamer {
+	return columnNamerFunc(func(col *column.Info) string {
+		r.mu.RLock()
+		name, ok := r.names[col]
+		r.mu.RUnlock()
+		if ok {
+			return name
+		}
+		name = r.fn(col.FieldNames, namer.ColumnName(col))
+		r.mu.Lock()
+		r.names[col] = name
+		r.mu.Unlock()
+		return name
+	})
+}
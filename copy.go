@@ -0,0 +1,94 @@
+package sqlr
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// Copy copies rows from one database to another. The rows that match the where
+// clause are selected from the table for the row type in srcDB, and inserted
+// into the table with the same name in dstDB. Copy returns the number of rows
+// copied.
+//
+// The row argument can be a struct, a pointer to a struct, or a slice of structs,
+// and is only used to determine the row type. The table name is obtained from the
+// row type's TableName() method if it has one, otherwise it is the name of the row
+// type converted using the schema's naming convention. The where clause can be
+// empty to copy all rows, and can refer to args:
+//  n, err := schema.Copy(prodDB, reportDB, Order{}, "created_at >= ?", since)
+//
+// All columns are inserted, including any auto-increment column, so the copied
+// rows have the same primary key values as the original rows.
+//
+// The two databases can have different dialects. If srcDB or dstDB is a *sql.DB,
+// the dialect for that database is determined from its driver (or the schema's
+// dialect negotiator), and the schema's naming rules apply to both. If dstDB is a
+// *sql.DB, all of the rows are inserted in a single transaction.
+func (s *Schema) Copy(srcDB, dstDB DB, row interface{}, where string, args ...interface{}) (int64, error) {
+	rowType, err := inferRowType(row)
+	if err != nil {
+		return 0, err
+	}
+	table := s.tableName(rowType)
+
+	query := "select {} from " + table
+	if where != "" {
+		query += " where " + where
+	}
+	rows := reflect.New(reflect.SliceOf(reflect.PtrTo(rowType)))
+	if _, err := s.schemaForDB(srcDB).Select(srcDB, rows.Interface(), query, args...); err != nil {
+		return 0, err
+	}
+
+	stmt, err := s.schemaForDB(dstDB).Prepare(rows.Interface(), fmt.Sprintf("insert into %s({all}) values({})", table))
+	if err != nil {
+		return 0, err
+	}
+
+	var tx *sql.Tx
+	if beginner, ok := dstDB.(interface {
+		Begin() (*sql.Tx, error)
+	}); ok {
+		if tx, err = beginner.Begin(); err != nil {
+			return 0, err
+		}
+		defer tx.Rollback()
+		dstDB = tx
+	}
+
+	sliceValue := rows.Elem()
+	for i := 0; i < sliceValue.Len(); i++ {
+		if _, err := stmt.Exec(dstDB, sliceValue.Index(i).Interface()); err != nil {
+			return 0, fmt.Errorf("cannot copy row %d: %v", i, err)
+		}
+	}
+
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return 0, err
+		}
+	}
+	return int64(sliceValue.Len()), nil
+}
+
+// schemaForDB returns a schema with the dialect for db, and the same naming
+// rules as s. If db is not a *sql.DB, or its dialect cannot be determined,
+// s is returned.
+func (s *Schema) schemaForDB(db DB) *Schema {
+	sqlDB, ok := db.(*sql.DB)
+	if !ok || sqlDB == s.dialectDB {
+		return s
+	}
+	var dialect Dialect
+	if s.dialectNegotiator != nil {
+		dialect = s.dialectNegotiator(sqlDB)
+	}
+	if dialect == nil {
+		dialect = matchDialect(sqlDB)
+	}
+	if dialect == nil || dialect == s.getDialect() {
+		return s
+	}
+	return s.Clone(WithDialect(dialect))
+}
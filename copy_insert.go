@@ -0,0 +1,86 @@
+package sqlr
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// CopyInsert inserts rows into a Postgres table using the COPY protocol, which
+// is much faster than INSERT statements for loading a large number of rows. The
+// rows argument must be a slice of structs or a slice of struct pointers. If table
+// is empty, the table name is the same as for the "insert" shorthand, ie it is
+// obtained from the row type's TableName() method or from WithTypes. The table name
+// is renamed using WithIdentifier in the same way as for an insert statement.
+// CopyInsert returns the number of rows inserted.
+//
+// The columns are the same as for an insert statement, so any auto-increment
+// column is omitted. Auto-increment values are not copied back into the rows.
+//
+// CopyInsert requires the Postgres dialect, and a driver that supports COPY
+// using a prepared statement, such as github.com/lib/pq. The COPY protocol can
+// only be used within a transaction, so the rows are not visible to other
+// transactions until tx is committed.
+func (s *Schema) CopyInsert(tx *sql.Tx, table string, rows interface{}) (int, error) {
+	if s.getDialect() != Postgres {
+		return 0, errors.New("CopyInsert requires the postgres dialect")
+	}
+	sliceValue := reflect.Indirect(reflect.ValueOf(rows))
+	if sliceValue.Kind() != reflect.Slice {
+		return 0, errors.New("expected rows to be a slice of structs or a slice of struct pointers")
+	}
+	insert := "insert"
+	if table != "" {
+		insert += " " + table
+	}
+	stmt, err := s.Prepare(rows, insert)
+	if err != nil {
+		return 0, err
+	}
+	if stmt.insertTable == "" {
+		return 0, errors.New("cannot determine the table name for CopyInsert")
+	}
+
+	// the table name is the same as in the insert statement, so it has
+	// been renamed and quoted in the same way
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "copy %s (", stmt.insertTable)
+	for i, input := range stmt.inputs {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		buf.WriteString(stmt.quoteMode.quote(stmt.dialect, stmt.columnNamer.ColumnName(input.col)))
+	}
+	buf.WriteString(") from stdin")
+
+	copyStmt, err := tx.Prepare(buf.String())
+	if err != nil {
+		return 0, err
+	}
+	defer copyStmt.Close()
+
+	rowCount := sliceValue.Len()
+	for i := 0; i < rowCount; i++ {
+		args, err := stmt.getArgs(sliceValue.Index(i).Interface(), nil)
+		if err != nil {
+			return 0, fmt.Errorf("cannot copy row %d: %v", i, err)
+		}
+		for j, input := range stmt.inputs {
+			if b, ok := args[j].([]byte); ok && input.col.Tag.JSON {
+				// the COPY text format would encode a byte slice as bytea
+				args[j] = string(b)
+			}
+		}
+		if _, err := copyStmt.Exec(args...); err != nil {
+			return 0, fmt.Errorf("cannot copy row %d: %v", i, err)
+		}
+	}
+
+	// an exec with no args flushes the data
+	if _, err := copyStmt.Exec(); err != nil {
+		return 0, err
+	}
+	return rowCount, nil
+}
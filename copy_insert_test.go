@@ -0,0 +1,91 @@
+package sqlr
+
+import (
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestCopyInsert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	type Row struct {
+		ID   int `sql:"primary key autoincrement"`
+		Name string
+		Tags []string `sql:"json"`
+	}
+	rows := []Row{
+		{Name: "one", Tags: []string{"a"}},
+		{Name: "two"},
+	}
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(regexp.QuoteMeta(`copy users ("name","tags") from stdin`))
+	prep.ExpectExec().WithArgs("one", `["a"]`).WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WithArgs("two", `null`).WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := NewSchema(WithDialect(Postgres))
+	n, err := schema.CopyInsert(tx, "users", rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := n, 2; got != want {
+		t.Errorf("want=%d, got=%d", want, got)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewSchema(WithDialect(MySQL)).CopyInsert(tx, "users", rows); err == nil {
+		t.Error("want error for mysql dialect, got nil")
+	}
+	if _, err := schema.CopyInsert(tx, "users", rows[0]); err == nil {
+		t.Error("want error for non-slice rows, got nil")
+	}
+	if _, err := schema.CopyInsert(tx, "", rows); err == nil {
+		t.Error("want error for missing table name, got nil")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCopyInsertTableName(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	type Widget struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(regexp.QuoteMeta(`copy app_widgets ("id","name") from stdin`))
+	prep.ExpectExec().WithArgs(1, "one").WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := NewSchema(WithDialect(Postgres), WithTypes(Widget{}), WithIdentifier("app_widgets", "widgets"))
+	if _, err := schema.CopyInsert(tx, "", []Widget{{ID: 1, Name: "one"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
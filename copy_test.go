@@ -0,0 +1,70 @@
+package sqlr
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestCopy(t *testing.T) {
+	srcDB, srcMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srcDB.Close()
+	dstDB, dstMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dstDB.Close()
+
+	// destination database is postgres, source uses the schema's dialect
+	schema := NewSchema(
+		WithDialect(MySQL),
+		WithDialectNegotiator(func(db *sql.DB) Dialect {
+			if db == dstDB {
+				return Postgres
+			}
+			return nil
+		}),
+	)
+
+	srcMock.ExpectQuery(regexp.QuoteMeta("select `id`,`name` from table_name_rows where name like ?")).
+		WithArgs("A%").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(1, "Alice").
+			AddRow(2, "Andrew"))
+	insert := regexp.QuoteMeta(`insert into table_name_rows("id","name") values($1,$2)`)
+	dstMock.ExpectBegin()
+	dstMock.ExpectExec(insert).WithArgs(1, "Alice").WillReturnResult(sqlmock.NewResult(1, 1))
+	dstMock.ExpectExec(insert).WithArgs(2, "Andrew").WillReturnResult(sqlmock.NewResult(2, 1))
+	dstMock.ExpectCommit()
+
+	n, err := schema.Copy(srcDB, dstDB, tableNameRow{}, "name like ?", "A%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := n, int64(2); got != want {
+		t.Errorf("want=%d, got=%d", want, got)
+	}
+
+	// insert fails, nothing is committed
+	srcMock.ExpectQuery(regexp.QuoteMeta("select `id`,`name` from table_name_rows")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Alice"))
+	dstMock.ExpectBegin()
+	dstMock.ExpectExec(insert).WithArgs(1, "Alice").WillReturnError(sqlmock.ErrCancelled)
+	dstMock.ExpectRollback()
+
+	if _, err := schema.Copy(srcDB, dstDB, tableNameRow{}, ""); err == nil {
+		t.Error("want error, got nil")
+	}
+
+	if err := srcMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if err := dstMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,267 @@
+package sqlr
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jjeffery/sqlr/private/column"
+	"github.com/jjeffery/sqlr/private/dialect"
+	"github.com/jjeffery/sqlr/private/scanner"
+)
+
+// ddlDialect is implemented by dialects that know the column types and
+// clauses used to create tables. Other dialects use the ANSI SQL DDL.
+type ddlDialect interface {
+	ColumnType(kind string) string
+	AutoIncrement(columnType string) (string, bool)
+	SupportsOnUpdate() bool
+	SupportsEnumType() bool
+	SupportsIndexMethod() bool
+}
+
+// DDL returns the statements that create the table for the row type, followed
+// by the statements that create its indexes. The row argument can be a struct,
+// a pointer to a struct, or a slice of structs. The table name is worked out in
+// the same way as for Truncate.
+//
+// The column type depends on the type of the field and the schema's dialect.
+// Fields that are pointers, or sql.NullString and similar types, or that are
+// tagged "null", are nullable. Other columns are "not null". The column tags
+// are used as follows:
+//
+//  primary key, autoincrement  primary key constraint, auto-increment column
+//  json, encrypt               text column for JSON, binary column for encrypted data
+//  precision:nano              bigint column for a time.Time field
+//  enum:a,b                    MySQL and MariaDB enum type, otherwise a check constraint
+//  xor_group:f                 one bigint column for the group
+//  on update:expr              MySQL and MariaDB "on update" clause, ignored otherwise
+//  index:name                  "create index", with the columns in field order
+//  index_type:gin              Postgres index method, eg "using gin", ignored otherwise
+//
+// DDL is intended for tests and tools that create a database schema. It returns
+// an error if a field's type does not have a column type.
+func (s *Schema) DDL(row interface{}) ([]string, error) {
+	rowType, err := inferRowType(row)
+	if err != nil {
+		return nil, err
+	}
+	table, err := s.rowTableName(row, rowType)
+	if err != nil {
+		return nil, err
+	}
+	d := s.getDialect()
+	dd, ok := d.(ddlDialect)
+	if !ok {
+		dd = dialect.ANSI
+	}
+	namer := s.columnNamer()
+	if len(s.tableConventions) > 0 {
+		namer = s.columnNamerForTable(table)
+	}
+	columns := s.columnsForType(rowType)
+	if err := checkBitGroups(columns); err != nil {
+		return nil, err
+	}
+	tableName := s.ddlTableName(table)
+
+	var defs []string
+	var primaryKey []string
+	var inlinePrimaryKey bool
+	var indexNames []string
+	indexColumns := make(map[string][]string)
+	indexTypes := make(map[string]string)
+	groups := make(map[string]bool)
+	for _, col := range columns {
+		if col.Tag.Window {
+			// not stored in the table
+			continue
+		}
+		if col.BitGroup != "" {
+			// all of the fields in the group share one column
+			if groups[col.BitGroup] {
+				continue
+			}
+			groups[col.BitGroup] = true
+		}
+		name := s.quoteMode.quote(d, namer.ColumnName(col))
+		def, inline, err := ddlColumn(dd, col, name)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, name+" "+def)
+		if col.Tag.PrimaryKey {
+			primaryKey = append(primaryKey, name)
+			inlinePrimaryKey = inlinePrimaryKey || inline
+		}
+		if index := col.Tag.Index; index != "" {
+			if _, ok := indexColumns[index]; !ok {
+				indexNames = append(indexNames, index)
+			}
+			indexColumns[index] = append(indexColumns[index], name)
+			if indexTypes[index] == "" {
+				indexTypes[index] = col.Tag.IndexType
+			}
+		}
+	}
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("cannot create table %s: no columns", table)
+	}
+	if len(primaryKey) > 0 && !inlinePrimaryKey {
+		defs = append(defs, "primary key ("+strings.Join(primaryKey, ",")+")")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "create table %s (\n", tableName)
+	for i, def := range defs {
+		buf.WriteString("  ")
+		buf.WriteString(def)
+		if i < len(defs)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(")")
+	statements := []string{buf.String()}
+
+	for _, index := range indexNames {
+		var using string
+		if indexType := indexTypes[index]; indexType != "" && dd.SupportsIndexMethod() {
+			using = "using " + indexType + " "
+		}
+		statements = append(statements, fmt.Sprintf("create index %s on %s %s(%s)",
+			index, tableName, using, strings.Join(indexColumns[index], ",")))
+	}
+	return statements, nil
+}
+
+// rowTableName returns the table name for the row, worked out in the same way
+// as for the "insert" shorthand. If the row type has no table name for the
+// shorthand, the table name is the row type's name converted by the naming convention.
+func (s *Schema) rowTableName(row interface{}, rowType reflect.Type) (string, error) {
+	table, err := s.shardTableForQuery(row, rowType, "insert")
+	if err != nil {
+		return "", err
+	}
+	if table == "" {
+		table = s.shorthandTableName(rowType)
+	}
+	if table == "" {
+		table = s.tableName(rowType)
+	}
+	return table, nil
+}
+
+// ddlTableName returns the table name as it appears in a query: renamed by
+// WithIdentifier, and quoted for the dialect if it is quoted.
+func (s *Schema) ddlTableName(table string) string {
+	quoted := scanner.IsQuoted(table)
+	if quoted {
+		table = scanner.Unquote(table)
+	}
+	if newName, ok := s.renameIdent(table); ok {
+		table = newName
+	}
+	if quoted {
+		return s.getDialect().Quote(table)
+	}
+	return table
+}
+
+// ddlColumn returns the definition of the named column, without the name. It
+// also returns true if the definition declares the column as the primary key.
+func ddlColumn(dd ddlDialect, col *column.Info, name string) (string, bool, error) {
+	kind, nullable := ddlKind(col)
+	if kind == "" {
+		return "", false, fmt.Errorf("cannot create column for field %s of type %s", col.FieldNames, col.Field.Type)
+	}
+	columnType := dd.ColumnType(kind)
+	var inline bool
+	if col.Tag.AutoIncrement {
+		columnType, inline = dd.AutoIncrement(columnType)
+	} else if len(col.Tag.Enum) > 0 && dd.SupportsEnumType() {
+		columnType = "enum(" + ddlValues(col.Tag.Enum) + ")"
+	}
+
+	def := columnType
+	if !nullable || col.Tag.PrimaryKey || col.Tag.AutoIncrement || col.Tag.NotNull {
+		def += " not null"
+	} else {
+		def += " null"
+	}
+	if col.Tag.OnUpdate != "" && dd.SupportsOnUpdate() {
+		def += " on update " + col.Tag.OnUpdate
+	}
+	if len(col.Tag.Enum) > 0 && !dd.SupportsEnumType() {
+		def += " check (" + name + " in (" + ddlValues(col.Tag.Enum) + "))"
+	}
+	return def, inline, nil
+}
+
+// ddlKind returns the kind of value stored in the column, as passed to the
+// dialect's ColumnType method, and whether the column is nullable. It returns
+// an empty string if the field type does not have a column type.
+func ddlKind(col *column.Info) (kind string, nullable bool) {
+	if col.BitGroup != "" {
+		return "int64", false
+	}
+	nullable = col.Tag.EmptyNull
+	fieldType := col.Field.Type
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+		nullable = true
+	}
+	if col.Tag.JSON {
+		return "json", nullable
+	}
+	if col.Tag.Encrypt {
+		return "bytes", nullable
+	}
+	if fieldType.Kind() == reflect.Struct && fieldType != timeType {
+		// sql.NullString, sql.NullInt64 and similar types hold the
+		// value in the first field, and whether it is null in "Valid"
+		valid, ok := fieldType.FieldByName("Valid")
+		if !ok || valid.Type.Kind() != reflect.Bool || fieldType.NumField() != 2 {
+			return "", false
+		}
+		fieldType = fieldType.Field(0).Type
+		nullable = true
+	}
+	if fieldType == timeType {
+		if col.Tag.Precision == "nano" {
+			return "int64", nullable
+		}
+		return "time", nullable
+	}
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		return "bool", nullable
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return "int16", nullable
+	case reflect.Int32, reflect.Uint16:
+		return "int32", nullable
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return "int64", nullable
+	case reflect.Float32:
+		return "float32", nullable
+	case reflect.Float64:
+		return "float64", nullable
+	case reflect.String:
+		return "string", nullable
+	case reflect.Slice:
+		if fieldType.Elem().Kind() == reflect.Uint8 {
+			return "bytes", nullable
+		}
+	}
+	return "", false
+}
+
+// ddlValues returns the values as a comma-separated list of string literals.
+func ddlValues(values []string) string {
+	literals := make([]string, len(values))
+	for i, v := range values {
+		literals[i] = "'" + strings.Replace(v, "'", "''", -1) + "'"
+	}
+	return strings.Join(literals, ",")
+}
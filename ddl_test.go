@@ -0,0 +1,129 @@
+package sqlr
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDDL(t *testing.T) {
+	type Event struct {
+		ID        int64             `sql:"primary key autoincrement"`
+		Name      string            `sql:"index:idx_event_name"`
+		Status    string            `sql:"enum:active,inactive"`
+		Doc       map[string]string `sql:"json index:idx_event_doc index_type:gin"`
+		Note      sql.NullString
+		Count     *int32
+		At        time.Time `sql:"precision:nano"`
+		UpdatedAt time.Time `sql:"on update CURRENT_TIMESTAMP(6)"`
+		Archived  bool      `sql:"xor_group:flags bit:0"`
+		Public    bool      `sql:"xor_group:flags bit:1"`
+	}
+	tests := []struct {
+		dialect Dialect
+		want    []string
+	}{
+		{
+			dialect: Postgres,
+			want: []string{
+				`create table events (
+  "id" bigint generated by default as identity not null,
+  "name" text not null,
+  "status" text not null check ("status" in ('active','inactive')),
+  "doc" jsonb not null,
+  "note" text null,
+  "count" integer null,
+  "at" bigint not null,
+  "updated_at" timestamp with time zone not null,
+  "flags" bigint not null,
+  primary key ("id")
+)`,
+				`create index idx_event_name on events ("name")`,
+				`create index idx_event_doc on events using gin ("doc")`,
+			},
+		},
+		{
+			dialect: MySQL,
+			want: []string{
+				"create table events (\n" +
+					"  `id` bigint auto_increment not null,\n" +
+					"  `name` varchar(255) not null,\n" +
+					"  `status` enum('active','inactive') not null,\n" +
+					"  `doc` json not null,\n" +
+					"  `note` varchar(255) null,\n" +
+					"  `count` int null,\n" +
+					"  `at` bigint not null,\n" +
+					"  `updated_at` datetime(6) not null on update CURRENT_TIMESTAMP(6),\n" +
+					"  `flags` bigint not null,\n" +
+					"  primary key (`id`)\n" +
+					")",
+				"create index idx_event_name on events (`name`)",
+				"create index idx_event_doc on events (`doc`)",
+			},
+		},
+		{
+			dialect: MSSQL,
+			want: []string{
+				`create table events (
+  [id] bigint identity(1,1) not null,
+  [name] nvarchar(255) not null,
+  [status] nvarchar(255) not null check ([status] in ('active','inactive')),
+  [doc] nvarchar(max) not null,
+  [note] nvarchar(255) null,
+  [count] int null,
+  [at] bigint not null,
+  [updated_at] datetime2 not null,
+  [flags] bigint not null,
+  primary key ([id])
+)`,
+				`create index idx_event_name on events ([name])`,
+				`create index idx_event_doc on events ([doc])`,
+			},
+		},
+		{
+			dialect: SQLite,
+			want: []string{
+				"create table events (\n" +
+					"  `id` integer primary key autoincrement not null,\n" +
+					"  `name` text not null,\n" +
+					"  `status` text not null check (`status` in ('active','inactive')),\n" +
+					"  `doc` text not null,\n" +
+					"  `note` text null,\n" +
+					"  `count` integer null,\n" +
+					"  `at` integer not null,\n" +
+					"  `updated_at` timestamp not null,\n" +
+					"  `flags` integer not null\n" +
+					")",
+				"create index idx_event_name on events (`name`)",
+				"create index idx_event_doc on events (`doc`)",
+			},
+		},
+	}
+	for i, tt := range tests {
+		schema := NewSchema(WithDialect(tt.dialect), WithTypes(Event{}))
+		got, err := schema.DDL(Event{})
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if want := tt.want; !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func TestDDLErrors(t *testing.T) {
+	type Unknown struct {
+		ID    int         `sql:"primary key"`
+		Value interface{} `sql:"json"`
+		Ch    complex128
+	}
+	schema := NewSchema(WithDialect(Postgres))
+	if _, err := schema.DDL(Unknown{}); err == nil {
+		t.Error("want error for complex128 field, got nil")
+	}
+	if _, err := schema.DDL(1); err == nil {
+		t.Error("want error for non-struct row, got nil")
+	}
+}
@@ -0,0 +1,120 @@
+package sqlr
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jjeffery/sqlr/private/scanner"
+)
+
+// ToQueryBuilderSQL returns the statement's query with the args substituted for
+// the placeholders, eg "select id,name from users where id = 42" instead of
+// "select id,name from users where id = $1" and [42]. Strings and times are quoted,
+// numbers are not, and nil is NULL. Slice args are expanded in the same way as when
+// the statement is executed. A placeholder without a corresponding arg is unchanged.
+//
+// The args are the values for the placeholders in the query, in order, in the
+// same way as the args for Select. For statements that are executed with a row,
+// such as insert and update statements, the values of the row's fields must be
+// included in args.
+//
+// WARNING: ToQueryBuilderSQL is for debugging and logging only. The quoting of values
+// is not guaranteed to be safe against SQL injection, and the result must never be
+// executed by the database. Always execute the statement with its args.
+func (stmt *Stmt) ToQueryBuilderSQL(args ...interface{}) string {
+	query := stmt.query
+	if expanded, err := stmt.expandQuery(query, args); err == nil {
+		query, args = expanded.SQL, expanded.Args
+	}
+
+	type token struct {
+		tok  scanner.Token
+		text string
+	}
+	var tokens []token
+	var dollar bool
+	scan := scanner.New(strings.NewReader(query))
+	scan.ColonPlaceholders = colonPlaceholders(stmt.dialect)
+	for scan.Scan() {
+		tok, text := scan.Token(), scan.Text()
+		if tok == scanner.PLACEHOLDER && strings.HasPrefix(text, "$") {
+			dollar = true
+		}
+		tokens = append(tokens, token{tok: tok, text: text})
+	}
+
+	var buf bytes.Buffer
+	var position int
+	for _, t := range tokens {
+		if t.tok != scanner.PLACEHOLDER || (dollar && strings.HasPrefix(t.text, "?")) {
+			// a "?" is a postgres jsonb operator if the query has "$" placeholders
+			buf.WriteString(t.text)
+			continue
+		}
+		index := position
+		position++
+		if n, err := strconv.Atoi(t.text[1:]); err == nil {
+			index = n - 1
+		}
+		if index < 0 || index >= len(args) {
+			buf.WriteString(t.text)
+			continue
+		}
+		buf.WriteString(sqlLiteral(args[index]))
+	}
+	return buf.String()
+}
+
+// sqlLiteral returns a readable SQL literal for the value of an arg.
+func sqlLiteral(arg interface{}) string {
+	if valuer, ok := arg.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return fmt.Sprintf("/* %v */", err)
+		}
+		arg = v
+	}
+	if arg == nil {
+		return "NULL"
+	}
+	switch v := arg.(type) {
+	case string:
+		return quoteString(v)
+	case []byte:
+		return quoteString(string(v))
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		return quoteString(v.Format("2006-01-02 15:04:05.999999999Z07:00"))
+	}
+	rv := reflect.ValueOf(arg)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return sqlLiteral(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(arg)
+	case reflect.String:
+		return quoteString(rv.String())
+	case reflect.Bool:
+		return sqlLiteral(rv.Bool())
+	}
+	return quoteString(fmt.Sprint(arg))
+}
+
+// quoteString returns s in single quotes, with any single quotes doubled.
+func quoteString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,62 @@
+package sqlr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToQueryBuilderSQL(t *testing.T) {
+	type User struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	name := "O'Brien"
+	tests := []struct {
+		dialect Dialect
+		query   string
+		args    []interface{}
+		want    string
+	}{
+		{
+			dialect: Postgres,
+			query:   "select {} from users where id = ?",
+			args:    []interface{}{42},
+			want:    `select "id","name" from users where id = 42`,
+		},
+		{
+			dialect: MySQL,
+			query:   "select {} from users where name = ? and id in (?)",
+			args:    []interface{}{"O'Brien", []int{1, 2, 3}},
+			want:    "select `id`,`name` from users where name = 'O''Brien' and id in (1,2,3)",
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users where name = ? or name = ? or name is ?",
+			args:    []interface{}{&name, []byte("x"), nil},
+			want:    `select "id","name" from users where name = 'O''Brien' or name = 'x' or name is NULL`,
+		},
+		{
+			dialect: SQLite,
+			query:   "select {} from users where created_at > ? and active = ? and score > ?",
+			args:    []interface{}{time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), true, 1.5},
+			want:    "select `id`,`name` from users where created_at > '2020-01-02 03:04:05Z' and active = TRUE and score > 1.5",
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users where id = ? and name = ?",
+			args:    []interface{}{42},
+			want:    `select "id","name" from users where id = 42 and name = $2`,
+		},
+	}
+	for i, tt := range tests {
+		schema := NewSchema(WithDialect(tt.dialect))
+		stmt, err := schema.Prepare(User{}, tt.query)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := stmt.ToQueryBuilderSQL(tt.args...), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
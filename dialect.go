@@ -3,6 +3,8 @@ package sqlr
 import (
 	"database/sql"
 	"database/sql/driver"
+	"fmt"
+	"strings"
 
 	"github.com/jjeffery/sqlr/private/dialect"
 )
@@ -27,6 +29,7 @@ type Dialect interface {
 var (
 	Postgres Dialect // Quote: "column_name", Placeholders: $1, $2, $3
 	MySQL    Dialect // Quote: `column_name`, Placeholders: ?, ?, ?
+	MariaDB  Dialect // Quote: `column_name`, Placeholders: ?, ?, ?
 	MSSQL    Dialect // Quote: [column_name], Placeholders: ?, ?, ?
 	SQLite   Dialect // Quote: `column_name`, Placeholders: ?, ?, ?
 	ANSISQL  Dialect // Quote: "column_name", Placeholders: ?, ?, ?
@@ -45,10 +48,11 @@ var allDialects []Dialect
 func init() {
 	Postgres = dialect.Postgres
 	MySQL = dialect.MySQL
+	MariaDB = dialect.MariaDB
 	MSSQL = dialect.MSSQL
 	SQLite = dialect.SQLite
 	ANSISQL = dialect.ANSI
-	allDialects = []Dialect{Postgres, MySQL, MSSQL, SQLite, ANSISQL}
+	allDialects = []Dialect{Postgres, MySQL, MariaDB, MSSQL, SQLite, ANSISQL}
 
 	DefaultDialect = ANSISQL
 
@@ -62,6 +66,8 @@ func init() {
 			DefaultDialect = Postgres
 		case "mysql":
 			DefaultDialect = MySQL
+		case "mariadb":
+			DefaultDialect = MariaDB
 		case "sqlite", "sqlite3":
 			DefaultDialect = SQLite
 		case "mssql":
@@ -70,7 +76,46 @@ func init() {
 	}
 }
 
+// dialectName returns the name of one of the pre-defined dialects, or the
+// name of a dialect created by NewCustomDialect. It returns an empty string
+// for other custom dialects.
+func dialectName(d Dialect) string {
+	switch d {
+	case Postgres:
+		return "postgres"
+	case MySQL:
+		return "mysql"
+	case MariaDB:
+		return "mariadb"
+	case MSSQL:
+		return "mssql"
+	case SQLite:
+		return "sqlite"
+	case ANSISQL:
+		return "ansi"
+	}
+	if n, ok := d.(interface {
+		Name() string
+	}); ok {
+		return n.Name()
+	}
+	return ""
+}
+
+// dialectFor returns the dialect for the DB driver, or the default
+// dialect if the driver is not recognised.
 func dialectFor(db *sql.DB) Dialect {
+	if dialect := matchDialect(db); dialect != nil {
+		return dialect
+	}
+	// dialect not found for driver, use default
+	return DefaultDialect
+}
+
+// matchDialect returns the dialect for the DB driver, or nil if
+// the driver is not recognised. MariaDB uses the MySQL driver, so
+// it is matched as MySQL: use NegotiateMariaDB to choose MariaDB.
+func matchDialect(db *sql.DB) Dialect {
 	if db != nil {
 		if drvr := db.Driver(); drvr != nil {
 			for _, dlct := range allDialects {
@@ -84,6 +129,35 @@ func dialectFor(db *sql.DB) Dialect {
 			}
 		}
 	}
-	// dialect not found for driver, use default
-	return DefaultDialect
+	return nil
+}
+
+// NegotiateMariaDB is a dialect negotiator for use with the WithDialectNegotiator
+// option. MariaDB uses the same driver as MySQL, so it cannot be distinguished by
+// driver. NegotiateMariaDB queries the database version and returns MariaDB for
+// MariaDB 10.5 and later, which support "insert ... returning". Earlier versions
+// of MariaDB are compatible with MySQL, and MySQL is returned. If the database is
+// not MariaDB, or the version cannot be determined, it returns nil so that the
+// dialect is determined by the driver.
+//
+//  schema := sqlr.NewSchema(
+//      sqlr.WithDialectNegotiator(sqlr.NegotiateMariaDB),
+//      sqlr.ForDB(db),
+//  )
+func NegotiateMariaDB(db *sql.DB) Dialect {
+	var version string
+	if err := db.QueryRow("select version()").Scan(&version); err != nil {
+		return nil
+	}
+	if !strings.Contains(strings.ToLower(version), "mariadb") {
+		return nil
+	}
+	var major, minor int
+	if _, err := fmt.Sscanf(version, "%d.%d", &major, &minor); err != nil {
+		return nil
+	}
+	if major > 10 || (major == 10 && minor >= 5) {
+		return MariaDB
+	}
+	return MySQL
 }
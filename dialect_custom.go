@@ -0,0 +1,111 @@
+package sqlr
+
+import (
+	"strings"
+
+	"github.com/jjeffery/sqlr/private/dialect"
+)
+
+// DialectPlaceholderStyle determines how a custom dialect writes placeholders.
+// See WithPlaceholderStyle.
+type DialectPlaceholderStyle int
+
+// Placeholder styles
+const (
+	DialectPlaceholderQuestion DialectPlaceholderStyle = iota // ?, ?, ? (the default)
+	DialectPlaceholderDollar                                  // $1, $2, $3
+	DialectPlaceholderColon                                   // :1, :2, :3
+)
+
+// DialectQuoteStyle determines how a custom dialect quotes table names and
+// column names. See WithQuoteStyle.
+type DialectQuoteStyle int
+
+// Quote styles
+const (
+	DialectQuoteDouble   DialectQuoteStyle = iota // "column_name" (the default)
+	DialectQuoteBacktick                          // `column_name`
+	DialectQuoteSquare                            // [column_name]
+)
+
+// A DialectOption provides optional configuration for a custom dialect,
+// and is supplied to NewCustomDialect.
+type DialectOption func(cd *customDialect)
+
+type customDialect struct {
+	placeholderStyle DialectPlaceholderStyle
+	quoteStyle       DialectQuoteStyle
+	driverNames      []string
+}
+
+// NewCustomDialect returns a dialect for a database that is not supported by
+// one of the pre-defined dialects, such as a database proxy with its own SQL
+// syntax. The dialect is assembled from opts, and without any options it quotes
+// names with double quotes and uses "?" for placeholders.
+//
+//  dialect := sqlr.NewCustomDialect("proxy",
+//      sqlr.WithPlaceholderStyle(sqlr.DialectPlaceholderColon),
+//      sqlr.WithQuoteStyle(sqlr.DialectQuoteBacktick),
+//      sqlr.WithDriverName("proxydriver"),
+//  )
+//  schema := sqlr.NewSchema(sqlr.WithDialect(dialect))
+//
+// The name is reported by Schema.InspectDialect. A custom dialect is not chosen
+// by the ForDB option, so specify it with the WithDialect option.
+func NewCustomDialect(name string, opts ...DialectOption) Dialect {
+	var cd customDialect
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cd)
+		}
+	}
+
+	begin, end := `"`, `"`
+	switch cd.quoteStyle {
+	case DialectQuoteBacktick:
+		begin, end = "`", "`"
+	case DialectQuoteSquare:
+		begin, end = "[", "]"
+	}
+
+	var placeholderFormat string
+	switch cd.placeholderStyle {
+	case DialectPlaceholderDollar:
+		placeholderFormat = "$%d"
+	case DialectPlaceholderColon:
+		placeholderFormat = ":%d"
+	}
+
+	return dialect.Custom(name, begin, end, placeholderFormat, cd.driverNames)
+}
+
+// WithPlaceholderStyle provides an option that sets how a custom dialect
+// writes placeholders.
+func WithPlaceholderStyle(style DialectPlaceholderStyle) DialectOption {
+	return func(cd *customDialect) {
+		cd.placeholderStyle = style
+	}
+}
+
+// WithQuoteStyle provides an option that sets how a custom dialect quotes
+// table names and column names.
+func WithQuoteStyle(style DialectQuoteStyle) DialectOption {
+	return func(cd *customDialect) {
+		cd.quoteStyle = style
+	}
+}
+
+// WithDriverName provides an option that adds the name that the database
+// driver for a custom dialect is registered with, for use with sql.Open.
+// It can be specified more than once if there are several drivers.
+func WithDriverName(name string) DialectOption {
+	return func(cd *customDialect) {
+		cd.driverNames = append(cd.driverNames, name)
+	}
+}
+
+// colonPlaceholders returns true if the dialect's placeholders are
+// numbered with a colon prefix, eg ":1".
+func colonPlaceholders(d Dialect) bool {
+	return strings.HasPrefix(d.Placeholder(1), ":")
+}
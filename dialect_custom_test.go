@@ -0,0 +1,96 @@
+package sqlr
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestNewCustomDialect(t *testing.T) {
+	tests := []struct {
+		opts            []DialectOption
+		wantQuote       string
+		wantPlaceholder string
+		wantDriverNames []string
+	}{
+		{
+			wantQuote:       `"a"."b"`,
+			wantPlaceholder: "?",
+		},
+		{
+			opts: []DialectOption{
+				WithPlaceholderStyle(DialectPlaceholderDollar),
+				WithQuoteStyle(DialectQuoteBacktick),
+				WithDriverName("proxy"),
+			},
+			wantQuote:       "`a`.`b`",
+			wantPlaceholder: "$2",
+			wantDriverNames: []string{"proxy"},
+		},
+		{
+			opts: []DialectOption{
+				WithPlaceholderStyle(DialectPlaceholderColon),
+				WithQuoteStyle(DialectQuoteSquare),
+				WithDriverName("proxy"),
+				WithDriverName("proxy2"),
+			},
+			wantQuote:       "[a].[b]",
+			wantPlaceholder: ":2",
+			wantDriverNames: []string{"proxy", "proxy2"},
+		},
+	}
+	for i, tt := range tests {
+		d := NewCustomDialect("custom", tt.opts...)
+		if got, want := d.Quote("a.b"), tt.wantQuote; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		if got, want := d.Placeholder(2), tt.wantPlaceholder; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		info := NewSchema(WithDialect(d)).InspectDialect()
+		if got, want := info.Name, "custom"; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		if got, want := info.DriverNames, tt.wantDriverNames; !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+	}
+}
+
+func TestCustomDialectColonPlaceholders(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	d := NewCustomDialect("colon", WithPlaceholderStyle(DialectPlaceholderColon))
+	schema := NewSchema(WithDialect(d))
+	stmt, err := schema.Prepare(Row{}, "select {} from rows where name = ? and id in (?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), `select "id","name" from rows where name = :1 and id in (:2)`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`select "id","name" from rows where name = :1 and id in (:2,:3)`)).
+		WithArgs("x", 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "x"))
+	var rows []*Row
+	if _, err := stmt.Select(db, &rows, "x", []int{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(rows), 1; got != want {
+		t.Errorf("want=%d, got=%d", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,54 @@
+package sqlr
+
+import "strings"
+
+// DialectInfo describes the SQL dialect of a schema. See Schema.InspectDialect.
+type DialectInfo struct {
+	Name                   string             // name of the dialect, eg "postgres", or empty for a custom dialect not created by NewCustomDialect
+	Placeholder            func(n int) string // placeholder for the nth arg, eg "$1" or "?"
+	QuoteChar              string             // opening quote for column names, eg `"`, "`" or "["
+	SupportsReturning      bool               // supports "insert ... returning"
+	SupportsMultiRowInsert bool               // supports inserting multiple rows in one statement
+	MaxPlaceholders        int                // maximum number of placeholders in a statement, zero if not known
+	DriverNames            []string           // names the database drivers are registered with, eg "postgres"
+}
+
+// InspectDialect returns information about the schema's dialect, for example
+// to report which kind of database a schema is used with. Custom dialects that
+// implement the Dialect interface report the information that is available from
+// their methods: a custom dialect can implement any of the methods
+//  SupportsReturning() bool
+//  SupportsMultiRowInsert() bool
+//  MaxPlaceholders() int
+//  DriverNames() []string
+func (s *Schema) InspectDialect() DialectInfo {
+	d := s.getDialect()
+	info := DialectInfo{
+		Name:        dialectName(d),
+		Placeholder: d.Placeholder,
+	}
+	if quoted := d.Quote("x"); strings.Contains(quoted, "x") {
+		info.QuoteChar = quoted[:strings.Index(quoted, "x")]
+	}
+	if r, ok := d.(interface {
+		SupportsReturning() bool
+	}); ok {
+		info.SupportsReturning = r.SupportsReturning()
+	}
+	if m, ok := d.(interface {
+		SupportsMultiRowInsert() bool
+	}); ok {
+		info.SupportsMultiRowInsert = m.SupportsMultiRowInsert()
+	}
+	if m, ok := d.(interface {
+		MaxPlaceholders() int
+	}); ok {
+		info.MaxPlaceholders = m.MaxPlaceholders()
+	}
+	if n, ok := d.(interface {
+		DriverNames() []string
+	}); ok {
+		info.DriverNames = n.DriverNames()
+	}
+	return info
+}
@@ -0,0 +1,50 @@
+package sqlr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInspectDialect(t *testing.T) {
+	tests := []struct {
+		dialect         Dialect
+		name            string
+		placeholder     string
+		quoteChar       string
+		returning       bool
+		multiRowInsert  bool
+		maxPlaceholders int
+		driverNames     []string
+	}{
+		{Postgres, "postgres", "$2", `"`, true, true, 65535, []string{"postgres", "pgx"}},
+		{MySQL, "mysql", "?", "`", false, true, 65535, []string{"mysql"}},
+		{MariaDB, "mariadb", "?", "`", true, true, 65535, []string{"mysql"}},
+		{MSSQL, "mssql", "?", "[", false, true, 2100, []string{"sqlserver", "mssql"}},
+		{SQLite, "sqlite", "?", "`", false, true, 32766, []string{"sqlite3"}},
+		{ANSISQL, "ansi", "?", `"`, false, false, 0, nil},
+	}
+	for i, tt := range tests {
+		info := NewSchema(WithDialect(tt.dialect)).InspectDialect()
+		if got, want := info.Name, tt.name; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		if got, want := info.Placeholder(2), tt.placeholder; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		if got, want := info.QuoteChar, tt.quoteChar; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		if got, want := info.SupportsReturning, tt.returning; got != want {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+		if got, want := info.SupportsMultiRowInsert, tt.multiRowInsert; got != want {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+		if got, want := info.MaxPlaceholders, tt.maxPlaceholders; got != want {
+			t.Errorf("%d: want=%d, got=%d", i, want, got)
+		}
+		if got, want := info.DriverNames, tt.driverNames; !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package sqlr
 
 import (
+	"regexp"
 	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
 )
 
 func TestDialect(t *testing.T) {
@@ -40,3 +43,43 @@ func TestDialectFor(t *testing.T) {
 		t.Errorf("want=%v, got=%v", want, got)
 	}
 }
+
+func TestNegotiateMariaDB(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		version string
+		want    Dialect
+	}{
+		{
+			version: "10.5.8-MariaDB-1:10.5.8+maria~focal",
+			want:    MariaDB,
+		},
+		{
+			version: "11.0.2-MariaDB",
+			want:    MariaDB,
+		},
+		{
+			version: "10.3.27-MariaDB-0+deb10u1",
+			want:    MySQL,
+		},
+		{
+			version: "5.7.19-log",
+			want:    nil,
+		},
+	}
+	for i, tt := range tests {
+		mock.ExpectQuery(regexp.QuoteMeta("select version()")).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(tt.version))
+		if got, want := NegotiateMariaDB(db), tt.want; got != want {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,156 @@
+package sqlr
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// ColumnEncryptor encrypts and decrypts the contents of columns whose
+// fields have the "encrypt" struct tag.
+//
+// Encrypt encrypts plaintext using the key identified by keyID. The
+// returned text is stored in the database, and must identify the key
+// used, so that Decrypt can decrypt it.
+type ColumnEncryptor interface {
+	Encrypt(keyID string, plaintext []byte) (string, error)
+	Decrypt(text string) ([]byte, error)
+}
+
+// MultiKeyEncryptor is a ColumnEncryptor that uses AES-GCM with one of
+// a number of keys. Encrypted text is stored in the format "keyid:ciphertext",
+// where ciphertext is base64 encoded. Because the key ID is stored alongside
+// the ciphertext, text encrypted with any of the keys can be decrypted. This
+// makes it possible to rotate keys without re-encrypting existing rows.
+type MultiKeyEncryptor struct {
+	aeads map[string]cipher.AEAD
+}
+
+// NewMultiKeyEncryptor returns an encryptor for the keys, which are indexed by
+// key ID. Each key must be 16, 24 or 32 bytes long, and key IDs cannot contain
+// a colon.
+func NewMultiKeyEncryptor(keys map[string][]byte) (*MultiKeyEncryptor, error) {
+	e := &MultiKeyEncryptor{
+		aeads: make(map[string]cipher.AEAD, len(keys)),
+	}
+	for keyID, key := range keys {
+		if keyID == "" || strings.Contains(keyID, ":") {
+			return nil, fmt.Errorf("invalid encryption key id %q", keyID)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key id %q: %v", keyID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key id %q: %v", keyID, err)
+		}
+		e.aeads[keyID] = aead
+	}
+	return e, nil
+}
+
+// Encrypt implements the ColumnEncryptor interface.
+func (e *MultiKeyEncryptor) Encrypt(keyID string, plaintext []byte) (string, error) {
+	aead, ok := e.aeads[keyID]
+	if !ok {
+		return "", fmt.Errorf("unknown encryption key id %q", keyID)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return keyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt implements the ColumnEncryptor interface.
+func (e *MultiKeyEncryptor) Decrypt(text string) ([]byte, error) {
+	i := strings.IndexByte(text, ':')
+	if i < 0 {
+		return nil, fmt.Errorf("missing encryption key id")
+	}
+	keyID := text[:i]
+	aead, ok := e.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key id %q", keyID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(text[i+1:])
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce := sealed[:aead.NonceSize()]
+	return aead.Open(nil, nonce, sealed[aead.NonceSize():], nil)
+}
+
+// encryptValue returns the encrypted contents of the field associated
+// with an encrypted column.
+func (stmt *Stmt) encryptValue(fieldName string, keyID string, v reflect.Value) (interface{}, error) {
+	if stmt.encryptor == nil {
+		return nil, fmt.Errorf("cannot encrypt field %q: no encryptor", fieldName)
+	}
+	if stmt.encryptionKeyID != "" {
+		keyID = stmt.encryptionKeyID
+	}
+	if keyID == "" {
+		return nil, fmt.Errorf("cannot encrypt field %q: no encryption key id", fieldName)
+	}
+	var plaintext []byte
+	switch {
+	case v.Kind() == reflect.String:
+		plaintext = []byte(v.String())
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		plaintext = v.Bytes()
+	default:
+		return nil, fmt.Errorf("cannot encrypt field %q of type %s", fieldName, v.Type())
+	}
+	text, err := stmt.encryptor.Encrypt(keyID, plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encrypt field %q: %v", fieldName, err)
+	}
+	return text, nil
+}
+
+// encryptedCell is used for scanning encrypted columns. The contents
+// are decrypted and stored in the cell value, which must be a string
+// or a byte slice. A NULL value is stored as the empty value.
+type encryptedCell struct {
+	colname   string
+	cellValue reflect.Value
+	encryptor ColumnEncryptor
+}
+
+func (ec *encryptedCell) Scan(v interface{}) error {
+	if ec.encryptor == nil {
+		return fmt.Errorf("cannot decrypt column %q: no encryptor", ec.colname)
+	}
+	var nullable sql.NullString
+	if err := nullable.Scan(v); err != nil {
+		return fmt.Errorf("cannot scan column %q: %v", ec.colname, err)
+	}
+	var plaintext []byte
+	if nullable.Valid {
+		var err error
+		if plaintext, err = ec.encryptor.Decrypt(nullable.String); err != nil {
+			return fmt.Errorf("cannot decrypt column %q: %v", ec.colname, err)
+		}
+	}
+	switch {
+	case ec.cellValue.Kind() == reflect.String:
+		ec.cellValue.SetString(string(plaintext))
+	case ec.cellValue.Kind() == reflect.Slice && ec.cellValue.Type().Elem().Kind() == reflect.Uint8:
+		ec.cellValue.SetBytes(plaintext)
+	default:
+		return fmt.Errorf("cannot decrypt column %q of type %s", ec.colname, ec.cellValue.Type())
+	}
+	return nil
+}
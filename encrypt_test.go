@@ -0,0 +1,151 @@
+package sqlr
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestMultiKeyEncryptor(t *testing.T) {
+	key1 := bytes.Repeat([]byte{1}, 32)
+	key2 := bytes.Repeat([]byte{2}, 16)
+	enc, err := NewMultiKeyEncryptor(map[string][]byte{"key1": key1, "key2": key2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, keyID := range []string{"key1", "key2"} {
+		text, err := enc.Encrypt(keyID, []byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(text, keyID+":") {
+			t.Errorf("want prefix %q, got %q", keyID+":", text)
+		}
+		plaintext, err := enc.Decrypt(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(plaintext), "secret"; got != want {
+			t.Errorf("want=%q, got=%q", want, got)
+		}
+	}
+
+	if _, err := enc.Encrypt("key3", []byte("secret")); err == nil {
+		t.Error("want error for unknown key, got nil")
+	}
+	if _, err := enc.Decrypt("key3:AAAA"); err == nil {
+		t.Error("want error for unknown key, got nil")
+	}
+
+	// text encrypted with a key that is no longer registered
+	old, err := NewMultiKeyEncryptor(map[string][]byte{"key1": key2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := old.Encrypt("key1", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := enc.Decrypt(text); err == nil {
+		t.Error("want error for wrong key, got nil")
+	}
+
+	for _, keys := range []map[string][]byte{
+		{"key:1": key1},
+		{"": key1},
+		{"key1": []byte("short")},
+	} {
+		if _, err := NewMultiKeyEncryptor(keys); err == nil {
+			t.Errorf("want error for %v, got nil", keys)
+		}
+	}
+}
+
+// encryptedArg matches an arg encrypted with a key ID and decrypts
+// it for comparison.
+type encryptedArg struct {
+	enc       ColumnEncryptor
+	keyID     string
+	plaintext string
+}
+
+func (a encryptedArg) Match(v driver.Value) bool {
+	text, ok := v.(string)
+	if !ok || !strings.HasPrefix(text, a.keyID+":") {
+		return false
+	}
+	plaintext, err := a.enc.Decrypt(text)
+	return err == nil && string(plaintext) == a.plaintext
+}
+
+func TestEncryptedColumns(t *testing.T) {
+	type Row struct {
+		ID    int    `sql:"primary key"`
+		SSN   string `sql:"ssn encrypt"`
+		Notes []byte `sql:"encrypt_key_id:key1"`
+	}
+	enc, err := NewMultiKeyEncryptor(map[string][]byte{
+		"key1": bytes.Repeat([]byte{1}, 32),
+		"key2": bytes.Repeat([]byte{2}, 32),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// no active key, so the key ID in the tag is used if there is one
+	schema := NewSchema(WithDialect(MySQL), WithEncryptor(enc))
+	row := Row{ID: 1, SSN: "123-45-6789", Notes: []byte("notes")}
+	if _, err := schema.Exec(db, &row, "insert rows"); err == nil {
+		t.Error("want error for missing key id, got nil")
+	}
+
+	type NotesRow struct {
+		ID    int    `sql:"primary key"`
+		Notes []byte `sql:"encrypt_key_id:key1"`
+	}
+	mock.ExpectExec("insert into notes").
+		WithArgs(1, encryptedArg{enc, "key1", "notes"}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := schema.Exec(db, &NotesRow{ID: 1, Notes: []byte("notes")}, "insert notes"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the active key takes precedence
+	schema = schema.Clone(WithActiveEncryptionKeyID("key2"))
+	mock.ExpectExec("insert into rows").
+		WithArgs(1, encryptedArg{enc, "key2", "123-45-6789"}, encryptedArg{enc, "key2", "notes"}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := schema.Exec(db, &row, "insert rows"); err != nil {
+		t.Fatal(err)
+	}
+
+	ssn, err := enc.Encrypt("key1", []byte("987-65-4321"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery("select `id`,`ssn`,`notes` from rows").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ssn", "notes"}).AddRow(2, ssn, nil))
+	var row2 Row
+	if _, err := schema.Select(db, &row2, "select rows", 2); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := row2.SSN, "987-65-4321"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+	if row2.Notes != nil {
+		t.Errorf("want nil, got %q", row2.Notes)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,66 @@
+package sqlr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jjeffery/sqlr/private/column"
+)
+
+// ErrInvalidEnum is the cause of an *EnumError. See WithEnumValidation.
+var ErrInvalidEnum = errors.New("invalid enum value")
+
+// EnumError is returned when a field tagged with "enum" has a value that is
+// not one of the values in the tag. See WithEnumValidation.
+type EnumError struct {
+	Field  string   // field name, or names joined by periods for embedded structs
+	Value  string   // value of the field
+	Values []string // values permitted by the tag
+}
+
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("%v for field %q: %q is not one of %s",
+		ErrInvalidEnum, e.Field, e.Value, strings.Join(e.Values, ","))
+}
+
+// Cause returns ErrInvalidEnum.
+func (e *EnumError) Cause() error {
+	return ErrInvalidEnum
+}
+
+// Unwrap returns ErrInvalidEnum, so that errors.Is(err, ErrInvalidEnum)
+// reports true for an *EnumError.
+func (e *EnumError) Unwrap() error {
+	return ErrInvalidEnum
+}
+
+// validateEnum returns an *EnumError if the value of an enum field is not one
+// of the values in its tag. Only string fields are checked. Nil pointers are
+// valid, and so are empty strings if they are stored as NULL.
+func validateEnum(col *column.Info, v reflect.Value, emptyNull bool) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return nil
+	}
+	value := v.String()
+	if value == "" && emptyNull {
+		return nil
+	}
+	for _, enum := range col.Tag.Enum {
+		if value == enum {
+			return nil
+		}
+	}
+	return &EnumError{
+		Field:  col.FieldNames,
+		Value:  value,
+		Values: col.Tag.Enum,
+	}
+}
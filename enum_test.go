@@ -0,0 +1,82 @@
+package sqlr
+
+import (
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestWithEnumValidation(t *testing.T) {
+	type Row struct {
+		ID     int     `sql:"primary key"`
+		Status string  `sql:"enum:active,inactive"`
+		Level  *string `sql:"enum:low,high"`
+		Kind   string  `sql:"null enum:a,b"`
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	high, medium := "high", "medium"
+	query := regexp.QuoteMeta("insert into rows(`id`,`status`,`level`,`kind`) values(?,?,?,?)")
+
+	tests := []struct {
+		row     Row
+		wantErr string
+	}{
+		{
+			row: Row{ID: 1, Status: "active", Level: &high, Kind: "a"},
+		},
+		{
+			// nil pointer and empty null field are not checked
+			row: Row{ID: 2, Status: "inactive"},
+		},
+		{
+			row:     Row{ID: 3, Status: "deleted"},
+			wantErr: `invalid enum value for field "Status": "deleted" is not one of active,inactive`,
+		},
+		{
+			row:     Row{ID: 4, Status: "active", Level: &medium},
+			wantErr: `invalid enum value for field "Level": "medium" is not one of low,high`,
+		},
+		{
+			row:     Row{ID: 5, Status: ""},
+			wantErr: `invalid enum value for field "Status": "" is not one of active,inactive`,
+		},
+	}
+
+	schema := NewSchema(WithDialect(MySQL), WithEnumValidation(true))
+	for i, tt := range tests {
+		if tt.wantErr == "" {
+			mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		_, err := schema.Exec(db, &tt.row, "insert rows")
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%d: want no error, got %v", i, err)
+			}
+		} else {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%d: want error %q, got %v", i, tt.wantErr, err)
+				continue
+			}
+			if enumErr, ok := err.(*EnumError); !ok || enumErr.Cause() != ErrInvalidEnum {
+				t.Errorf("%d: want *EnumError, got %T", i, err)
+			}
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%d: %v", i, err)
+		}
+	}
+
+	// no validation without the option
+	mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := NewSchema(WithDialect(MySQL)).Exec(db, &Row{ID: 6, Status: "deleted"}, "insert rows"); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,52 @@
+package sqlr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrRowsAffected is the cause of a *RowsAffectedError. See Stmt.ExecExpect.
+var ErrRowsAffected = errors.New("unexpected number of rows affected")
+
+// RowsAffectedError is returned by ExecExpect when the statement does not
+// affect the expected number of rows.
+type RowsAffectedError struct {
+	Expected int // number of rows expected to be affected
+	Actual   int // number of rows affected
+}
+
+func (e *RowsAffectedError) Error() string {
+	return fmt.Sprintf("%v: expected=%d, actual=%d", ErrRowsAffected, e.Expected, e.Actual)
+}
+
+// Cause returns ErrRowsAffected.
+func (e *RowsAffectedError) Cause() error {
+	return ErrRowsAffected
+}
+
+// Unwrap returns ErrRowsAffected, so that errors.Is(err, ErrRowsAffected)
+// reports true for a *RowsAffectedError.
+func (e *RowsAffectedError) Unwrap() error {
+	return ErrRowsAffected
+}
+
+// ExecExpect executes the prepared statement in the same way as Exec, and
+// returns a *RowsAffectedError if the number of rows affected is not expected.
+// It is common to expect exactly one row to be affected when updating or
+// deleting a row by its primary key:
+//  if err := stmt.ExecExpect(db, &user, 1); err != nil {
+//      return err
+//  }
+//
+// Note that for some databases, such as MySQL, the number of rows affected by
+// an update does not include rows that matched but were not changed.
+func (stmt *Stmt) ExecExpect(db DB, row interface{}, expected int, args ...interface{}) error {
+	n, err := stmt.Exec(db, row, args...)
+	if err != nil {
+		return err
+	}
+	if n != expected {
+		return &RowsAffectedError{Expected: expected, Actual: n}
+	}
+	return nil
+}
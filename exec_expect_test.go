@@ -0,0 +1,55 @@
+package sqlr
+
+import (
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestExecExpect(t *testing.T) {
+	type User struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+	stmt, err := schema.Prepare(User{}, "update users set {} where {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := regexp.QuoteMeta(`update users set "name"=$1 where "id"=$2`)
+
+	tests := []struct {
+		rowsAffected int64
+		wantErr      string
+	}{
+		{rowsAffected: 1},
+		{rowsAffected: 0, wantErr: "unexpected number of rows affected: expected=1, actual=0"},
+		{rowsAffected: 2, wantErr: "unexpected number of rows affected: expected=1, actual=2"},
+	}
+	for i, tt := range tests {
+		mock.ExpectExec(query).WithArgs("Alice", 1).WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+		err := stmt.ExecExpect(db, &User{ID: 1, Name: "Alice"}, 1)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%d: %v", i, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%d: want=%q, got=%v", i, tt.wantErr, err)
+			continue
+		}
+		if rerr, ok := err.(*RowsAffectedError); !ok || rerr.Cause() != ErrRowsAffected || rerr.Actual != int(tt.rowsAffected) {
+			t.Errorf("%d: want *RowsAffectedError, got %#v", i, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
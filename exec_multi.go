@@ -0,0 +1,249 @@
+package sqlr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// maxMultiRowArgs is the maximum number of placeholder args in one multi-row
+// insert statement. MySQL does not accept more than 65535 placeholders.
+const maxMultiRowArgs = 65535
+
+// ExecMulti executes an INSERT statement for each row in rows, which must be a slice
+// of structs or a slice of struct pointers. It returns the auto-increment value
+// generated for the first row, and the number of rows inserted. If the row type does
+// not have an auto-increment column, firstID is zero.
+//
+// If db is a *sql.DB, all of the rows are inserted in a single transaction,
+// and no rows are committed if any insert fails. If db is a *sql.Tx, the
+// calling program is responsible for committing or rolling back the transaction.
+//
+// How the rows are inserted depends on the dialect:
+//
+// MySQL: Rows are inserted using multi-row insert statements. MySQL reports the
+// auto-increment value of the first row in each statement, and the values for the
+// other rows are assumed to follow it sequentially. This is only true if the server's
+// auto_increment_increment setting is 1, and the innodb_autoinc_lock_mode setting
+// is 0 ("traditional") or 1 ("consecutive"). With innodb_autoinc_lock_mode 2
+// ("interleaved"), the default for MySQL 8.0, concurrent inserts can cause gaps,
+// and the values set in the rows may be wrong.
+//
+// Postgres and MariaDB: Each row is inserted using an insert statement with a
+// returning clause, so the auto-increment value of each row is exact.
+//
+// Other dialects: Each row is inserted using Exec, which obtains the auto-increment
+// value from the driver, if the driver supports this.
+//
+// In every case, the auto-increment field of each row is set. Other than for MySQL,
+// the values are not necessarily sequential, so callers should use the values set in
+// the rows rather than computing them from firstID.
+//
+// If the row type has a method "TableName() string", then the query can be
+// the shorthand notation "insert":
+//  firstID, count, err := schema.ExecMulti(db, rows, "insert")
+func (s *Schema) ExecMulti(db DB, rows interface{}, query string, args ...interface{}) (firstID int64, count int, err error) {
+	sliceValue := reflect.Indirect(reflect.ValueOf(rows))
+	if sliceValue.Kind() != reflect.Slice {
+		return 0, 0, errors.New("expected rows to be a slice of structs or a slice of struct pointers")
+	}
+	stmt, err := s.Prepare(rows, query)
+	if err != nil {
+		return 0, 0, err
+	}
+	if stmt.queryType != queryInsert {
+		return 0, 0, fmt.Errorf("expected insert statement, got %q", stmt.query)
+	}
+	if sliceValue.Len() == 0 {
+		return 0, 0, nil
+	}
+
+	var tx *sql.Tx
+	if beginner, ok := db.(interface {
+		Begin() (*sql.Tx, error)
+	}); ok {
+		if tx, err = beginner.Begin(); err != nil {
+			return 0, 0, err
+		}
+		defer tx.Rollback()
+		db = tx
+	}
+
+	rowValues := make([]reflect.Value, sliceValue.Len())
+	for i := range rowValues {
+		rowValues[i] = sliceValue.Index(i)
+		if rowValues[i].Kind() == reflect.Struct {
+			rowValues[i] = rowValues[i].Addr()
+		}
+	}
+
+	returning, _ := stmt.dialect.(interface {
+		SupportsReturning() bool
+		ReturningClause(columns ...string) string
+	})
+	valuesGroup := multiRowValues(stmt.query)
+	switch {
+	case stmt.autoIncrColumn != nil && returning != nil && returning.SupportsReturning():
+		firstID, count, err = stmt.execReturning(db, rowValues, returning.ReturningClause, args)
+	case stmt.dialect == MySQL && valuesGroup != "":
+		firstID, count, err = stmt.execMultiRow(db, rowValues, valuesGroup, args)
+	default:
+		firstID, count, err = stmt.execEach(db, rowValues, args)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return 0, 0, err
+		}
+	}
+	return firstID, count, nil
+}
+
+// multiRowValues returns the values group, eg "(?,?,?)", if the insert query
+// ends with a single values group that can be repeated for multiple rows.
+// Otherwise it returns an empty string.
+func multiRowValues(query string) string {
+	index := strings.LastIndex(strings.ToLower(query), "values")
+	if index < 0 {
+		return ""
+	}
+	group := strings.TrimSpace(query[index+len("values"):])
+	if !strings.HasPrefix(group, "(") || !strings.Contains(group, "?") {
+		return ""
+	}
+	depth := 0
+	for i, ch := range group {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i != len(group)-1 {
+				return ""
+			}
+		case '\'', '"', '`':
+			// quoted literals in the values group are not expected
+			return ""
+		}
+	}
+	if depth != 0 {
+		return ""
+	}
+	return group
+}
+
+// execReturning inserts each row using an insert statement with a returning clause,
+// and sets the row's auto-increment field with the value returned.
+func (stmt *Stmt) execReturning(db DB, rowValues []reflect.Value, returningClause func(columns ...string) string, args []interface{}) (int64, int, error) {
+	query := stmt.query + returningClause(stmt.columnNamer.ColumnName(stmt.autoIncrColumn))
+	var firstID int64
+	for i, rowValue := range rowValues {
+		field := stmt.autoIncrColumn.Index.ValueRW(rowValue)
+		rowArgs, err := stmt.getArgs(rowValue.Interface(), args)
+		if err != nil {
+			return 0, 0, err
+		}
+		expanded, err := stmt.expandQuery(query, rowArgs)
+		if err != nil {
+			return 0, 0, err
+		}
+		rows, err := expanded.QueryOn(db)
+		if err != nil {
+			return 0, 0, fmt.Errorf("cannot insert row %d: %v", i, err)
+		}
+		var id int64
+		if rows.Next() {
+			err = rows.Scan(&id)
+		} else if err = rows.Err(); err == nil {
+			err = errors.New("no value returned")
+		}
+		rows.Close()
+		if err != nil {
+			return 0, 0, fmt.Errorf("cannot insert row %d: %v", i, err)
+		}
+		field.SetInt(id)
+		if i == 0 {
+			firstID = id
+		}
+	}
+	return firstID, len(rowValues), nil
+}
+
+// execMultiRow inserts the rows using multi-row insert statements, by repeating the
+// values group for each row. The auto-increment value reported for each statement
+// is the value for the first row, and the values for the other rows are assumed to
+// follow sequentially.
+func (stmt *Stmt) execMultiRow(db DB, rowValues []reflect.Value, valuesGroup string, args []interface{}) (int64, int, error) {
+	prefix := strings.TrimSuffix(strings.TrimSpace(stmt.query), valuesGroup)
+	batchSize := len(rowValues)
+	if len(stmt.inputs) > 0 && batchSize*len(stmt.inputs) > maxMultiRowArgs {
+		batchSize = maxMultiRowArgs / len(stmt.inputs)
+	}
+
+	var firstID int64
+	var count int
+	for start := 0; start < len(rowValues); start += batchSize {
+		end := start + batchSize
+		if end > len(rowValues) {
+			end = len(rowValues)
+		}
+		batch := rowValues[start:end]
+		var batchArgs []interface{}
+		for _, rowValue := range batch {
+			rowArgs, err := stmt.getArgs(rowValue.Interface(), args)
+			if err != nil {
+				return 0, 0, err
+			}
+			batchArgs = append(batchArgs, rowArgs...)
+		}
+		query := prefix + valuesGroup + strings.Repeat(","+valuesGroup, len(batch)-1)
+		expanded, err := stmt.expandQuery(query, batchArgs)
+		if err != nil {
+			return 0, 0, err
+		}
+		result, err := expanded.ExecOn(db)
+		if err != nil {
+			return 0, 0, err
+		}
+		if stmt.autoIncrColumn != nil {
+			id, err := result.LastInsertId()
+			if err != nil {
+				return 0, 0, err
+			}
+			if start == 0 {
+				firstID = id
+			}
+			for i, rowValue := range batch {
+				stmt.autoIncrColumn.Index.ValueRW(rowValue).SetInt(id + int64(i))
+			}
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, 0, err
+		}
+		count += int(rowsAffected)
+	}
+	return firstID, count, nil
+}
+
+// execEach inserts each row using Exec.
+func (stmt *Stmt) execEach(db DB, rowValues []reflect.Value, args []interface{}) (int64, int, error) {
+	var firstID int64
+	var count int
+	for i, rowValue := range rowValues {
+		n, err := stmt.Exec(db, rowValue.Interface(), args...)
+		if err != nil {
+			return 0, 0, fmt.Errorf("cannot insert row %d: %v", i, err)
+		}
+		if i == 0 && stmt.autoIncrColumn != nil {
+			firstID = stmt.autoIncrColumn.Index.ValueRO(rowValue).Int()
+		}
+		count += n
+	}
+	return firstID, count, nil
+}
@@ -0,0 +1,137 @@
+package sqlr
+
+import (
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+type execMultiRow struct {
+	ID   int64 `sql:"primary key autoincrement"`
+	Name string
+}
+
+func TestExecMulti(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		dialect Dialect
+		expect  func()
+		wantIDs []int64
+	}{
+		{
+			dialect: MySQL,
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("insert into rows(`name`) values(?),(?),(?)")).
+					WithArgs("A", "B", "C").
+					WillReturnResult(sqlmock.NewResult(10, 3))
+				mock.ExpectCommit()
+			},
+			wantIDs: []int64{10, 11, 12},
+		},
+		{
+			dialect: Postgres,
+			expect: func() {
+				query := regexp.QuoteMeta(`insert into rows("name") values($1) returning "id"`)
+				mock.ExpectBegin()
+				mock.ExpectQuery(query).WithArgs("A").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(20))
+				mock.ExpectQuery(query).WithArgs("B").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(22))
+				mock.ExpectQuery(query).WithArgs("C").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(23))
+				mock.ExpectCommit()
+			},
+			wantIDs: []int64{20, 22, 23},
+		},
+		{
+			dialect: SQLite,
+			expect: func() {
+				query := regexp.QuoteMeta("insert into rows(`name`) values(?)")
+				mock.ExpectBegin()
+				mock.ExpectExec(query).WithArgs("A").WillReturnResult(sqlmock.NewResult(30, 1))
+				mock.ExpectExec(query).WithArgs("B").WillReturnResult(sqlmock.NewResult(31, 1))
+				mock.ExpectExec(query).WithArgs("C").WillReturnResult(sqlmock.NewResult(32, 1))
+				mock.ExpectCommit()
+			},
+			wantIDs: []int64{30, 31, 32},
+		},
+	}
+
+	for i, tt := range tests {
+		schema := NewSchema(WithDialect(tt.dialect))
+		rows := []execMultiRow{{Name: "A"}, {Name: "B"}, {Name: "C"}}
+		tt.expect()
+		firstID, count, err := schema.ExecMulti(db, rows, "insert into rows({}) values({})")
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := firstID, tt.wantIDs[0]; got != want {
+			t.Errorf("%d: firstID: want=%d, got=%d", i, want, got)
+		}
+		if got, want := count, len(rows); got != want {
+			t.Errorf("%d: count: want=%d, got=%d", i, want, got)
+		}
+		for j, row := range rows {
+			if got, want := row.ID, tt.wantIDs[j]; got != want {
+				t.Errorf("%d/%d: ID: want=%d, got=%d", i, j, want, got)
+			}
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%d: %v", i, err)
+		}
+	}
+}
+
+func TestExecMultiErrors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL))
+
+	// insert fails, nothing is committed
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("insert into rows(`name`) values(?),(?)")).
+		WithArgs("A", "B").
+		WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
+	rows := []*execMultiRow{{Name: "A"}, {Name: "B"}}
+	if _, _, err := schema.ExecMulti(db, rows, "insert into rows({}) values({})"); err == nil {
+		t.Error("want error, got nil")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	if _, _, err := schema.ExecMulti(db, rows, "update rows set {} where {}"); err == nil {
+		t.Error("want error for update statement, got nil")
+	}
+	if _, _, err := schema.ExecMulti(db, execMultiRow{}, "insert into rows({}) values({})"); err == nil {
+		t.Error("want error for non-slice, got nil")
+	}
+}
+
+func TestMultiRowValues(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"insert into t(`a`,`b`) values(?,?)", "(?,?)"},
+		{"insert into t(a,b) VALUES (?, lower(?))", "(?, lower(?))"},
+		{"insert into t(a,b) values(?,?) on duplicate key update b = ?", ""},
+		{"insert into t(a,b) values(?,'x')", ""},
+		{"insert into t(a,b) values(?,?) on duplicate key update b = values(b)", ""},
+		{"insert into t(a) select a from u", ""},
+	}
+	for i, tt := range tests {
+		if got, want := multiRowValues(tt.query), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package sqlr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jjeffery/sqlr/private/column"
+	"github.com/jjeffery/sqlr/private/scanner"
+)
+
+// ExecReturning executes the prepared statement with the given row and optional
+// arguments, and stores the first row returned by the statement in dest. It is
+// intended for insert, update and delete statements with a returning clause, so
+// that values computed by the database server, such as auto-increment IDs and
+// default timestamps, can be obtained without another query:
+//  stmt, err := schema.Prepare(row, "insert into users({}) values({}) {returning}")
+//  err = stmt.ExecReturning(db, &row, &row)
+//
+// The "{returning}" expansion is a returning clause for all of the columns, and
+// is only available for dialects that support a returning clause, such as Postgres.
+// A returning clause can also be written as part of the query, eg "returning id".
+//
+// Dest must be a pointer to a struct. It can be the same as row, or it can be a
+// different struct type with fields for some or all of the returned columns. The
+// returned columns are matched to the fields of dest by column name, and an error
+// is returned if a returned column does not match a field. Only the fields for the
+// returned columns are changed, so "returning id" sets just the ID field of dest.
+// If the statement returns no rows, ExecReturning returns sql.ErrNoRows and dest
+// is not modified.
+//
+// Unlike Exec and Select, ExecReturning always executes the statement as a query,
+// regardless of the type of statement.
+func (stmt *Stmt) ExecReturning(db DB, row interface{}, dest interface{}, args ...interface{}) (err error) {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() || destValue.Elem().Kind() != reflect.Struct {
+		return errors.New("expected dest to be a non-nil pointer to a struct")
+	}
+	args, opts := splitStmtOptions(args)
+	if len(opts.omit) > 0 {
+		variant, err := stmt.omitting(opts.omit)
+		if err != nil {
+			return err
+		}
+		stmt = variant
+	}
+	qlog := stmt.newQueryLog()
+	defer func() {
+		var rowCount int
+		if err == nil {
+			rowCount = 1
+		}
+		qlog.done(rowCount, err)
+	}()
+
+	args, err = stmt.getArgs(row, args)
+	if err != nil {
+		return err
+	}
+	query, err := stmt.expandQuery(stmt.query, args)
+	if err != nil {
+		return err
+	}
+	qlog.executing(query)
+	rows, err := query.QueryOn(db)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	destType := destValue.Elem().Type()
+	destColumns := stmt.columns
+	if destType != stmt.rowType {
+		destColumns = column.ListForType(destType)
+	}
+	outputs, err := stmt.returningOutputs(rows, destType, destColumns)
+	if err != nil {
+		return err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	// scan into a copy so that dest is not modified if there is an error,
+	// and the fields for columns that are not returned keep their values
+	newValue := reflect.New(destType).Elem()
+	newValue.Set(destValue.Elem())
+	if err := stmt.scanRow(rows, outputs, newValue); err != nil {
+		return err
+	}
+	destValue.Elem().Set(newValue)
+	return nil
+}
+
+// returningOutputs matches the columns returned by the statement with the
+// columns of the dest type. The returned columns can be any subset of the
+// columns of the dest type.
+func (stmt *Stmt) returningOutputs(rows *sql.Rows, destType reflect.Type, destColumns []*column.Info) ([]*column.Info, error) {
+	columnMap := make(map[string]*column.Info)
+	for _, col := range destColumns {
+		columnMap[strings.ToLower(stmt.columnNamer.ColumnName(col))] = col
+	}
+	columnNames, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	outputs := make([]*column.Info, len(columnNames))
+	for i, columnName := range columnNames {
+		col := columnMap[strings.ToLower(columnName)]
+		if col == nil {
+			return nil, fmt.Errorf("unknown column name=%q for type %s", columnName, destType.Name())
+		}
+		outputs[i] = col
+	}
+	return outputs, nil
+}
+
+// isReturningExpansion reports whether lit is a "{returning}" expansion.
+func isReturningExpansion(lit string) bool {
+	return strings.ToLower(strings.TrimSpace(scanner.Unquote(lit))) == "returning"
+}
+
+// returningClause returns the returning clause for a "{returning}" expansion,
+// which returns all of the columns for the row type.
+func (stmt *Stmt) returningClause(lit string, clause sqlClause) (string, error) {
+	switch clause.queryType() {
+	case queryInsert, queryUpdate, queryDelete:
+	default:
+		return "", fmt.Errorf("cannot expand %q in %q clause", lit, clause)
+	}
+	d, ok := stmt.dialect.(interface {
+		SupportsReturning() bool
+		ReturningClause(columns ...string) string
+	})
+	if !ok || !d.SupportsReturning() {
+		return "", fmt.Errorf("cannot expand %q: dialect does not support a returning clause", lit)
+	}
+	names := make([]string, 0, len(stmt.columns))
+	for _, col := range stmt.columns {
+		names = append(names, stmt.columnNamer.ColumnName(col))
+	}
+	return strings.TrimSpace(d.ReturningClause(names...)), nil
+}
@@ -0,0 +1,123 @@
+package sqlr
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestExecReturning(t *testing.T) {
+	type Row struct {
+		ID        int `sql:"primary key autoincrement"`
+		Name      string
+		CreatedAt time.Time
+	}
+	type Result struct {
+		ID        int
+		CreatedAt time.Time
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+	created := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	stmt, err := schema.Prepare(Row{}, "insert into rows({}) values({}) {returning}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), `insert into rows("name","created_at") values($1,$2) returning "id","name","created_at"`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(stmt.String())).
+		WithArgs("Alice", time.Time{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at"}).AddRow(7, "Alice", created))
+	row := Row{Name: "Alice"}
+	if err := stmt.ExecReturning(db, &row, &row); err != nil {
+		t.Fatal(err)
+	}
+	if row.ID != 7 || !row.CreatedAt.Equal(created) {
+		t.Errorf("unexpected row: %+v", row)
+	}
+
+	// partial result into the same type: other fields are unchanged
+	stmt, err = schema.Prepare(Row{}, "insert into rows({}) values({}) returning id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`insert into rows("name","created_at") values($1,$2) returning id`)).
+		WithArgs("Carol", created).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	row2 := Row{Name: "Carol", CreatedAt: created}
+	if err := stmt.ExecReturning(db, &row2, &row2); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Row{ID: 9, Name: "Carol", CreatedAt: created}); row2 != want {
+		t.Errorf("want=%+v, got=%+v", want, row2)
+	}
+
+	// partial result into a different type
+	stmt, err = schema.Prepare(Row{}, "update rows set {} where {} returning id, created_at")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`update rows set "name"=$1,"created_at"=$2 where "id"=$3 returning id, created_at`)).
+		WithArgs("Bob", created, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, created))
+	row.Name = "Bob"
+	var result Result
+	if err := stmt.ExecReturning(db, &row, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.ID != 7 || !result.CreatedAt.Equal(created) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// no rows returned
+	mock.ExpectQuery(regexp.QuoteMeta(`update rows set "name"=$1,"created_at"=$2 where "id"=$3 returning id, created_at`)).
+		WithArgs("Bob", created, 8).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+	row.ID = 8
+	result = Result{ID: 1}
+	if err := stmt.ExecReturning(db, &row, &result); err != sql.ErrNoRows {
+		t.Errorf("want=%v, got=%v", sql.ErrNoRows, err)
+	}
+	if result.ID != 1 {
+		t.Errorf("dest modified: %+v", result)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	errorTests := []struct {
+		schema *Schema
+		query  string
+		want   string
+	}{
+		{
+			schema: NewSchema(WithDialect(MySQL)),
+			query:  "insert into rows({}) values({}) {returning}",
+			want:   `cannot expand "{returning}": dialect does not support a returning clause`,
+		},
+		{
+			schema: schema,
+			query:  "select {} from rows {returning}",
+			want:   `cannot expand "{returning}" in "select from" clause`,
+		},
+	}
+	for i, tt := range errorTests {
+		_, err := tt.schema.Prepare(Row{}, tt.query)
+		if got := fmt.Sprint(err); got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+	if err := stmt.ExecReturning(db, &row, result); err == nil {
+		t.Error("want error for dest that is not a pointer, got nil")
+	}
+}
@@ -0,0 +1,97 @@
+package sqlr
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// Explain returns the execution plan for the statement's query, as text. The
+// query is prefixed with the dialect's EXPLAIN form, eg "explain query plan" for
+// SQLite, and each row of the plan returned by the database is a line of text.
+// If the plan has more than one column, the first line contains the column names,
+// and the columns of each row are separated by tabs. The query is not executed.
+//
+// The args are the values for the placeholders in the query, in the same way as
+// the args for Select. For statements that are executed with a row, such as insert
+// and update statements, the values of the row's fields must be included in args.
+//
+// MSSQL does not support an EXPLAIN statement, and Explain returns an error.
+func (stmt *Stmt) Explain(db DB, args ...interface{}) (string, error) {
+	return stmt.explain(db, false, args)
+}
+
+// ExplainAnalyze is like Explain, except that the query is executed, and the plan
+// includes the actual row counts and timings. Because the query is executed, an
+// insert, update or delete statement changes the database, so consider calling
+// ExplainAnalyze in a transaction that is rolled back.
+//
+// ExplainAnalyze is supported by Postgres, MySQL 8.0.18 and later, and MariaDB.
+// For other dialects it returns an error.
+func (stmt *Stmt) ExplainAnalyze(db DB, args ...interface{}) (string, error) {
+	return stmt.explain(db, true, args)
+}
+
+func (stmt *Stmt) explain(db DB, analyze bool, args []interface{}) (string, error) {
+	prefix := "explain "
+	if analyze {
+		prefix = "explain analyze "
+	}
+	if d, ok := stmt.dialect.(interface {
+		ExplainPrefix(analyze bool) string
+	}); ok {
+		prefix = d.ExplainPrefix(analyze)
+	}
+	if prefix == "" {
+		if analyze {
+			return "", errors.New("explain analyze is not supported by the dialect")
+		}
+		return "", errors.New("explain is not supported by the dialect")
+	}
+
+	args, err := stmt.coerceSelectArgs(args)
+	if err != nil {
+		return "", err
+	}
+	query, err := stmt.expandQuery(prefix+stmt.query, args)
+	if err != nil {
+		return "", err
+	}
+	rows, err := query.QueryOn(db)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if len(columns) > 1 {
+		buf.WriteString(strings.Join(columns, "\t"))
+		buf.WriteByte('\n')
+	}
+	values := make([]sql.NullString, len(columns))
+	scanValues := make([]interface{}, len(columns))
+	for i := range values {
+		scanValues[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(scanValues...); err != nil {
+			return "", err
+		}
+		for i, value := range values {
+			if i > 0 {
+				buf.WriteByte('\t')
+			}
+			buf.WriteString(value.String)
+		}
+		buf.WriteByte('\n')
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
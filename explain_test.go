@@ -0,0 +1,110 @@
+package sqlr
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestExplain(t *testing.T) {
+	type User struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		dialect Dialect
+		analyze bool
+		query   string
+		columns []string
+		rows    [][]driver.Value
+		want    string
+		wantErr string
+	}{
+		{
+			dialect: Postgres,
+			query:   `explain select "id","name" from users where "id"=$1`,
+			columns: []string{"QUERY PLAN"},
+			rows: [][]driver.Value{
+				{"Index Scan using users_pkey on users  (cost=0.15..8.17 rows=1 width=36)"},
+				{"  Index Cond: (id = 1)"},
+			},
+			want: "Index Scan using users_pkey on users  (cost=0.15..8.17 rows=1 width=36)\n" +
+				"  Index Cond: (id = 1)",
+		},
+		{
+			dialect: Postgres,
+			analyze: true,
+			query:   `explain analyze select "id","name" from users where "id"=$1`,
+			columns: []string{"QUERY PLAN"},
+			rows:    [][]driver.Value{{"Seq Scan on users  (actual time=0.010..0.011 rows=1 loops=1)"}},
+			want:    "Seq Scan on users  (actual time=0.010..0.011 rows=1 loops=1)",
+		},
+		{
+			dialect: SQLite,
+			query:   "explain query plan select `id`,`name` from users where `id`=?",
+			columns: []string{"id", "parent", "notused", "detail"},
+			rows:    [][]driver.Value{{2, 0, 0, "SEARCH users USING INTEGER PRIMARY KEY (rowid=?)"}},
+			want:    "id\tparent\tnotused\tdetail\n2\t0\t0\tSEARCH users USING INTEGER PRIMARY KEY (rowid=?)",
+		},
+		{
+			dialect: MySQL,
+			query:   "explain select `id`,`name` from users where `id`=?",
+			columns: []string{"id", "table", "key"},
+			rows:    [][]driver.Value{{1, "users", nil}},
+			want:    "id\ttable\tkey\n1\tusers\t",
+		},
+		{
+			dialect: SQLite,
+			analyze: true,
+			wantErr: "explain analyze is not supported by the dialect",
+		},
+		{
+			dialect: MSSQL,
+			wantErr: "explain is not supported by the dialect",
+		},
+	}
+	for i, tt := range tests {
+		schema := NewSchema(WithDialect(tt.dialect))
+		stmt, err := schema.Prepare(User{}, "select {} from users where {}")
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if tt.query != "" {
+			rows := sqlmock.NewRows(tt.columns)
+			for _, row := range tt.rows {
+				rows.AddRow(row...)
+			}
+			mock.ExpectQuery(regexp.QuoteMeta(tt.query)).WithArgs(1).WillReturnRows(rows)
+		}
+		var got string
+		if tt.analyze {
+			got, err = stmt.ExplainAnalyze(db, 1)
+		} else {
+			got, err = stmt.Explain(db, 1)
+		}
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%d: want=%q, got=%v", i, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
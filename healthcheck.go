@@ -0,0 +1,152 @@
+package sqlr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// HealthcheckResult contains the outcome of each check performed by
+// Schema.Healthcheck, so that a partial failure can be reported in detail.
+type HealthcheckResult struct {
+	Ping    HealthcheckStatus // the database responds to a ping
+	Dialect HealthcheckStatus // the database server matches the schema's dialect
+	Columns HealthcheckStatus // the tables have columns for the row types' fields
+}
+
+// HealthcheckStatus is the outcome of one check performed by Schema.Healthcheck.
+// A check is skipped if it cannot be performed, and a skipped check is OK.
+type HealthcheckStatus struct {
+	OK      bool
+	Skipped bool
+	Error   error
+}
+
+// OK returns true if all of the checks are OK.
+func (r *HealthcheckResult) OK() bool {
+	return r.Ping.OK && r.Dialect.OK && r.Columns.OK
+}
+
+// Healthcheck checks that the schema can be used with the database. It is
+// intended to be called from the health endpoint of a service. The checks are:
+//
+// Ping: the database is pinged, to check connectivity. If the ping fails, the
+// remaining checks are skipped.
+//
+// Dialect: the version of the database server is queried, eg "select version()",
+// and it is checked that the server matches the dialect. The check is skipped for
+// custom dialects, and for dialects that do not know how to query the version.
+//
+// Columns: for each row type and table that the schema has prepared an insert
+// statement for, it is checked that the table has a column for each field of the
+// row type, using the schema's naming convention. Columns in the table without
+// a matching field are not reported, see VerifyColumns. The check is skipped if
+// the schema has not prepared any insert statements.
+//
+// Healthcheck returns the result of all of the checks, and the error for the
+// first check that failed.
+func (s *Schema) Healthcheck(db *sql.DB) (*HealthcheckResult, error) {
+	var result HealthcheckResult
+	if err := db.Ping(); err != nil {
+		result.Ping.Error = err
+		result.Dialect.Skipped = true
+		result.Columns.Skipped = true
+		return &result, fmt.Errorf("healthcheck ping: %v", err)
+	}
+	result.Ping.OK = true
+
+	dialect := s.dialect
+	if dialect == nil {
+		dialect = s.dialectForDB(db)
+	}
+	result.Dialect = s.checkDialect(db, dialect)
+	result.Columns = s.checkColumns(db, dialect)
+
+	if err := result.Dialect.Error; err != nil {
+		return &result, fmt.Errorf("healthcheck dialect: %v", err)
+	}
+	if err := result.Columns.Error; err != nil {
+		return &result, fmt.Errorf("healthcheck columns: %v", err)
+	}
+	return &result, nil
+}
+
+// checkDialect checks that the database server version matches the dialect.
+func (s *Schema) checkDialect(db *sql.DB, dialect Dialect) HealthcheckStatus {
+	d, ok := dialect.(interface {
+		VersionQuery() string
+		MatchVersion(version string) bool
+	})
+	if !ok || d.VersionQuery() == "" {
+		return HealthcheckStatus{OK: true, Skipped: true}
+	}
+	var version string
+	if err := db.QueryRow(d.VersionQuery()).Scan(&version); err != nil {
+		return HealthcheckStatus{Error: err}
+	}
+	if !d.MatchVersion(version) {
+		return HealthcheckStatus{Error: fmt.Errorf("dialect does not match database version %q", version)}
+	}
+	return HealthcheckStatus{OK: true}
+}
+
+// checkColumns checks that the tables of the insert statements prepared by the
+// schema have a column for each field of the statements' row types.
+func (s *Schema) checkColumns(db *sql.DB, dialect Dialect) HealthcheckStatus {
+	seen := make(map[rowTable]bool)
+	var tables rowTables
+	s.cache.mu.RLock()
+	for _, stmt := range s.cache.stmts {
+		if stmt.insertTable == "" {
+			continue
+		}
+		rt := rowTable{rowType: stmt.rowType, table: stmt.insertTable}
+		if !seen[rt] {
+			seen[rt] = true
+			tables = append(tables, rt)
+		}
+	}
+	s.cache.mu.RUnlock()
+	if len(tables) == 0 {
+		return HealthcheckStatus{OK: true, Skipped: true}
+	}
+	sort.Sort(tables)
+
+	var problems []string
+	for _, rt := range tables {
+		missingColumns, _, err := s.compareColumns(db, dialect, rt.rowType, rt.table)
+		if err != nil {
+			return HealthcheckStatus{Error: err}
+		}
+		if len(missingColumns) > 0 {
+			problems = append(problems, fmt.Sprintf("type %s does not match table %s: fields without columns: %s",
+				rt.rowType.Name(), rt.table, strings.Join(missingColumns, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return HealthcheckStatus{Error: errors.New(strings.Join(problems, "; "))}
+	}
+	return HealthcheckStatus{OK: true}
+}
+
+// rowTable is a row type and the table it is inserted into.
+type rowTable struct {
+	rowType reflect.Type
+	table   string
+}
+
+// rowTables sorts by table name, then by row type, so that problems are
+// reported in a consistent order.
+type rowTables []rowTable
+
+func (t rowTables) Len() int      { return len(t) }
+func (t rowTables) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+func (t rowTables) Less(i, j int) bool {
+	if t[i].table != t[j].table {
+		return t[i].table < t[j].table
+	}
+	return t[i].rowType.String() < t[j].rowType.String()
+}
@@ -0,0 +1,114 @@
+package sqlr
+
+import (
+	"regexp"
+	"testing"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestHealthcheck(t *testing.T) {
+	type User struct {
+		ID       int `sql:"primary key"`
+		Name     string
+		Nickname string
+	}
+	const columnsQuery = `select column_name from information_schema.columns where table_name = $1` +
+		` and table_schema = coalesce(nullif($2, ''), current_schema()) order by ordinal_position`
+
+	tests := []struct {
+		dialect     Dialect
+		insert      bool
+		version     string
+		columns     []string
+		wantDialect HealthcheckStatus
+		wantColumns HealthcheckStatus
+		wantErr     string
+	}{
+		{
+			dialect:     Postgres,
+			insert:      true,
+			version:     "PostgreSQL 15.4 on x86_64-pc-linux-gnu",
+			columns:     []string{"id", "name", "nickname", "created_at"},
+			wantDialect: HealthcheckStatus{OK: true},
+			wantColumns: HealthcheckStatus{OK: true},
+		},
+		{
+			dialect:     Postgres,
+			insert:      false,
+			version:     "PostgreSQL 15.4 on x86_64-pc-linux-gnu",
+			wantDialect: HealthcheckStatus{OK: true},
+			wantColumns: HealthcheckStatus{OK: true, Skipped: true},
+		},
+		{
+			dialect:     Postgres,
+			insert:      true,
+			version:     "8.0.34 MySQL Community Server",
+			columns:     []string{"id", "name"},
+			wantErr:     `healthcheck dialect: dialect does not match database version "8.0.34 MySQL Community Server"`,
+			wantColumns: HealthcheckStatus{},
+		},
+		{
+			dialect:     Postgres,
+			insert:      true,
+			version:     "PostgreSQL 15.4 on x86_64-pc-linux-gnu",
+			columns:     []string{"id", "name"},
+			wantErr:     "healthcheck columns: type User does not match table users: fields without columns: Nickname (nickname)",
+			wantDialect: HealthcheckStatus{OK: true},
+		},
+		{
+			dialect:     ANSISQL,
+			insert:      false,
+			wantDialect: HealthcheckStatus{OK: true, Skipped: true},
+			wantColumns: HealthcheckStatus{OK: true, Skipped: true},
+		},
+	}
+	for i, tt := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema := NewSchema(WithDialect(tt.dialect))
+		if tt.insert {
+			if _, err := schema.Prepare(User{}, "insert into users({}) values({})"); err != nil {
+				t.Fatalf("%d: %v", i, err)
+			}
+		}
+		if tt.version != "" {
+			mock.ExpectQuery(regexp.QuoteMeta("select version()")).
+				WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(tt.version))
+		}
+		if tt.columns != nil {
+			rows := sqlmock.NewRows([]string{"column_name"})
+			for _, col := range tt.columns {
+				rows.AddRow(col)
+			}
+			mock.ExpectQuery(regexp.QuoteMeta(columnsQuery)).WithArgs("users", "").WillReturnRows(rows)
+		}
+
+		result, err := schema.Healthcheck(db)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%d: unexpected error: %v", i, err)
+			}
+		} else if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%d: want=%q, got=%v", i, tt.wantErr, err)
+		}
+		if !result.Ping.OK {
+			t.Errorf("%d: want ping OK", i)
+		}
+		if got, want := result.Dialect, tt.wantDialect; got.OK != want.OK || got.Skipped != want.Skipped {
+			t.Errorf("%d: dialect: want=%+v, got=%+v", i, want, got)
+		}
+		if got, want := result.Columns, tt.wantColumns; got.OK != want.OK || got.Skipped != want.Skipped {
+			t.Errorf("%d: columns: want=%+v, got=%+v", i, want, got)
+		}
+		if got, want := result.OK(), tt.wantErr == ""; got != want {
+			t.Errorf("%d: OK: want=%v, got=%v", i, want, got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%d: %v", i, err)
+		}
+		db.Close()
+	}
+}
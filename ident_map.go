@@ -1,5 +1,10 @@
 package sqlr
 
+import (
+	"sort"
+	"strings"
+)
+
 // identifierMap is used to lookup identifiers that need to be replaced.
 // There is no mutex because once a schema has been initialized, its
 // identifier map should be immutable.
@@ -34,3 +39,22 @@ func (im *identMap) lookup(identifier string) (string, bool) {
 	}
 	return "", false
 }
+
+// key returns a string that identifies the replacements in the map,
+// including the replacements in the maps that it overrides.
+func (im *identMap) key() string {
+	replacements := make(map[string]string)
+	for m := im; m != nil; m = m.prev {
+		for identifier, replacement := range m.identifiers {
+			if _, ok := replacements[identifier]; !ok {
+				replacements[identifier] = replacement
+			}
+		}
+	}
+	pairs := make([]string, 0, len(replacements))
+	for identifier, replacement := range replacements {
+		pairs = append(pairs, identifier+"="+replacement)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\x00")
+}
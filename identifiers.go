@@ -0,0 +1,67 @@
+package sqlr
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// QuoteIdentifier quotes a table name or column name according to the schema's
+// dialect, so that it can be included safely in an SQL query. It is intended for
+// names that are only known at runtime, such as per-tenant table names. For the
+// built-in dialects, quote characters in the name are escaped so that the name
+// cannot be used for SQL injection. A name containing periods is quoted as a
+// qualified name, eg "schema"."table".
+//
+// Custom dialects that do not implement a QuoteIdentifier method use their
+// Quote method.
+func (s *Schema) QuoteIdentifier(name string) string {
+	dialect := s.getDialect()
+	if d, ok := dialect.(interface {
+		QuoteIdentifier(name string) string
+	}); ok {
+		return d.QuoteIdentifier(name)
+	}
+	return dialect.Quote(name)
+}
+
+// ExpandIdentifiers replaces each "{{.Name}}" token in the query with the
+// identifier in idents for Name, quoted using QuoteIdentifier. The resulting query
+// can be passed to Prepare, Select, Exec, etc. This provides a safe alternative to
+// building a query with table or column names that are only known at runtime:
+//  query, err := schema.ExpandIdentifiers("select {} from {{.Table}} where {}", map[string]string{
+//      "Table": tenant + "_users",
+//  })
+//
+// An error is returned if a token is malformed, or if there is no identifier
+// in idents for a token.
+func (s *Schema) ExpandIdentifiers(query string, idents map[string]string) (string, error) {
+	var buf bytes.Buffer
+	for {
+		start := strings.Index(query, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(query[start:], "}}")
+		if end < 0 {
+			return "", fmt.Errorf("missing closing braces for %q", query[start:])
+		}
+		end += start
+		token := strings.TrimSpace(query[start+2 : end])
+		if !strings.HasPrefix(token, ".") || len(token) == 1 {
+			return "", fmt.Errorf("invalid identifier token %q", query[start:end+2])
+		}
+		ident, ok := idents[token[1:]]
+		if !ok {
+			return "", fmt.Errorf("no identifier for %q", query[start:end+2])
+		}
+		if ident == "" {
+			return "", fmt.Errorf("empty identifier for %q", query[start:end+2])
+		}
+		buf.WriteString(query[:start])
+		buf.WriteString(s.QuoteIdentifier(ident))
+		query = query[end+2:]
+	}
+	buf.WriteString(query)
+	return buf.String(), nil
+}
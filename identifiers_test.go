@@ -0,0 +1,86 @@
+package sqlr
+
+import (
+	"testing"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		name    string
+		want    string
+	}{
+		{Postgres, "users", `"users"`},
+		{Postgres, "tenant1.users", `"tenant1"."users"`},
+		{Postgres, `users"; drop table users; --`, `"users""; drop table users; --"`},
+		{MySQL, "users", "`users`"},
+		{MySQL, "users`; drop table users; --", "`users``; drop table users; --`"},
+		{MSSQL, "users]; drop table users; --", "[users]]; drop table users; --]"},
+		{SQLite, "users", "`users`"},
+	}
+	for i, tt := range tests {
+		schema := NewSchema(WithDialect(tt.dialect))
+		if got, want := schema.QuoteIdentifier(tt.name), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func TestExpandIdentifiers(t *testing.T) {
+	idents := map[string]string{
+		"Table":  "tenant1_users",
+		"Column": `na"me`,
+		"Empty":  "",
+	}
+	tests := []struct {
+		query   string
+		want    string
+		wantErr string
+	}{
+		{
+			query: "select {} from {{.Table}} where {}",
+			want:  `select {} from "tenant1_users" where {}`,
+		},
+		{
+			query: "select {{ .Column }} from {{.Table}} order by {{.Column}}",
+			want:  `select "na""me" from "tenant1_users" order by "na""me"`,
+		},
+		{
+			query: "select {} from users",
+			want:  "select {} from users",
+		},
+		{
+			query:   "select {} from {{.Missing}}",
+			wantErr: `no identifier for "{{.Missing}}"`,
+		},
+		{
+			query:   "select {} from {{Table}}",
+			wantErr: `invalid identifier token "{{Table}}"`,
+		},
+		{
+			query:   "select {} from {{.Table",
+			wantErr: `missing closing braces for "{{.Table"`,
+		},
+		{
+			query:   "select {} from {{.Empty}}",
+			wantErr: `empty identifier for "{{.Empty}}"`,
+		},
+	}
+	schema := NewSchema(WithDialect(Postgres))
+	for i, tt := range tests {
+		got, err := schema.ExpandIdentifiers(tt.query, idents)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%d: want error %q, got %v", i, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %v", i, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+}
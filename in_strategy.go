@@ -0,0 +1,35 @@
+package sqlr
+
+import (
+	"errors"
+
+	"github.com/jjeffery/sqlr/private/wherein"
+)
+
+// InStrategy determines how an arg that is a slice of tuples is expanded for
+// a tuple IN clause, such as "where (a,b) in (?)". Each tuple is a slice or an
+// array of values, eg [][]int{{1, 2}, {3, 4}} or [][2]interface{}{{1, "x"}}.
+// See WithInStrategy.
+type InStrategy int
+
+// In strategies
+const (
+	InValues      InStrategy = iota // list of row values, eg "(a,b) in ((?,?),(?,?))" (the default)
+	InUnionSelect                   // union of selects, eg "(a,b) in (select ?,? union all select ?,?)"
+)
+
+// expandQuery expands the args that are slices in the query, using the
+// statement's strategy for args that are slices of tuples.
+func (stmt *Stmt) expandQuery(query string, args []interface{}) (*wherein.ExpandedQuery, error) {
+	if stmt.placeholderOffset > 0 {
+		return nil, errors.New("cannot execute a statement with a placeholder offset")
+	}
+	opts := wherein.Options{
+		Tuples:            wherein.TupleValues,
+		ColonPlaceholders: colonPlaceholders(stmt.dialect),
+	}
+	if stmt.inStrategy == InUnionSelect {
+		opts.Tuples = wherein.TupleUnionSelect
+	}
+	return wherein.ExpandQueryOptions(query, args, opts)
+}
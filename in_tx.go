@@ -0,0 +1,77 @@
+package sqlr
+
+import (
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+)
+
+// savepointCount is used to give each savepoint a unique name.
+var savepointCount uint64
+
+// InTx calls fn in a transaction. If fn returns nil the transaction is
+// committed, otherwise it is rolled back and the error is returned. The DB
+// passed to fn is the transaction, and fn should use it for all of its queries.
+//
+// If db is a *sql.DB, InTx begins a new transaction. Otherwise db is an existing
+// transaction, such as a *sql.Tx, and InTx creates a savepoint instead. The savepoint
+// is released if fn returns nil, and if fn returns an error only the changes made
+// since the savepoint are rolled back. This makes it possible to compose functions
+// that call InTx, and to recover from a failure in a nested function:
+//  err := schema.InTx(db, func(tx sqlr.DB) error {
+//      if _, err := schema.Exec(tx, &order, "insert into orders({}) values({})"); err != nil {
+//          return err
+//      }
+//      // a failure to notify the customer does not cancel the order
+//      _ = schema.InTx(tx, func(tx sqlr.DB) error {
+//          return addNotification(tx, order)
+//      })
+//      return nil
+//  })
+//
+// Savepoints are created using "savepoint", "release savepoint" and "rollback to
+// savepoint", except for MSSQL, which uses "save transaction" and "rollback transaction".
+func (s *Schema) InTx(db DB, fn func(tx DB) error) error {
+	beginner, ok := db.(interface {
+		Begin() (*sql.Tx, error)
+	})
+	if !ok {
+		return s.inSavepoint(db, fn)
+	}
+	tx, err := beginner.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// inSavepoint calls fn in a savepoint of the existing transaction.
+func (s *Schema) inSavepoint(tx DB, fn func(tx DB) error) error {
+	name := fmt.Sprintf("sqlr_savepoint_%d", atomic.AddUint64(&savepointCount, 1))
+	save, release, rollback := "savepoint "+name, "release savepoint "+name, "rollback to savepoint "+name
+	if d, ok := s.getDialect().(interface {
+		SavepointSQL(name string) (save, release, rollback string)
+	}); ok {
+		save, release, rollback = d.SavepointSQL(name)
+	}
+
+	if _, err := tx.Exec(save); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		if _, rollbackErr := tx.Exec(rollback); rollbackErr != nil {
+			return fmt.Errorf("%v (rollback to savepoint failed: %v)", err, rollbackErr)
+		}
+		return err
+	}
+	if release != "" {
+		if _, err := tx.Exec(release); err != nil {
+			return err
+		}
+	}
+	return nil
+}
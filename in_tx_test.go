@@ -0,0 +1,84 @@
+package sqlr
+
+import (
+	"errors"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestInTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+	errNested := errors.New("nested failed")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("insert into orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^savepoint sqlr_savepoint_\d+$`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("insert into order_lines").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^savepoint sqlr_savepoint_\d+$`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("insert into notifications").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^rollback to savepoint sqlr_savepoint_\d+$`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^release savepoint sqlr_savepoint_\d+$`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	var nestedErr error
+	err = schema.InTx(db, func(tx DB) error {
+		if _, err := tx.Exec("insert into orders"); err != nil {
+			return err
+		}
+		return schema.InTx(tx, func(tx DB) error {
+			if _, err := tx.Exec("insert into order_lines"); err != nil {
+				return err
+			}
+			// partial rollback: the failure is ignored and the outer savepoint is released
+			nestedErr = schema.InTx(tx, func(tx DB) error {
+				if _, err := tx.Exec("insert into notifications"); err != nil {
+					return err
+				}
+				return errNested
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nestedErr != errNested {
+		t.Errorf("want=%v, got=%v", errNested, nestedErr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInTxRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MSSQL))
+	errFailed := errors.New("failed")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^save transaction sqlr_savepoint_\d+$`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^rollback transaction sqlr_savepoint_\d+$`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = schema.InTx(db, func(tx DB) error {
+		return schema.InTx(tx, func(tx DB) error {
+			return errFailed
+		})
+	})
+	if err != errFailed {
+		t.Errorf("want=%v, got=%v", errFailed, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,107 @@
+package sqlr
+
+import (
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/jjeffery/sqlr/private/column"
+)
+
+// driverValuerType is the type of the driver.Valuer interface.
+var driverValuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// inputField contains information about a field that supplies an input
+// to a query. It is resolved when the statement is prepared, so that
+// getting the args for each execution of the statement requires as little
+// reflection as possible.
+type inputField struct {
+	index    []int        // field index path, flattened from the column index
+	indirect bool         // path passes through an embedded pointer to struct
+	zero     interface{}  // zero value of the field, used for columns where empty is NULL
+	kind     reflect.Kind // kind to convert named primitive types to, or reflect.Invalid
+	dynamic  bool         // field is an interface, so its value is converted when known
+	pointer  bool         // field is a pointer, and a nil pointer is passed as NULL
+}
+
+// newInputField returns the input field information for the column.
+func newInputField(col *column.Info, rowType reflect.Type) *inputField {
+	f := &inputField{
+		index: []int(col.Index.Clone()),
+	}
+	t := rowType
+	for _, fieldIndex := range f.index {
+		if t.Kind() == reflect.Ptr {
+			f.indirect = true
+			t = t.Elem()
+		}
+		t = t.Field(fieldIndex).Type
+	}
+
+	fieldType := col.Field.Type
+	f.zero = reflect.Zero(fieldType).Interface()
+	if fieldType.Kind() == reflect.Interface {
+		f.dynamic = true
+	} else if fieldType.Kind() == reflect.Ptr && !fieldType.Implements(driverValuerType) {
+		// eg *time.Time for a nullable timestamp
+		f.pointer = true
+	} else if fieldType.PkgPath() != "" && !fieldType.Implements(driverValuerType) {
+		// named type, which may need conversion to its primitive type
+		switch k := fieldType.Kind(); k {
+		case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f.kind = k
+		}
+	}
+	return f
+}
+
+// value returns the field value in the struct rowVal.
+func (f *inputField) value(rowVal reflect.Value) reflect.Value {
+	if f.indirect {
+		for _, i := range f.index {
+			rowVal = reflect.Indirect(rowVal).Field(i)
+		}
+		return rowVal
+	}
+	for _, i := range f.index {
+		rowVal = rowVal.Field(i)
+	}
+	return rowVal
+}
+
+// isEmpty returns true if v is the zero value for the field type,
+// or if v is a pointer to an empty string.
+func (f *inputField) isEmpty(v reflect.Value) bool {
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.String {
+		return v.Elem().Len() == 0
+	}
+	return v.Interface() == f.zero
+}
+
+// primitive returns the value of the field, converted to its underlying
+// primitive type if it is a named boolean, numeric or string type.
+// See primitiveValue.
+func (f *inputField) primitive(v reflect.Value) interface{} {
+	switch f.kind {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	}
+	if f.dynamic {
+		return primitiveValue(v.Interface())
+	}
+	if f.pointer && v.IsNil() {
+		// Pass an untyped nil, because drivers that check their own
+		// args may not convert a typed nil pointer to NULL.
+		return nil
+	}
+	return v.Interface()
+}
@@ -6,11 +6,16 @@ import (
 	"reflect"
 )
 
+// maxJSONErrorSnippet is the maximum number of bytes of JSON text that
+// will be included in an unmarshal error message when error context is enabled.
+const maxJSONErrorSnippet = 64
+
 // jsonCell is used to unmarshal JSON cells into their destination type
 type jsonCell struct {
-	colname   string
-	cellValue interface{}
-	data      []byte
+	colname      string
+	cellValue    interface{}
+	data         []byte
+	errorContext bool // include a snippet of the JSON text in error messages
 }
 
 func newJSONCell(colname string, v interface{}) *jsonCell {
@@ -39,7 +44,19 @@ func (jc *jsonCell) Unmarshal() error {
 	}
 	if err := json.Unmarshal(jc.data, jc.cellValue); err != nil {
 		// TODO(jpj): if Wrap makes it into the stdlib, use it here
+		if jc.errorContext {
+			return fmt.Errorf("cannot unmarshal JSON field %q: %v: json=%q", jc.colname, err, jc.snippet())
+		}
 		return fmt.Errorf("cannot unmarshal JSON field %q: %v", jc.colname, err)
 	}
 	return nil
 }
+
+// snippet returns the JSON text, truncated if it is too long to
+// include in an error message.
+func (jc *jsonCell) snippet() string {
+	if len(jc.data) <= maxJSONErrorSnippet {
+		return string(jc.data)
+	}
+	return string(jc.data[:maxJSONErrorSnippet]) + "..."
+}
@@ -1,6 +1,13 @@
 package sqlr
 
-import "testing"
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
 
 func TestJSONCell(t *testing.T) {
 	{
@@ -52,4 +59,87 @@ func TestJSONCell(t *testing.T) {
 			t.Errorf("want=%v, got=%v", want, got)
 		}
 	}
+	{
+		var row struct {
+			V1 int
+			V2 string
+		}
+		nc := newJSONCell("col", &row)
+		nc.errorContext = true
+		nc.data = []byte(`{"V1":1,"V2":`)
+		err := nc.Unmarshal()
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+		if got, want := err.Error(), `cannot unmarshal JSON field "col": unexpected end of JSON input: json="{\"V1\":1,\"V2\":"`; got != want {
+			t.Errorf("want=%v, got=%v", want, got)
+		}
+	}
+	{
+		var row struct {
+			V1 int
+			V2 string
+		}
+		nc := newJSONCell("col", &row)
+		nc.errorContext = true
+		nc.data = []byte(`{"V1":"` + strings.Repeat("x", 100) + `"}`)
+		err := nc.Unmarshal()
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+		if got, want := nc.snippet(), `{"V1":"`+strings.Repeat("x", 57)+"..."; got != want {
+			t.Errorf("want=%v, got=%v", want, got)
+		}
+	}
+}
+
+func TestRawMessageColumn(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Data json.RawMessage
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	tests := []struct {
+		data    string
+		want    string
+		wantErr string
+	}{
+		{
+			data: `{"a": [1, 2]}`,
+			want: `{"a": [1, 2]}`,
+		},
+		{
+			data:    `{"a":`,
+			wantErr: `cannot unmarshal JSON field "Data": unexpected end of JSON input`,
+		},
+	}
+	for i, tt := range tests {
+		mock.ExpectQuery(regexp.QuoteMeta(`select "id","data" from rows where "id"=$1`)).
+			WithArgs(i).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "data"}).AddRow(i, []byte(tt.data)))
+		var row Row
+		_, err := schema.Select(db, &row, "select {} from rows where {}", i)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%d: want=%q, got=%v", i, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := string(row.Data), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
 }
@@ -0,0 +1,269 @@
+package sqlr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jjeffery/sqlr/private/column"
+	"github.com/jjeffery/sqlr/private/scanner"
+)
+
+// SelectKeyset executes a SELECT query that returns one page of rows using
+// keyset pagination, and stores the result in rows. Keyset pagination is more
+// efficient than "offset n" for deep pages of large tables, because the database
+// can seek to the first row of the page using an index on the key columns.
+//
+// The query is modified to return the rows whose key columns are greater than
+// the after values, ordered by the key columns, and at most limit rows. The key
+// columns should identify a row uniquely, eg "created_at" and "id". Each key column
+// must be the name of a column of the row type, optionally qualified with a table
+// alias, eg "u.id", and it is quoted in the query in the same way as the columns
+// expanded from "{}". The after
+// values are nil for the first page, and for the next page are the key values
+// returned by the previous call. A limit of zero means no limit.
+//
+//  var after []interface{} // first page
+//  for {
+//      var users []*User
+//      after, err = schema.SelectKeyset(db, &users, "select {} from users where active = ?",
+//          []string{"created_at", "id"}, after, 100, true)
+//      if err != nil || after == nil {
+//          break
+//      }
+//      // ... process users
+//  }
+//
+// The query must not have an order by, group by, having or limit clause. If it
+// has a where clause, the keyset condition is added to it using "and". Key values
+// are compared using a row value comparison, eg "(created_at,id) > (?,?)", unless
+// the dialect does not support row values (eg MSSQL), in which case the equivalent
+// "or" condition is used.
+//
+// SelectKeyset returns the values of the key columns for the last row returned,
+// which are the after values for the next page. It returns nil if no rows are
+// returned.
+func (s *Schema) SelectKeyset(db DB, rows interface{}, query string, keyColumns []string, after []interface{}, limit int, args ...interface{}) ([]interface{}, error) {
+	if len(keyColumns) == 0 {
+		return nil, errors.New("expected at least one key column")
+	}
+	if len(after) > 0 && len(after) != len(keyColumns) {
+		return nil, fmt.Errorf("expected %d key values, got %d", len(keyColumns), len(after))
+	}
+	if limit < 0 {
+		return nil, errors.New("expected limit to be zero or greater")
+	}
+	rowType, err := inferRowType(rows)
+	if err != nil {
+		return nil, err
+	}
+	query, err = checkSQL(query, s.shorthandTableName(rowType))
+	if err != nil {
+		return nil, err
+	}
+
+	// the key columns are checked before they are added to the query,
+	// because they might not come from a trusted source
+	dialect := s.getDialect()
+	cols := s.columnsForType(rowType)
+	namer := s.columnNamerForQuery(query)
+	keyCols := make([]*column.Info, len(keyColumns))
+	keyNames := make([]string, len(keyColumns))
+	for i, keyColumn := range keyColumns {
+		alias, col := keysetColumn(cols, namer, keyColumn)
+		if col == nil {
+			return nil, fmt.Errorf("key column %q is not a field of the row type", keyColumn)
+		}
+		keyCols[i] = col
+		keyNames[i] = s.quoteMode.quote(dialect, namer.ColumnName(col))
+		if alias != "" {
+			keyNames[i] = alias + "." + keyNames[i]
+		}
+	}
+	query, err = keysetQuery(dialect, query, keyNames, len(after) > 0)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 {
+		if query, err = limitQuery(dialect, query, limit); err != nil {
+			return nil, err
+		}
+	}
+	stmt, err := s.Prepare(rows, query)
+	if err != nil {
+		return nil, err
+	}
+	if db, err = s.lazyDB(db); err != nil {
+		return nil, err
+	}
+
+	args = append(args[:len(args):len(args)], keysetArgs(dialect, after)...)
+	n, err := stmt.Select(db, rows, args...)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	return keysetValues(rows, keyCols), nil
+}
+
+// keysetQuery returns query with the keyset condition added to the where clause,
+// if there are after values, and an order by clause for the key columns.
+func keysetQuery(dialect Dialect, query string, keyColumns []string, hasAfter bool) (string, error) {
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	scan := scanner.New(strings.NewReader(query))
+	scan.AddKeywords("where", "order", "group", "having", "limit", "offset", "fetch", "union")
+	var depth int
+	var hasWhere bool
+	var whereEnd int // offset of the end of the "where" keyword
+	var offset int
+	for scan.Scan() {
+		tok, lit := scan.Token(), scan.Text()
+		offset += len(lit)
+		switch {
+		case tok == scanner.OP && lit == "(":
+			depth++
+		case tok == scanner.OP && lit == ")":
+			depth--
+		case tok == scanner.KEYWORD && depth == 0:
+			if keyword := strings.ToLower(lit); keyword == "where" {
+				hasWhere = true
+				whereEnd = offset
+			} else {
+				return "", fmt.Errorf("cannot use keyset pagination with %q in query", keyword)
+			}
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if hasAfter {
+		cond := keysetCondition(dialect, keyColumns)
+		if hasWhere {
+			// the existing condition is enclosed in parentheses, as it may contain "or"
+			buf.WriteString(query[:whereEnd])
+			buf.WriteString(" (")
+			buf.WriteString(strings.TrimSpace(query[whereEnd:]))
+			buf.WriteString(") and ")
+			buf.WriteString(cond)
+		} else {
+			buf.WriteString(query)
+			buf.WriteString(" where ")
+			buf.WriteString(cond)
+		}
+	} else {
+		buf.WriteString(query)
+	}
+	buf.WriteString(" order by ")
+	buf.WriteString(strings.Join(keyColumns, ","))
+	return buf.String(), nil
+}
+
+// keysetCondition returns the condition for rows whose key columns are greater
+// than the after values. For two key columns (a,b), the condition is "(a,b) > (?,?)"
+// if the dialect supports row value comparisons, otherwise "(a > ? or (a = ? and b > ?))".
+func keysetCondition(dialect Dialect, keyColumns []string) string {
+	if len(keyColumns) == 1 {
+		return keyColumns[0] + " > ?"
+	}
+	if supportsRowValues(dialect) {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(keyColumns)), ",")
+		return "(" + strings.Join(keyColumns, ",") + ") > (" + placeholders + ")"
+	}
+	var terms []string
+	for i := range keyColumns {
+		var term []string
+		for _, col := range keyColumns[:i] {
+			term = append(term, col+" = ?")
+		}
+		term = append(term, keyColumns[i]+" > ?")
+		if len(term) == 1 {
+			terms = append(terms, term[0])
+		} else {
+			terms = append(terms, "("+strings.Join(term, " and ")+")")
+		}
+	}
+	return "(" + strings.Join(terms, " or ") + ")"
+}
+
+// keysetArgs returns the args for the placeholders in the condition
+// returned by keysetCondition.
+func keysetArgs(dialect Dialect, after []interface{}) []interface{} {
+	if len(after) <= 1 || supportsRowValues(dialect) {
+		return after
+	}
+	var args []interface{}
+	for i := range after {
+		args = append(args, after[:i+1]...)
+	}
+	return args
+}
+
+func supportsRowValues(dialect Dialect) bool {
+	if d, ok := dialect.(interface {
+		SupportsRowValues() bool
+	}); ok {
+		return d.SupportsRowValues()
+	}
+	return false
+}
+
+// keysetValues returns the values of the key columns for the last row in rows.
+func keysetValues(rows interface{}, keyCols []*column.Info) []interface{} {
+	rowValue := reflect.ValueOf(rows)
+	for rowValue.Kind() == reflect.Ptr {
+		rowValue = rowValue.Elem()
+	}
+	if rowValue.Kind() == reflect.Slice {
+		rowValue = rowValue.Index(rowValue.Len() - 1)
+		for rowValue.Kind() == reflect.Ptr {
+			rowValue = rowValue.Elem()
+		}
+	}
+	values := make([]interface{}, 0, len(keyCols))
+	for _, col := range keyCols {
+		values = append(values, col.Index.ValueRO(rowValue).Interface())
+	}
+	return values
+}
+
+// keysetColumn returns the column for the key column name, which may be quoted
+// and may be qualified with a table alias, eg "u.id". It returns nil if the name
+// is not a column of the row type, or the alias is not a plain identifier.
+func keysetColumn(cols []*column.Info, namer columnNamer, keyColumn string) (alias string, col *column.Info) {
+	keyColumn = strings.TrimSpace(keyColumn)
+	if i := strings.LastIndex(keyColumn, "."); i >= 0 {
+		alias, keyColumn = keyColumn[:i], keyColumn[i+1:]
+		if !isAlias(alias) {
+			return "", nil
+		}
+	}
+	keyColumn = scanner.Unquote(strings.TrimSpace(keyColumn))
+	for _, col := range cols {
+		if col.BitGroup == "" && strings.EqualFold(namer.ColumnName(col), keyColumn) {
+			return alias, col
+		}
+	}
+	return "", nil
+}
+
+// isAlias reports whether s is a plain identifier that can be used as a table alias.
+func isAlias(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, ch := range s {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch == '_':
+		case ch >= '0' && ch <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
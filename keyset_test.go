@@ -0,0 +1,131 @@
+package sqlr
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestSelectKeyset(t *testing.T) {
+	type User struct {
+		ID      int `sql:"primary key"`
+		Name    string
+		Created int
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		dialect  Dialect
+		query    string
+		keys     []string
+		after    []interface{}
+		limit    int
+		args     []interface{}
+		wantSQL  string
+		wantArgs []driver.Value
+		rows     [][]driver.Value
+		want     []interface{}
+		wantErr  string
+	}{
+		{
+			dialect:  Postgres,
+			query:    "select {} from users where name = ? or name = ?",
+			keys:     []string{"created", "id"},
+			after:    []interface{}{100, 7},
+			limit:    2,
+			args:     []interface{}{"a", "b"},
+			wantSQL:  `select "id","name","created" from users where (name = $1 or name = $2) and ("created","id") > ($3,$4) order by "created","id" limit 2`,
+			wantArgs: []driver.Value{"a", "b", 100, 7},
+			rows:     [][]driver.Value{{8, "a", 100}, {3, "b", 101}},
+			want:     []interface{}{101, 3},
+		},
+		{
+			dialect: SQLite,
+			query:   "select {} from users",
+			keys:    []string{"id"},
+			limit:   10,
+			wantSQL: "select `id`,`name`,`created` from users order by `id` limit 10",
+			rows:    [][]driver.Value{{1, "a", 100}},
+			want:    []interface{}{1},
+		},
+		{
+			dialect:  MSSQL,
+			query:    "select {} from users",
+			keys:     []string{"u.created", "[id]"},
+			after:    []interface{}{100, 7},
+			limit:    2,
+			wantSQL:  "select top 2 [id],[name],[created] from users where (u.[created] > ? or (u.[created] = ? and [id] > ?)) order by u.[created],[id]",
+			wantArgs: []driver.Value{100, 100, 7},
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users order by name",
+			keys:    []string{"id"},
+			wantErr: `cannot use keyset pagination with "order" in query`,
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users",
+			keys:    []string{"id"},
+			after:   []interface{}{1, 2},
+			wantErr: "expected 1 key values, got 2",
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users",
+			keys:    []string{"email"},
+			wantErr: `key column "email" is not a field of the row type`,
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users",
+			keys:    []string{"id; drop table users; --"},
+			wantErr: `key column "id; drop table users; --" is not a field of the row type`,
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users",
+			keys:    []string{"(select 1).id"},
+			wantErr: `key column "(select 1).id" is not a field of the row type`,
+		},
+	}
+	for i, tt := range tests {
+		schema := NewSchema(WithDialect(tt.dialect))
+		if tt.wantSQL != "" {
+			rows := sqlmock.NewRows([]string{"id", "name", "created"})
+			for _, row := range tt.rows {
+				rows.AddRow(row...)
+			}
+			expect := mock.ExpectQuery(regexp.QuoteMeta(tt.wantSQL))
+			if len(tt.wantArgs) > 0 {
+				expect.WithArgs(tt.wantArgs...)
+			}
+			expect.WillReturnRows(rows)
+		}
+		var users []*User
+		got, err := schema.SelectKeyset(db, &users, tt.query, tt.keys, tt.after, tt.limit, tt.args...)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%d: want=%q, got=%v", i, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if want := tt.want; !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
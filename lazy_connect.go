@@ -0,0 +1,71 @@
+package sqlr
+
+import (
+	"database/sql"
+	"sync"
+)
+
+// lazyConnect opens the DB handle for a schema created with the
+// WithLazyConnect option the first time it is needed.
+type lazyConnect struct {
+	driverName string
+	dsn        string
+
+	mutex   sync.Mutex
+	db      *sql.DB
+	dialect Dialect
+}
+
+// connect opens the DB handle and checks the connection, unless this has
+// already been done successfully. If the connection fails, the handle is
+// closed and the next call tries again.
+func (lc *lazyConnect) connect(s *Schema) (*sql.DB, error) {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+	if lc.db != nil {
+		return lc.db, nil
+	}
+	db, err := sql.Open(lc.driverName, lc.dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	lc.db = db
+	lc.dialect = s.dialectForDB(db)
+	return db, nil
+}
+
+// getDialect returns the dialect for the DB handle, or nil if it
+// has not been opened.
+func (lc *lazyConnect) getDialect() Dialect {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+	return lc.dialect
+}
+
+// Connect opens the DB handle for a schema created with the WithLazyConnect
+// option, and checks that the database can be reached. Programs that want to
+// fail fast at startup can call Connect, instead of waiting for the first
+// statement to connect. Connect does nothing if the connection has already
+// been made, or if the schema was not created with WithLazyConnect.
+func (s *Schema) Connect() error {
+	_, err := s.lazyDB(nil)
+	return err
+}
+
+// lazyDB returns db, unless db is nil and the schema was created with the
+// WithLazyConnect option, in which case it returns the schema's DB handle,
+// connecting first if necessary.
+func (s *Schema) lazyDB(db DB) (DB, error) {
+	if db != nil || s.lazyConnect == nil {
+		return db, nil
+	}
+	sqlDB, err := s.lazyConnect.connect(s)
+	if err != nil {
+		return nil, err
+	}
+	return sqlDB, nil
+}
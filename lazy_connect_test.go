@@ -0,0 +1,52 @@
+package sqlr
+
+import (
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestWithLazyConnect(t *testing.T) {
+	type User struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.NewWithDSN("lazy_connect_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	schema := NewSchema(WithDialect(Postgres), WithLazyConnect("sqlmock", "lazy_connect_test"))
+	if err := schema.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectExec(regexp.QuoteMeta(`insert into users("id","name") values($1,$2)`)).
+		WithArgs(1, "Alice").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := schema.Exec(nil, &User{ID: 1, Name: "Alice"}, "insert into users({}) values({})"); err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`select "id","name" from users`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Alice"))
+	var users []User
+	if _, err := schema.Clone().Select(nil, &users, "select {} from users"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(users), 1; got != want {
+		t.Errorf("want=%d, got=%d", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	schema = NewSchema(WithLazyConnect("no_such_driver", "dsn"))
+	wantErr := `sql: unknown driver "no_such_driver" (forgotten import?)`
+	if err := schema.Connect(); err == nil || err.Error() != wantErr {
+		t.Errorf("want=%q, got=%v", wantErr, err)
+	}
+	if _, err := schema.Select(nil, &users, "select {} from users"); err == nil || err.Error() != wantErr {
+		t.Errorf("want=%q, got=%v", wantErr, err)
+	}
+}
@@ -0,0 +1,36 @@
+package sqlr
+
+import (
+	"github.com/jjeffery/sqlr/private/dialect"
+)
+
+// EscapeLike escapes the percent (%) and underscore (_) wildcard characters in s
+// with a backslash, so that s only matches itself when it is used in a LIKE
+// pattern. Backslashes in s are also escaped. It is intended for search text
+// provided by users:
+//  pattern := "%" + sqlr.EscapeLike(search) + "%"
+//  _, err := schema.Select(db, &rows, "select {} from products where name like ?", pattern)
+//
+// Backslash is the default escape character for MySQL and PostgreSQL. SQLite and
+// MS SQL Server have no default escape character, so the query must specify one,
+// eg "name like ? escape '\'". Use the schema's EscapeLike method for patterns
+// that are specific to the schema's dialect.
+func EscapeLike(s string) string {
+	return dialect.ANSI.EscapeLike(s)
+}
+
+// EscapeLike escapes the characters in s that have a special meaning in a LIKE
+// pattern for the schema's dialect, using backslash as the escape character. It is
+// the same as the EscapeLike function, except that dialects with other wildcard
+// characters have them escaped too, eg "[" for MS SQL Server.
+//
+// Custom dialects that do not implement an EscapeLike method use the EscapeLike
+// function.
+func (s *Schema) EscapeLike(str string) string {
+	if d, ok := s.getDialect().(interface {
+		EscapeLike(s string) string
+	}); ok {
+		return d.EscapeLike(str)
+	}
+	return EscapeLike(str)
+}
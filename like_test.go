@@ -0,0 +1,45 @@
+package sqlr
+
+import (
+	"testing"
+)
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", ""},
+		{"plain text", "plain text"},
+		{"100%", `100\%`},
+		{"snake_case", `snake\_case`},
+		{`C:\temp`, `C:\\temp`},
+		{`%_\%`, `\%\_\\\%`},
+		{"[abc]", "[abc]"},
+	}
+	for i, tt := range tests {
+		if got, want := EscapeLike(tt.s), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func TestSchemaEscapeLike(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		s       string
+		want    string
+	}{
+		{Postgres, "50%_[a]", `50\%\_[a]`},
+		{MySQL, "50%_[a]", `50\%\_[a]`},
+		{SQLite, "50%_[a]", `50\%\_[a]`},
+		{MSSQL, "50%_[a]", `50\%\_\[a]`},
+		{retryDialect{}, "50%_[a]", `50\%\_[a]`},
+	}
+	for i, tt := range tests {
+		schema := NewSchema(WithDialect(tt.dialect))
+		if got, want := schema.EscapeLike(tt.s), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
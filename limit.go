@@ -0,0 +1,215 @@
+package sqlr
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jjeffery/sqlr/private/scanner"
+)
+
+// limitToken is a single lexical token in a query being modified
+// to limit the number of rows returned.
+type limitToken struct {
+	tok   scanner.Token
+	lit   string
+	depth int // nesting depth of parentheses
+}
+
+// limitQuery returns query modified so that it returns at most n rows.
+// Dialects that support "select top n" (eg MSSQL) have the top clause
+// inserted after the select keyword. Other dialects have a limit clause
+// appended to the query.
+//
+// If the query already limits the number of rows returned, the lesser of
+// the two limits is used. It is an error if the existing limit is not a
+// number (eg a placeholder), because it is not possible to work out which
+// limit is lower.
+func limitQuery(dialect Dialect, query string, n int) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("invalid row limit %d", n)
+	}
+	toks, err := limitTokens(query)
+	if err != nil {
+		return "", err
+	}
+
+	if d, ok := dialect.(interface {
+		SelectTop() bool
+	}); ok && d.SelectTop() {
+		toks, err = limitTop(toks, n)
+	} else {
+		toks, err = limitLimit(toks, n)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	for _, t := range toks {
+		buf.WriteString(t.lit)
+	}
+	return buf.String(), nil
+}
+
+// defaultLimitQuery returns query modified so that it returns at most n rows,
+// but only if query is a select query that does not already limit the number of
+// rows returned. Otherwise query is returned unchanged.
+func defaultLimitQuery(dialect Dialect, query string, n int) (string, error) {
+	toks, err := limitTokens(query)
+	if err != nil {
+		return "", err
+	}
+	if i := limitNext(toks, -1); i >= len(toks) || toks[i].tok != scanner.KEYWORD || !strings.EqualFold(toks[i].lit, "select") {
+		// not a select query
+		return query, nil
+	}
+	for _, keyword := range []string{"limit", "top", "fetch"} {
+		if limitKeyword(toks, keyword) >= 0 {
+			// query already limits the number of rows
+			return query, nil
+		}
+	}
+	return limitQuery(dialect, query, n)
+}
+
+func limitTokens(query string) ([]limitToken, error) {
+	var toks []limitToken
+	var depth int
+	scan := scanner.New(strings.NewReader(query))
+	scan.AddKeywords("select", "distinct", "all", "top", "limit", "offset", "fetch", "for")
+	for scan.Scan() {
+		t := limitToken{tok: scan.Token(), lit: scan.Text(), depth: depth}
+		if t.tok == scanner.OP {
+			switch t.lit {
+			case "(":
+				depth++
+			case ")":
+				depth--
+				t.depth = depth
+			}
+		}
+		toks = append(toks, t)
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+
+	// remove any trailing white space, comments and semicolons
+	for len(toks) > 0 {
+		t := toks[len(toks)-1]
+		if t.tok != scanner.WS && t.tok != scanner.COMMENT && t.lit != ";" {
+			break
+		}
+		toks = toks[:len(toks)-1]
+	}
+	return toks, nil
+}
+
+// limitNext returns the index of the next token after index i that
+// is not white space or a comment. Returns len(toks) if there is none.
+func limitNext(toks []limitToken, i int) int {
+	for i++; i < len(toks); i++ {
+		if toks[i].tok != scanner.WS && toks[i].tok != scanner.COMMENT {
+			break
+		}
+	}
+	return i
+}
+
+// limitKeyword returns the index of the first token that is the keyword
+// at the outermost level of the query, or -1 if not found.
+func limitKeyword(toks []limitToken, keyword string) int {
+	for i, t := range toks {
+		if t.depth == 0 && t.tok == scanner.KEYWORD && strings.EqualFold(t.lit, keyword) {
+			return i
+		}
+	}
+	return -1
+}
+
+// limitReplace replaces the numeric literal at index i with n
+// if n is the lesser of the two values.
+func limitReplace(toks []limitToken, i int, n int) error {
+	if i >= len(toks) || toks[i].tok != scanner.LITERAL {
+		return fmt.Errorf("cannot apply row limit to query with non-numeric limit")
+	}
+	existing, err := strconv.Atoi(toks[i].lit)
+	if err != nil {
+		return fmt.Errorf("cannot apply row limit to query with non-numeric limit")
+	}
+	if n < existing {
+		toks[i].lit = strconv.Itoa(n)
+	}
+	return nil
+}
+
+// limitTop limits the number of rows using "select top n".
+func limitTop(toks []limitToken, n int) ([]limitToken, error) {
+	i := limitKeyword(toks, "select")
+	if i < 0 {
+		return nil, fmt.Errorf("cannot apply row limit to query that is not a select")
+	}
+	if j := limitNext(toks, i); j < len(toks) && toks[j].tok == scanner.KEYWORD {
+		switch strings.ToLower(toks[j].lit) {
+		case "distinct", "all":
+			i = j
+		}
+	}
+	if j := limitNext(toks, i); j < len(toks) && toks[j].tok == scanner.KEYWORD && strings.EqualFold(toks[j].lit, "top") {
+		k := limitNext(toks, j)
+		if k < len(toks) && toks[k].lit == "(" {
+			k = limitNext(toks, k)
+		}
+		if err := limitReplace(toks, k, n); err != nil {
+			return nil, err
+		}
+		return toks, nil
+	}
+
+	top := []limitToken{
+		{tok: scanner.WS, lit: " "},
+		{tok: scanner.KEYWORD, lit: "top"},
+		{tok: scanner.WS, lit: " "},
+		{tok: scanner.LITERAL, lit: strconv.Itoa(n)},
+	}
+	toks = append(toks[:i+1], append(top, toks[i+1:]...)...)
+	return toks, nil
+}
+
+// limitLimit limits the number of rows using "limit n".
+func limitLimit(toks []limitToken, n int) ([]limitToken, error) {
+	if i := limitKeyword(toks, "limit"); i >= 0 {
+		j := limitNext(toks, i)
+		if j < len(toks) && toks[j].tok == scanner.KEYWORD && strings.EqualFold(toks[j].lit, "all") {
+			toks[j] = limitToken{tok: scanner.LITERAL, lit: strconv.Itoa(n)}
+			return toks, nil
+		}
+		if k := limitNext(toks, j); k < len(toks) && toks[k].lit == "," {
+			// MySQL syntax "limit offset, count"
+			j = limitNext(toks, k)
+		}
+		if err := limitReplace(toks, j, n); err != nil {
+			return nil, err
+		}
+		return toks, nil
+	}
+
+	limit := []limitToken{
+		{tok: scanner.KEYWORD, lit: "limit"},
+		{tok: scanner.WS, lit: " "},
+		{tok: scanner.LITERAL, lit: strconv.Itoa(n)},
+	}
+	for _, keyword := range []string{"offset", "for"} {
+		if i := limitKeyword(toks, keyword); i >= 0 {
+			// limit clause goes before any offset or locking clause
+			limit = append(limit, limitToken{tok: scanner.WS, lit: " "})
+			toks = append(toks[:i], append(limit, toks[i:]...)...)
+			return toks, nil
+		}
+	}
+	toks = append(toks, limitToken{tok: scanner.WS, lit: " "})
+	toks = append(toks, limit...)
+	return toks, nil
+}
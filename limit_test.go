@@ -0,0 +1,288 @@
+package sqlr
+
+import (
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestLimitQuery(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+		n       int
+		want    string
+		errText string
+	}{
+		{
+			dialect: MySQL,
+			query:   "select {} from users where {}",
+			n:       10,
+			want:    "select {} from users where {} limit 10",
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users order by id;\n",
+			n:       10,
+			want:    "select {} from users order by id limit 10",
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users limit 5",
+			n:       10,
+			want:    "select {} from users limit 5",
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users limit 50",
+			n:       10,
+			want:    "select {} from users limit 10",
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users limit all",
+			n:       10,
+			want:    "select {} from users limit 10",
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users offset 20",
+			n:       10,
+			want:    "select {} from users limit 10 offset 20",
+		},
+		{
+			dialect: MySQL,
+			query:   "select {} from users limit 20, 50",
+			n:       10,
+			want:    "select {} from users limit 20, 10",
+		},
+		{
+			dialect: SQLite,
+			query:   "select {} from users where id in (select id from t limit 100)",
+			n:       10,
+			want:    "select {} from users where id in (select id from t limit 100) limit 10",
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users where {} for update",
+			n:       10,
+			want:    "select {} from users where {} limit 10 for update",
+		},
+		{
+			dialect: MySQL,
+			query:   "select {} from users limit ?",
+			n:       10,
+			errText: "cannot apply row limit to query with non-numeric limit",
+		},
+		{
+			dialect: MSSQL,
+			query:   "select {} from users where {}",
+			n:       10,
+			want:    "select top 10 {} from users where {}",
+		},
+		{
+			dialect: MSSQL,
+			query:   "select distinct {} from users",
+			n:       10,
+			want:    "select distinct top 10 {} from users",
+		},
+		{
+			dialect: MSSQL,
+			query:   "select top 5 {} from users",
+			n:       10,
+			want:    "select top 5 {} from users",
+		},
+		{
+			dialect: MSSQL,
+			query:   "select top (50) {} from users",
+			n:       10,
+			want:    "select top (10) {} from users",
+		},
+		{
+			dialect: MSSQL,
+			query:   "update users set {} where {}",
+			n:       10,
+			errText: "cannot apply row limit to query that is not a select",
+		},
+		{
+			dialect: MySQL,
+			query:   "select {} from users",
+			n:       -1,
+			errText: "invalid row limit -1",
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := limitQuery(tt.dialect, tt.query, tt.n)
+		if err != nil {
+			if got, want := err.Error(), tt.errText; got != want {
+				t.Errorf("%d: want=%q, got=%q", i, want, got)
+			}
+			continue
+		}
+		if tt.errText != "" {
+			t.Errorf("%d: want error %q, got none", i, tt.errText)
+			continue
+		}
+		if want := tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func TestDefaultLimitQuery(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+		want    string
+	}{
+		{
+			dialect: MySQL,
+			query:   "select {} from users where {}",
+			want:    "select {} from users where {} limit 100",
+		},
+		{
+			dialect: MSSQL,
+			query:   "select {} from users where {}",
+			want:    "select top 100 {} from users where {}",
+		},
+		{
+			dialect: MySQL,
+			query:   "select {} from users limit 500",
+			want:    "select {} from users limit 500",
+		},
+		{
+			dialect: MySQL,
+			query:   "select {} from users limit ? offset ?",
+			want:    "select {} from users limit ? offset ?",
+		},
+		{
+			dialect: MSSQL,
+			query:   "select top 500 {} from users",
+			want:    "select top 500 {} from users",
+		},
+		{
+			dialect: MSSQL,
+			query:   "select {} from users order by id offset 0 rows fetch next 500 rows only",
+			want:    "select {} from users order by id offset 0 rows fetch next 500 rows only",
+		},
+		{
+			dialect: Postgres,
+			query:   "select {} from users where id in (select id from t limit 5)",
+			want:    "select {} from users where id in (select id from t limit 5) limit 100",
+		},
+		{
+			dialect: MySQL,
+			query:   "update users set {} where {}",
+			want:    "update users set {} where {}",
+		},
+		{
+			dialect: MSSQL,
+			query:   "insert into users({}) select {} from other_users",
+			want:    "insert into users({}) select {} from other_users",
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := defaultLimitQuery(tt.dialect, tt.query, 100)
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		if want := tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func TestWithDefaultLimit(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	schema := NewSchema(WithDialect(MySQL), WithDefaultLimit(1000))
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: "select {} from users",
+			want:  "select `id`,`name` from users limit 1000",
+		},
+		{
+			query: "select {} from users limit 10",
+			want:  "select `id`,`name` from users limit 10",
+		},
+		{
+			query: "update users",
+			want:  "update users set `name`=? where `id`=?",
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := schema.Prepare(Row{}, tt.query)
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		if got := stmt.String(); got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+
+	// clone can remove the default limit
+	stmt, err := schema.Clone(WithDefaultLimit(0)).Prepare(Row{}, "select {} from users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), "select `id`,`name` from users"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
+
+func TestSelectTop(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL))
+
+	mock.ExpectQuery("select `id`,`name` from users where name like \\? limit 2").
+		WithArgs("A%").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(1, "Alice").
+			AddRow(2, "Andrew"))
+
+	mock.ExpectQuery("select `id`,`name` from users where name like \\? limit 3").
+		WithArgs("B%").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(3, "Bob"))
+
+	var rows []Row
+	n, err := schema.SelectTop(db, &rows, 2, "select {} from users where name like ?", "A%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := n, 2; got != want {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+	n, err = schema.SelectTop(db, &rows, 3, "select {} from users where name like ?", "B%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := n, 1; got != want {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+
+	// only the statement without the limit is cached
+	if got, want := len(schema.cache.stmts), 1; got != want {
+		t.Errorf("want=%d cached statements, got=%d", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,63 @@
+package sqlr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// nameMarkerRE matches a line that marks the start of a named query.
+var nameMarkerRE = regexp.MustCompile(`^\s*--\s*name\s*:\s*(\S+)\s*$`)
+
+// ParseNamedQueries reads SQL text containing one or more named queries.
+// Each query is preceded by a comment line that gives the name of the query:
+//  -- name: get-user
+//  select {} from users where {}
+//
+//  -- name: list-users
+//  select {} from users order by family_name, given_name
+// The map returned contains the text of each query keyed by its name. It is
+// an error if a name occurs more than once, or if there is any SQL before the
+// first name marker.
+func ParseNamedQueries(r io.Reader) (map[string]string, error) {
+	queries := make(map[string]string)
+	var name string
+	var lines []string
+
+	flush := func() {
+		if name != "" {
+			queries[name] = strings.TrimSpace(strings.Join(lines, "\n"))
+		}
+		lines = lines[:0]
+	}
+
+	scan := bufio.NewScanner(r)
+	for lineNum := 1; scan.Scan(); lineNum++ {
+		line := scan.Text()
+		if match := nameMarkerRE.FindStringSubmatch(line); match != nil {
+			flush()
+			name = match[1]
+			if _, ok := queries[name]; ok {
+				return nil, fmt.Errorf("duplicate query name=%q at line %d", name, lineNum)
+			}
+			// reserve the name so that duplicates are detected
+			queries[name] = ""
+			continue
+		}
+		if name == "" {
+			trimmed := strings.TrimSpace(line)
+			if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				return nil, fmt.Errorf("missing query name before line %d", lineNum)
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return queries, nil
+}
@@ -0,0 +1,60 @@
+package sqlr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseNamedQueries(t *testing.T) {
+	tests := []struct {
+		text    string
+		queries map[string]string
+		errText string
+	}{
+		{
+			text: `
+-- queries for the users table
+
+-- name: get-user
+select {}
+from users
+where {}
+
+--name:list-users
+select {} from users
+-- order by name
+order by family_name, given_name
+`,
+			queries: map[string]string{
+				"get-user":   "select {}\nfrom users\nwhere {}",
+				"list-users": "select {} from users\n-- order by name\norder by family_name, given_name",
+			},
+		},
+		{
+			text:    "-- name: q1\nselect {} from t1\n-- name: q1\nselect {} from t2\n",
+			errText: `duplicate query name="q1" at line 3`,
+		},
+		{
+			text:    "select {} from t1\n-- name: q1\nselect {} from t2\n",
+			errText: "missing query name before line 1",
+		},
+	}
+
+	for i, tt := range tests {
+		queries, err := ParseNamedQueries(strings.NewReader(tt.text))
+		if err != nil {
+			if got, want := err.Error(), tt.errText; got != want {
+				t.Errorf("%d: want=%q, got=%q", i, want, got)
+			}
+			continue
+		}
+		if tt.errText != "" {
+			t.Errorf("%d: want error %q, got none", i, tt.errText)
+			continue
+		}
+		if got, want := queries, tt.queries; !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
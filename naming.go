@@ -1,11 +1,18 @@
 package sqlr
 
 import (
+	"strings"
+
 	"github.com/jjeffery/sqlr/private/naming"
+	"github.com/jjeffery/sqlr/private/scanner"
 )
 
 // The NamingConvention interface provides methods that are used to
 // infer a database column name from its associated Go struct field.
+//
+// A schema caches the column names that it obtains from its naming convention,
+// and never calls the naming convention concurrently, so implementations do not
+// need to be safe for concurrent use.
 type NamingConvention interface {
 	// Convert converts a Go struct field name according to the naming convention.
 	Convert(fieldName string) string
@@ -17,7 +24,8 @@ type NamingConvention interface {
 }
 
 // Pre-defined naming conventions. If a naming convention is not specified
-// for a schema, it defaults to snake_case.
+// for a schema, it defaults to snake_case. The pre-defined naming conventions
+// have no state, and are safe for concurrent use.
 var (
 	SnakeCase NamingConvention // eg "FieldName" -> "field_name"
 	SameCase  NamingConvention // eg "FieldName" -> "FieldName"
@@ -35,3 +43,34 @@ func init() {
 	SameCase = naming.SameCase
 	LowerCase = naming.LowerCase
 }
+
+// statementTable returns the name of the table that a query inserts into,
+// updates, deletes from or selects from, so that the columns can be named
+// using the table's naming convention. Returns an empty string if the table
+// cannot be determined. For a query with joins, the first table is returned.
+func statementTable(query string) string {
+	scan := scanner.New(strings.NewReader(query))
+	scan.IgnoreWhiteSpace = true
+	scan.AddKeywords("insert", "into", "update", "delete", "from", "select")
+	var found bool
+	for scan.Scan() {
+		tok, lit := scan.Token(), scan.Text()
+		switch {
+		case tok == scanner.KEYWORD:
+			switch strings.ToLower(lit) {
+			case "into", "update", "from":
+				found = true
+			}
+		case found && tok == scanner.IDENT:
+			table := scanner.Unquote(lit)
+			// qualified table name, eg "schema.table"
+			for scan.Scan() && scan.Token() == scanner.OP && scan.Text() == "." && scan.Scan() {
+				table += "." + scanner.Unquote(scan.Text())
+			}
+			return table
+		default:
+			found = false
+		}
+	}
+	return ""
+}
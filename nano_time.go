@@ -0,0 +1,81 @@
+package sqlr
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/jjeffery/sqlr/private/column"
+)
+
+// isNanoTime returns true if the column is a time.Time (or *time.Time) field
+// tagged with "precision:nano", which is stored as an integer number of
+// nanoseconds since the Unix epoch.
+func isNanoTime(col *column.Info) bool {
+	if col.Tag.Precision != "nano" {
+		return false
+	}
+	fieldType := col.Field.Type
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType == timeType
+}
+
+// nanoTimeValue returns the value of a time field as Unix nanoseconds, or nil
+// for a nil pointer. The zero time is stored as zero, as the zero time cannot
+// be represented in an int64.
+func nanoTimeValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	t := v.Interface().(time.Time)
+	if t.IsZero() {
+		return int64(0)
+	}
+	return t.UnixNano()
+}
+
+// nanoTimeArg converts an arg compared with a nanosecond time column. Args
+// that are not times are left unchanged.
+func nanoTimeArg(arg interface{}) interface{} {
+	switch t := arg.(type) {
+	case time.Time:
+		return nanoTimeValue(reflect.ValueOf(t))
+	case *time.Time:
+		return nanoTimeValue(reflect.ValueOf(t))
+	}
+	return arg
+}
+
+// nanoTimeCell scans an integer number of Unix nanoseconds into a time field.
+type nanoTimeCell struct {
+	colname   string
+	cellValue reflect.Value
+}
+
+func (nc *nanoTimeCell) Scan(v interface{}) error {
+	var nullable sql.NullInt64
+	if err := nullable.Scan(v); err != nil {
+		return fmt.Errorf("cannot scan column %q: %v", nc.colname, err)
+	}
+	cellValue := nc.cellValue
+	if cellValue.Kind() == reflect.Ptr {
+		if !nullable.Valid {
+			cellValue.Set(reflect.Zero(cellValue.Type()))
+			return nil
+		}
+		cellValue.Set(reflect.New(timeType))
+		cellValue = cellValue.Elem()
+	}
+	if !nullable.Valid || nullable.Int64 == 0 {
+		cellValue.Set(timeZero)
+		return nil
+	}
+	cellValue.Set(reflect.ValueOf(time.Unix(0, nullable.Int64).UTC()))
+	return nil
+}
@@ -0,0 +1,63 @@
+package sqlr
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestNanoTime(t *testing.T) {
+	type Event struct {
+		ID        int        `sql:"primary key"`
+		At        time.Time  `sql:"precision:nano"`
+		Optional  *time.Time `sql:"precision:nano"`
+		CreatedAt time.Time
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL))
+
+	at := time.Date(2018, 3, 1, 10, 11, 12, 123456789, time.UTC)
+	createdAt := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta("insert into events(`id`,`at`,`optional`,`created_at`) values(?,?,?,?)")).
+		WithArgs(1, at.UnixNano(), nil, createdAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	row := Event{ID: 1, At: at, CreatedAt: createdAt}
+	if _, err := schema.Exec(db, &row, "insert into events({}) values({})"); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`at`,`optional`,`created_at` from events where at > ?")).
+		WithArgs(createdAt.UnixNano()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "at", "optional", "created_at"}).
+			AddRow(1, at.UnixNano(), at.UnixNano(), createdAt).
+			AddRow(2, 0, nil, createdAt))
+	var rows []Event
+	if _, err := schema.Select(db, &rows, "select {} from events where at > ?", createdAt); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(rows), 2; got != want {
+		t.Fatalf("want=%d, got=%d", want, got)
+	}
+	if !rows[0].At.Equal(at) {
+		t.Errorf("want=%v, got=%v", at, rows[0].At)
+	}
+	if rows[0].Optional == nil || !rows[0].Optional.Equal(at) {
+		t.Errorf("want=%v, got=%v", at, rows[0].Optional)
+	}
+	if !rows[1].At.IsZero() {
+		t.Errorf("want zero time, got=%v", rows[1].At)
+	}
+	if rows[1].Optional != nil {
+		t.Errorf("want nil, got=%v", rows[1].Optional)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,32 @@
+package sqlr
+
+import (
+	"context"
+	"time"
+)
+
+// NotifyDialect is implemented by dialects that can notify a program when
+// the rows in a table change, such as Postgres using LISTEN/NOTIFY.
+type NotifyDialect interface {
+	Dialect
+
+	// SupportsNotify returns true if the dialect supports table
+	// change notifications.
+	SupportsNotify() bool
+}
+
+// A NotificationListener listens for notifications on the named channel, and
+// sends the payload of each notification to the returned Go channel. The listener
+// stops listening and closes the Go channel when ctx is done.
+//
+// Receiving notifications requires support from the database driver, which is
+// not available through the "database/sql" package. For the lib/pq driver, a
+// listener can be implemented using pq.NewListener.
+type NotificationListener func(ctx context.Context, channel string) (<-chan string, error)
+
+// ChangeEvent describes a change to a row in a table watched by WatchTable.
+type ChangeEvent struct {
+	Operation string    `json:"operation"` // "INSERT", "UPDATE" or "DELETE"
+	RowID     string    `json:"row_id"`    // primary key of the row, empty if not known
+	Timestamp time.Time `json:"timestamp"` // time of the change in the database
+}
@@ -0,0 +1,11 @@
+// Package oteltrace creates OpenTelemetry spans for the statements executed
+// by a schema. It is a separate package so that programs that do not use
+// OpenTelemetry do not depend on it:
+//  schema := sqlr.NewSchema(
+//      sqlr.WithDialect(sqlr.Postgres),
+//      oteltrace.WithOTelTracer(otel.GetTracerProvider()),
+//  )
+//
+// The OpenTelemetry packages require Go 1.21 or later, and this package
+// is empty for earlier versions.
+package oteltrace
@@ -0,0 +1,64 @@
+//go:build go1.21
+// +build go1.21
+
+package oteltrace
+
+import (
+	"context"
+
+	"github.com/jjeffery/sqlr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the tracer used by this package.
+const instrumentationName = "github.com/jjeffery/sqlr"
+
+// WithOTelTracer creates a schema option that starts a span with a tracer from
+// tp for each execution of a statement. The span is named "sqlr.select",
+// "sqlr.insert", "sqlr.update" or "sqlr.delete", and has the attributes
+// "db.type", "db.statement" and "db.rows_affected". If the execution failed,
+// the error is recorded and the span status is set to error.
+//
+// Statements are executed without a context, so each span is the root of
+// its own trace. Tracing is disabled if tp is nil.
+func WithOTelTracer(tp trace.TracerProvider) sqlr.SchemaOption {
+	if tp == nil {
+		return sqlr.WithQueryObserver(nil)
+	}
+	tracer := tp.Tracer(instrumentationName)
+	return sqlr.WithQueryObserver(func(event *sqlr.QueryEvent) {
+		_, span := tracer.Start(context.Background(), spanName(event),
+			trace.WithTimestamp(event.Start),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.type", dbType(event)),
+				attribute.String("db.statement", event.Query),
+				attribute.Int("db.rows_affected", event.Rows),
+			),
+		)
+		if event.Err != nil {
+			span.RecordError(event.Err)
+			span.SetStatus(codes.Error, event.Err.Error())
+		}
+		span.End(trace.WithTimestamp(event.Start.Add(event.Duration)))
+	})
+}
+
+// spanName returns the name of the span for the event.
+func spanName(event *sqlr.QueryEvent) string {
+	if event.Op == "" {
+		return "sqlr.query"
+	}
+	return "sqlr." + event.Op
+}
+
+// dbType returns the value of the "db.type" attribute for the event, which
+// is "sql" for a custom dialect.
+func dbType(event *sqlr.QueryEvent) string {
+	if event.Dialect == "" {
+		return "sql"
+	}
+	return event.Dialect
+}
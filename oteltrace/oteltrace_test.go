@@ -0,0 +1,96 @@
+//go:build go1.21
+// +build go1.21
+
+package oteltrace
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/jjeffery/sqlr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestWithOTelTracer(t *testing.T) {
+	type User struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	schema := sqlr.NewSchema(sqlr.WithDialect(sqlr.Postgres), WithOTelTracer(tp))
+
+	mock.ExpectExec(regexp.QuoteMeta(`update users set "name"=$1 where "id"=$2`)).
+		WithArgs("Alice", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`select "id","name" from users`)).
+		WillReturnError(errors.New("connection lost"))
+
+	if _, err := schema.Exec(db, &User{ID: 1, Name: "Alice"}, "update users set {} where {}"); err != nil {
+		t.Fatal(err)
+	}
+	var users []User
+	if _, err := schema.Select(db, &users, "select {} from users"); err == nil {
+		t.Fatal("want error, got nil")
+	}
+
+	spans := recorder.Ended()
+	if got, want := len(spans), 2; got != want {
+		t.Fatalf("want=%d, got=%d", want, got)
+	}
+	tests := []struct {
+		name  string
+		attrs []attribute.KeyValue
+		code  codes.Code
+	}{
+		{
+			name: "sqlr.update",
+			attrs: []attribute.KeyValue{
+				attribute.String("db.type", "postgres"),
+				attribute.String("db.statement", `update users set "name"=$1 where "id"=$2`),
+				attribute.Int("db.rows_affected", 1),
+			},
+			code: codes.Unset,
+		},
+		{
+			name: "sqlr.select",
+			attrs: []attribute.KeyValue{
+				attribute.String("db.type", "postgres"),
+				attribute.String("db.statement", `select "id","name" from users`),
+				attribute.Int("db.rows_affected", 0),
+			},
+			code: codes.Error,
+		},
+	}
+	for i, tt := range tests {
+		span := spans[i]
+		if got, want := span.Name(), tt.name; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		attrs := make(map[attribute.Key]attribute.Value)
+		for _, kv := range span.Attributes() {
+			attrs[kv.Key] = kv.Value
+		}
+		for _, want := range tt.attrs {
+			if got := attrs[want.Key]; got != want.Value {
+				t.Errorf("%d: %s: want=%v, got=%v", i, want.Key, want.Value.Emit(), got.Emit())
+			}
+		}
+		if got, want := span.Status().Code, tt.code; got != want {
+			t.Errorf("%d: status: want=%v, got=%v", i, want, got)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
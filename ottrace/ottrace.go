@@ -0,0 +1,65 @@
+// Package ottrace creates OpenTracing spans for the statements executed
+// by a schema. It is a separate package so that programs that do not use
+// OpenTracing do not depend on it:
+//  schema := sqlr.NewSchema(
+//      sqlr.WithDialect(sqlr.Postgres),
+//      ottrace.WithTracer(opentracing.GlobalTracer()),
+//  )
+//
+// OpenTracing is no longer developed, and new programs should consider
+// the oteltrace package, which uses OpenTelemetry.
+package ottrace
+
+import (
+	"github.com/jjeffery/sqlr"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// WithTracer creates a schema option that starts a span with tracer for each
+// execution of a statement. The span is named "sqlr.select", "sqlr.insert",
+// "sqlr.update" or "sqlr.delete", and has the tags "db.type", "db.statement"
+// and "db.rows_affected". If the execution failed, the span has the "error"
+// tag and the error is logged.
+//
+// Statements are executed without a context, so each span is the root of
+// its own trace. Tracing is disabled if tracer is nil.
+func WithTracer(tracer opentracing.Tracer) sqlr.SchemaOption {
+	if tracer == nil {
+		return sqlr.WithQueryObserver(nil)
+	}
+	return sqlr.WithQueryObserver(func(event *sqlr.QueryEvent) {
+		span := tracer.StartSpan(spanName(event),
+			opentracing.StartTime(event.Start),
+			ext.SpanKindRPCClient,
+		)
+		ext.DBType.Set(span, dbType(event))
+		ext.DBStatement.Set(span, event.Query)
+		span.SetTag("db.rows_affected", event.Rows)
+		if event.Err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(log.Error(event.Err))
+		}
+		span.FinishWithOptions(opentracing.FinishOptions{
+			FinishTime: event.Start.Add(event.Duration),
+		})
+	})
+}
+
+// spanName returns the name of the span for the event.
+func spanName(event *sqlr.QueryEvent) string {
+	if event.Op == "" {
+		return "sqlr.query"
+	}
+	return "sqlr." + event.Op
+}
+
+// dbType returns the value of the "db.type" tag for the event, which is
+// "sql" for a custom dialect.
+func dbType(event *sqlr.QueryEvent) string {
+	if event.Dialect == "" {
+		return "sql"
+	}
+	return event.Dialect
+}
@@ -0,0 +1,87 @@
+package ottrace
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/jjeffery/sqlr"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestWithTracer(t *testing.T) {
+	type User struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	tracer := mocktracer.New()
+	schema := sqlr.NewSchema(sqlr.WithDialect(sqlr.Postgres), WithTracer(tracer))
+
+	mock.ExpectExec(regexp.QuoteMeta(`update users set "name"=$1 where "id"=$2`)).
+		WithArgs("Alice", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`select "id","name" from users`)).
+		WillReturnError(errors.New("connection lost"))
+
+	if _, err := schema.Exec(db, &User{ID: 1, Name: "Alice"}, "update users set {} where {}"); err != nil {
+		t.Fatal(err)
+	}
+	var users []User
+	if _, err := schema.Select(db, &users, "select {} from users"); err == nil {
+		t.Fatal("want error, got nil")
+	}
+
+	spans := tracer.FinishedSpans()
+	if got, want := len(spans), 2; got != want {
+		t.Fatalf("want=%d, got=%d", want, got)
+	}
+	tests := []struct {
+		name    string
+		tags    map[string]interface{}
+		errored bool
+	}{
+		{
+			name: "sqlr.update",
+			tags: map[string]interface{}{
+				"db.type":          "postgres",
+				"db.statement":     `update users set "name"=$1 where "id"=$2`,
+				"db.rows_affected": 1,
+			},
+		},
+		{
+			name: "sqlr.select",
+			tags: map[string]interface{}{
+				"db.type":          "postgres",
+				"db.statement":     `select "id","name" from users`,
+				"db.rows_affected": 0,
+			},
+			errored: true,
+		},
+	}
+	for i, tt := range tests {
+		span := spans[i]
+		if got, want := span.OperationName, tt.name; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		for key, want := range tt.tags {
+			if got := span.Tag(key); got != want {
+				t.Errorf("%d: %s: want=%v, got=%v", i, key, want, got)
+			}
+		}
+		if got, want := span.Tag("error") == true, tt.errored; got != want {
+			t.Errorf("%d: error: want=%v, got=%v", i, want, got)
+		}
+		if got, want := len(span.Logs()) > 0, tt.errored; got != want {
+			t.Errorf("%d: logs: want=%v, got=%v", i, want, got)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
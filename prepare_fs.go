@@ -0,0 +1,41 @@
+//go:build go1.16
+// +build go1.16
+
+package sqlr
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+)
+
+// PrepareFS creates a prepared statement from the SQL query contained in
+// the file name in the file system fsys. The file system is commonly an
+// embed.FS, which allows SQL queries to be kept in .sql files rather than
+// in Go string literals.
+func (s *Schema) PrepareFS(row interface{}, fsys fs.FS, name string) (*Stmt, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Prepare(row, string(data))
+}
+
+// PrepareNamedFS creates a prepared statement from the named query in the
+// file name in the file system fsys. The file contains one or more named
+// queries in the format described in ParseNamedQueries.
+func (s *Schema) PrepareNamedFS(row interface{}, fsys fs.FS, name string, queryName string) (*Stmt, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	queries, err := ParseNamedQueries(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %v", name, err)
+	}
+	query, ok := queries[queryName]
+	if !ok {
+		return nil, fmt.Errorf("cannot find query name=%q in %s", queryName, name)
+	}
+	return s.Prepare(row, query)
+}
@@ -0,0 +1,53 @@
+//go:build go1.16
+// +build go1.16
+
+package sqlr
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestPrepareFS(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	fsys := fstest.MapFS{
+		"get.sql": &fstest.MapFile{
+			Data: []byte("select {}\nfrom rows\nwhere {}\n"),
+		},
+		"queries.sql": &fstest.MapFile{
+			Data: []byte("-- name: get\nselect {} from rows where {}\n\n-- name: update\nupdate rows set {} where {}\n"),
+		},
+	}
+	schema := NewSchema(WithDialect(Postgres))
+
+	stmt, err := schema.PrepareFS(Row{}, fsys, "get.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), `select "id","name" from rows where "id"=$1`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	stmt, err = schema.PrepareNamedFS(Row{}, fsys, "queries.sql", "update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), `update rows set "name"=$1 where "id"=$2`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	_, err = schema.PrepareNamedFS(Row{}, fsys, "queries.sql", "delete")
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+	if got, want := err.Error(), `cannot find query name="delete" in queries.sql`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	if _, err = schema.PrepareFS(Row{}, fsys, "missing.sql"); err == nil {
+		t.Error("expected error, got none")
+	}
+}
@@ -0,0 +1,24 @@
+package sqlr
+
+import "strings"
+
+// PrepareJoined creates a prepared statement from a query made by joining the
+// fragments with sep. Fragments that are empty or all white space are skipped,
+// so that a query can be built from optional clauses:
+//  var where string
+//  if activeOnly {
+//      where = "where active = ?"
+//  }
+//  stmt, err := schema.PrepareJoined(row, " ", "select {} from users", where, "order by id")
+//
+// Apart from joining the fragments, PrepareJoined is the same as Prepare, and the
+// statement is cached in the same way as a statement for the joined query.
+func (s *Schema) PrepareJoined(row interface{}, sep string, fragments ...string) (*Stmt, error) {
+	parts := make([]string, 0, len(fragments))
+	for _, fragment := range fragments {
+		if strings.TrimSpace(fragment) != "" {
+			parts = append(parts, fragment)
+		}
+	}
+	return s.Prepare(row, strings.Join(parts, sep))
+}
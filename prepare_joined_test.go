@@ -0,0 +1,42 @@
+package sqlr
+
+import "testing"
+
+func TestPrepareJoined(t *testing.T) {
+	type User struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	schema := NewSchema(WithDialect(Postgres))
+	tests := []struct {
+		sep       string
+		fragments []string
+		want      string
+	}{
+		{
+			sep:       " ",
+			fragments: []string{"select {} from users", "where active = ?"},
+			want:      `select "id","name" from users where active = $1`,
+		},
+		{
+			sep:       "\n",
+			fragments: []string{"select {} from users", "", "  ", "order by id"},
+			want:      `select "id","name" from users order by id`,
+		},
+		{
+			sep:       " and ",
+			fragments: []string{"delete from users where {}", "name = ?"},
+			want:      `delete from users where "id"=$1 and name = $2`,
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := schema.PrepareJoined(User{}, tt.sep, tt.fragments...)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := stmt.String(), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
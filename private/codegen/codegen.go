@@ -59,6 +59,8 @@ type QueryType struct {
 	QuotedInsert    string
 	QuotedUpdate    string
 	QuotedDelete    string
+	QuotedCount     string
+	QuotedExists    string
 	Singular        string // Describes one instance in error msg
 	Plural          string // Describes multiple instances in error msg
 	DBField         string // Name of the field of type sqlr.DB (probably db)
@@ -73,6 +75,8 @@ type QueryType struct {
 		Update    string
 		Delete    string
 		Upsert    string
+		Count     string
+		Exists    string
 	}
 }
 
@@ -84,6 +88,7 @@ type RowType struct {
 	IDParams  string   // for function parameters specifying primary key ID field(s)
 	IDKeyvals string   // for log messages specifying primary key ID field(s)
 	LogProps  []string // for error messages
+	AutoIncr  string   // name of the auto-increment (identity) field, if any
 }
 
 // Parse the file, and any other related files and build the
@@ -247,7 +252,7 @@ func newQueryType(file *ast.File, ir *importResolver, typeSpec *ast.TypeSpec, st
 			// without knowing the primary key we can only do select and selectRow
 			methods = "select,selectRow"
 		} else {
-			// if not specified, do all
+			// if not specified, do all except count and exists, which are opt-in
 			methods = "get,select,selectRow,insert,update,delete,upsert"
 		}
 	}
@@ -264,6 +269,8 @@ func newQueryType(file *ast.File, ir *importResolver, typeSpec *ast.TypeSpec, st
 		QuotedInsert:    quotedString(fmt.Sprintf(`insert into %s({}) values({})`, tableName)),
 		QuotedUpdate:    quotedString(fmt.Sprintf("update %s set {} where {}", tableName)),
 		QuotedDelete:    quotedString(fmt.Sprintf("delete from %s where {}", tableName)),
+		QuotedCount:     quotedString(fmt.Sprintf("select count(*) from %s", tableName)),
+		QuotedExists:    quotedString(fmt.Sprintf("select 1 from %s", tableName)),
 		Singular:        singular,
 		Plural:          plural,
 		DBField:         dbField.Names[0].Name,
@@ -308,6 +315,10 @@ func newQueryType(file *ast.File, ir *importResolver, typeSpec *ast.TypeSpec, st
 				return nil, err
 			}
 			queryType.Method.Delete = method
+		case "count", "countrows":
+			queryType.Method.Count = method
+		case "exists", "existsrow":
+			queryType.Method.Exists = method
 		default:
 			return nil, errors.New("unknown method").With(
 				"method", method,
@@ -387,6 +398,7 @@ func newRowType(file *ast.File, ir *importResolver, typeExpr ast.Expr) (*RowType
 	var pkKeyvals []string
 	var pkArgs []string
 	var kvArgs []string
+	var autoIncr string
 
 	for _, field := range structType.Fields.List {
 		var tagInfo column.TagInfo
@@ -413,6 +425,11 @@ func newRowType(file *ast.File, ir *importResolver, typeExpr ast.Expr) (*RowType
 				kvArgs = append(kvArgs, ident.Name)
 			}
 		}
+		if tagInfo.AutoIncrement && autoIncr == "" && len(field.Names) > 0 {
+			// the identity column is excluded from the insert column list
+			// by "{}", and is set from the value assigned by the database
+			autoIncr = field.Names[0].Name
+		}
 	}
 
 	rowType := &RowType{
@@ -421,6 +438,7 @@ func newRowType(file *ast.File, ir *importResolver, typeExpr ast.Expr) (*RowType
 		IDArgs:    strings.Join(pkArgs, ", "),
 		IDKeyvals: strings.Join(pkKeyvals, ", ") + ",",
 		LogProps:  kvArgs,
+		AutoIncr:  autoIncr,
 	}
 
 	return rowType, nil
@@ -66,3 +66,25 @@ func TestParse(t *testing.T) {
 		}()
 	}
 }
+
+func TestParseAutoIncr(t *testing.T) {
+	model, err := Parse(filepath.Join("testdata", "test4.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(model.QueryTypes), 1; got != want {
+		t.Fatalf("want=%d, got=%d", want, got)
+	}
+	queryType := model.QueryTypes[0]
+	if got, want := queryType.RowType.AutoIncr, "ID"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	var buf bytes.Buffer
+	if err := DefaultTemplate.Execute(&buf, model); err != nil {
+		t.Fatal(err)
+	}
+	if want := "The ID field is not inserted"; !strings.Contains(buf.String(), want) {
+		t.Errorf("want generated insert to contain %q", want)
+	}
+}
@@ -63,6 +63,9 @@ func ({{.ReceiverIdent}} *{{.TypeName}}) {{.Method.SelectRow}}(query string, arg
 {{end -}}
 {{- if .Method.Insert}}
 // {{.Method.Insert}} inserts a {{.Singular}} row.
+{{- if .RowType.AutoIncr}} The {{.RowType.AutoIncr}} field is not inserted: it is
+// set to the identity value assigned by the database.
+{{- end}}
 func ({{.ReceiverIdent}} *{{.TypeName}}) {{.Method.Insert}}(row *{{.RowType.Name}}) error {
 	_, err := {{.ReceiverIdent}}.{{.SchemaField}}.Exec({{.ReceiverIdent}}.{{.DBField}}, row, {{.QuotedInsert}})
 	if err != nil {
@@ -120,4 +123,44 @@ func ({{.ReceiverIdent}} *{{.TypeName}}) {{.Method.Delete}}(row *{{.RowType.Name
 	return n, nil
 }
 {{end -}}
+{{- if .Method.Count}}
+// {{.Method.Count}} returns the number of {{.Plural}} that match the where clause.
+// If where is blank, all {{.Plural}} are counted.
+func ({{.ReceiverIdent}} *{{.TypeName}}) {{.Method.Count}}(where string, args ...interface{}) (int, error) {
+	query := {{.QuotedCount}}
+	if where != "" {
+		query += " where " + where
+	}
+	var count int
+	_, err := {{.ReceiverIdent}}.{{.SchemaField}}.SelectScalar({{.ReceiverIdent}}.{{.DBField}}, (*{{.RowType.Name}})(nil), &count, query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot count {{.Plural}}").With(
+			"where", where,
+			"args", args,
+		)
+	}
+	return count, nil
+}
+{{end -}}
+{{- if .Method.Exists}}
+// {{.Method.Exists}} returns true if at least one {{.Singular}} matches the where clause.
+func ({{.ReceiverIdent}} *{{.TypeName}}) {{.Method.Exists}}(where string, args ...interface{}) (bool, error) {
+	query := {{.QuotedExists}}
+	if where != "" {
+		query += " where " + where
+	}
+	// the database stops at the first matching row, and the case expression
+	// works for all dialects, including those without a boolean type
+	query = "select case when exists(" + query + ") then 1 else 0 end"
+	var exists int
+	_, err := {{.ReceiverIdent}}.{{.SchemaField}}.SelectScalar({{.ReceiverIdent}}.{{.DBField}}, (*{{.RowType.Name}})(nil), &exists, query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot query {{.Singular}} exists").With(
+			"where", where,
+			"args", args,
+		)
+	}
+	return exists == 1, nil
+}
+{{end -}}
 {{- end}}`))
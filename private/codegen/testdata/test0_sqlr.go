@@ -106,3 +106,41 @@ func (q *Row0Query) Delete(row *Row0) (int, error) {
 	}
 	return n, nil
 }
+
+// Count returns the number of documents that match the where clause.
+// If where is blank, all documents are counted.
+func (q *Row0Query) Count(where string, args ...interface{}) (int, error) {
+	query := "select count(*) from xyz.rows"
+	if where != "" {
+		query += " where " + where
+	}
+	var count int
+	_, err := q.schema.SelectScalar(q.db, (*Row0)(nil), &count, query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "cannot count documents").With(
+			"where", where,
+			"args", args,
+		)
+	}
+	return count, nil
+}
+
+// Exists returns true if at least one document matches the where clause.
+func (q *Row0Query) Exists(where string, args ...interface{}) (bool, error) {
+	query := "select 1 from xyz.rows"
+	if where != "" {
+		query += " where " + where
+	}
+	// the database stops at the first matching row, and the case expression
+	// works for all dialects, including those without a boolean type
+	query = "select case when exists(" + query + ") then 1 else 0 end"
+	var exists int
+	_, err := q.schema.SelectScalar(q.db, (*Row0)(nil), &exists, query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot query document exists").With(
+			"where", where,
+			"args", args,
+		)
+	}
+	return exists == 1, nil
+}
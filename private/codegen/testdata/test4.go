@@ -0,0 +1,18 @@
+package testdata
+
+// Test case: identity primary key is excluded from the insert column list
+
+//go:generate sqlr-gen
+
+import "github.com/jjeffery/sqlr"
+
+type Row4 struct {
+	ID   int64  `sql:"primary key generated always as identity"`
+	Name string `sql:"natural key"`
+}
+
+type Row4Query struct {
+	db      sqlr.DB
+	schema  *sqlr.Schema
+	rowType *Row4 `table:"identity_rows"`
+}
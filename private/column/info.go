@@ -1,7 +1,10 @@
 package column
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/jjeffery/sqlr/private/scanner"
@@ -16,6 +19,8 @@ type Info struct {
 	Path       Path
 	FieldNames string  // one or more field names, joined by periods
 	Tag        TagInfo // meta data from the struct field tag
+	BitGroup   string  // name of the integer column that the bool field is packed into
+	BitMask    uint64  // bit in the BitGroup column for the field
 	/*
 		PrimaryKey    bool
 		AutoIncrement bool
@@ -63,21 +68,83 @@ func newScannerForString(str string) *scanner.Scanner {
 		"natural_key",
 		"null",
 		"omitempty",
-		"emptynull")
+		"emptynull",
+		"on",
+		"on_update",
+		"encrypt",
+		"encrypted",
+		"encrypt_key_id",
+		"nullsafe",
+		"null_safe",
+		"embedded_prefix",
+		"not",
+		"not_null",
+		"immutable",
+		"precision",
+		"window",
+		"lazy",
+		"enum",
+		"generated",
+		"xor_group",
+		"bit",
+		"index",
+		"index_type")
 	return scan
 }
 
+// valueKeywords are the keywords that are only keywords when they are followed
+// by a value, eg "bit:2". Otherwise they are column names, eg `sql:"bit"`.
+var valueKeywords = map[string]bool{
+	"on_update":       true,
+	"encrypt_key_id":  true,
+	"embedded_prefix": true,
+	"precision":       true,
+	"enum":            true,
+	"xor_group":       true,
+	"bit":             true,
+	"index_type":      true,
+}
+
+// flagKeywords are the keywords that are column names when they are the first
+// word in the tag, eg `sql:"window"`, and are only keywords when they follow
+// the column name or another keyword, eg `sql:"rank window"`.
+var flagKeywords = map[string]bool{
+	"encrypt":   true,
+	"encrypted": true,
+	"nullsafe":  true,
+	"null_safe": true,
+	"immutable": true,
+	"window":    true,
+	"lazy":      true,
+}
+
 // TagInfo is information obtained about a column from the
 // struct tags of its corresponding field.
 type TagInfo struct {
-	Ignore        bool
-	Name          string
-	PrimaryKey    bool
-	AutoIncrement bool
-	Version       bool
-	JSON          bool
-	NaturalKey    bool
-	EmptyNull     bool
+	Ignore         bool
+	Name           string
+	PrimaryKey     bool
+	AutoIncrement  bool
+	Version        bool
+	JSON           bool
+	NaturalKey     bool
+	EmptyNull      bool
+	OnUpdate       string   // value assigned by the database on update, eg "CURRENT_TIMESTAMP"
+	Encrypt        bool     // column contents are encrypted
+	EncryptKeyID   string   // ID of the key used to encrypt, if not the active key
+	NullSafe       bool     // use NULL-safe equality in where clauses
+	EmbeddedPrefix string   // prefix for the column names of an embedded struct's fields
+	NotNull        bool     // empty value is not stored as NULL, even if the schema says so
+	Immutable      bool     // column is set on insert, but never updated
+	Precision      string   // precision of a time column, "nano" is stored as Unix nanoseconds
+	Window         bool     // column is the result of a window function, only scanned from query results
+	Lazy           bool     // column is only selected by "select {all}", not "select {}"
+	Enum           []string // values permitted for the column, eg "enum:active,inactive"
+	BitGroup       string   // integer column that a bool field is packed into, eg "xor_group:flags"
+	BitMask        uint64   // bit for the field in the BitGroup column, eg "bit:2" is 1<<2
+	Index          string   // name of an index that includes the column, eg "index:idx_name"
+	IndexType      string   // type of the index, eg "index_type:gin" for a Postgres GIN index
+	Err            error    // error in the tag, eg an invalid "bit:" value
 }
 
 // ParseTag returns a TagInfo containing information obtained from the
@@ -90,12 +157,147 @@ func ParseTag(tag reflect.StructTag) TagInfo {
 		return tagInfo
 	}
 	var hadKeyword bool
-	for scan.Scan() {
+
+	// scanned is set when the token after a keyword has already been scanned
+	// by hasValue, so that scanNext returns it instead of scanning the next one.
+	var scanned bool
+	scanNext := func() bool {
+		if scanned {
+			scanned = false
+			return true
+		}
+		return scan.Scan()
+	}
+
+	// scanValue scans the value that follows a keyword,
+	// as in "keyword:value". The colon is optional.
+	scanValue := func() string {
+		if !scanNext() {
+			return ""
+		}
+		if scan.Token() == scanner.OP && scan.Text() == ":" {
+			if !scan.Scan() {
+				return ""
+			}
+		}
+		return scanner.Unquote(scan.Text())
+	}
+
+	// scanList scans the comma-separated values that follow a keyword, as
+	// in "keyword:a,b,c". The colon is optional. Scanning stops at the first
+	// token that does not follow a comma, and rescan is set so that the token
+	// is processed by the main loop.
+	var rescan bool
+	scanList := func() []string {
+		var values []string
+		if !scanNext() {
+			return values
+		}
+		if scan.Token() == scanner.OP && scan.Text() == ":" {
+			if !scan.Scan() {
+				return values
+			}
+		}
+		for {
+			if scan.Token() == scanner.OP {
+				rescan = true
+				return values
+			}
+			values = append(values, scanner.Unquote(scan.Text()))
+			if !scan.Scan() {
+				return values
+			}
+			if scan.Token() != scanner.OP || scan.Text() != "," {
+				rescan = true
+				return values
+			}
+			if !scan.Scan() {
+				return values
+			}
+		}
+	}
+
+	// scanExpr scans the expression that follows a keyword, as in
+	// "on update CURRENT_TIMESTAMP(6)". The colon is optional. The expression
+	// ends at the next keyword, which is processed by the main loop, and white
+	// space within the expression is replaced by a single space.
+	scanExpr := func() string {
+		if !scanNext() {
+			return ""
+		}
+		if scan.Token() == scanner.OP && scan.Text() == ":" {
+			if !scan.Scan() {
+				return ""
+			}
+		}
+		scan.IgnoreWhiteSpace = false
+		defer func() { scan.IgnoreWhiteSpace = true }()
+		var texts []string
+		var space bool
+		for {
+			switch scan.Token() {
+			case scanner.KEYWORD:
+				rescan = true
+			case scanner.WS:
+				space = true
+			default:
+				if space && len(texts) > 0 {
+					texts = append(texts, " ")
+				}
+				texts = append(texts, scan.Text())
+				space = false
+			}
+			if rescan || !scan.Scan() {
+				break
+			}
+		}
+		if len(texts) == 1 {
+			return scanner.Unquote(texts[0])
+		}
+		return strings.Join(texts, "")
+	}
+
+	// hasValue scans the token after a keyword that requires a value, and
+	// reports whether it is the value or the colon before it. If it is not,
+	// rescan is set so that the token is processed by the main loop.
+	hasValue := func() bool {
+		more := scan.Scan()
+		if more {
+			switch tok := scan.Token(); {
+			case tok == scanner.OP && scan.Text() == ":", tok == scanner.IDENT, tok == scanner.LITERAL:
+				scanned = true
+				return true
+			}
+		}
+		rescan = more
+		return false
+	}
+
+	for rescan || scan.Scan() {
+		rescan = false
 		tok, lit := scan.Token(), scan.Text()
 		switch tok {
 		case scanner.KEYWORD:
+			nameAllowed := !hadKeyword && tagInfo.Name == ""
 			hadKeyword = true
-			switch strings.ToLower(lit) {
+			keyword := strings.ToLower(lit)
+			if flagKeywords[keyword] && nameAllowed {
+				tagInfo.Name = lit
+				hadKeyword = false
+				continue
+			}
+			if valueKeywords[keyword] && !hasValue() {
+				// without a value the word is not a keyword,
+				// so that it can still be used as a column name
+				if nameAllowed {
+					tagInfo.Name = lit
+					hadKeyword = false
+				} else if keyword == "bit" && tagInfo.Err == nil {
+					tagInfo.Err = errors.New("invalid bit: expected a number from 0 to 63")
+				}
+				continue
+			}
+			switch keyword {
 			case "pk", "primary_key":
 				tagInfo.PrimaryKey = true
 			case "autoincrement", "autoincr":
@@ -110,6 +312,24 @@ func ParseTag(tag reflect.StructTag) TagInfo {
 				}
 			case "identity":
 				tagInfo.AutoIncrement = true
+			case "generated":
+				// "generated always as identity" or "generated by default as identity",
+				// otherwise "generated" can still be used as a column name
+				more := scan.Scan()
+				if follow := strings.ToLower(scan.Text()); more && (follow == "always" || follow == "by") {
+					for scan.Scan() {
+						if strings.ToLower(scan.Text()) == "identity" {
+							tagInfo.AutoIncrement = true
+							break
+						}
+					}
+				} else {
+					if nameAllowed {
+						tagInfo.Name = lit
+						hadKeyword = false
+					}
+					rescan = more
+				}
 			case "version":
 				tagInfo.Version = true
 			case "json", "jsonb":
@@ -122,6 +342,81 @@ func ParseTag(tag reflect.StructTag) TagInfo {
 				}
 			case "null", "omitempty", "emptynull":
 				tagInfo.EmptyNull = true
+			case "on_update":
+				tagInfo.OnUpdate = scanExpr()
+			case "on":
+				// "on" is only a keyword when followed by "update",
+				// so that it can still be used as a column name
+				more := scan.Scan()
+				if more && strings.ToLower(scan.Text()) == "update" {
+					tagInfo.OnUpdate = scanExpr()
+				} else {
+					if nameAllowed {
+						tagInfo.Name = lit
+						hadKeyword = false
+					}
+					rescan = more
+				}
+			case "encrypt", "encrypted":
+				tagInfo.Encrypt = true
+			case "encrypt_key_id":
+				tagInfo.Encrypt = true
+				tagInfo.EncryptKeyID = scanValue()
+			case "nullsafe", "null_safe":
+				tagInfo.NullSafe = true
+			case "embedded_prefix":
+				tagInfo.EmbeddedPrefix = scanValue()
+			case "not_null":
+				tagInfo.NotNull = true
+			case "immutable":
+				tagInfo.Immutable = true
+			case "precision":
+				tagInfo.Precision = strings.ToLower(scanValue())
+			case "window":
+				tagInfo.Window = true
+			case "lazy":
+				tagInfo.Lazy = true
+			case "enum":
+				tagInfo.Enum = scanList()
+			case "xor_group":
+				tagInfo.BitGroup = scanValue()
+			case "bit":
+				value := scanValue()
+				if n, err := strconv.ParseUint(value, 10, 8); err == nil && n < 64 {
+					tagInfo.BitMask = 1 << n
+				} else if tagInfo.Err == nil {
+					tagInfo.Err = fmt.Errorf("invalid bit %q: expected a number from 0 to 63", value)
+				}
+			case "index":
+				// "index" is only a keyword when followed by a colon,
+				// so that it can still be used as a column name
+				more := scan.Scan()
+				if more && scan.Token() == scanner.OP && scan.Text() == ":" {
+					if scan.Scan() {
+						tagInfo.Index = scanner.Unquote(scan.Text())
+					}
+				} else {
+					if nameAllowed {
+						tagInfo.Name = lit
+						hadKeyword = false
+					}
+					rescan = more
+				}
+			case "index_type":
+				tagInfo.IndexType = strings.ToLower(scanValue())
+			case "not":
+				// "not" is only a keyword when followed by "null",
+				// so that it can still be used as a column name
+				more := scan.Scan()
+				if more && strings.ToLower(scan.Text()) == "null" {
+					tagInfo.NotNull = true
+				} else {
+					if nameAllowed {
+						tagInfo.Name = lit
+						hadKeyword = false
+					}
+					rescan = more
+				}
 			}
 		case scanner.IDENT:
 			if !hadKeyword && tagInfo.Name == "" {
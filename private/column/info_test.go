@@ -0,0 +1,214 @@
+package column_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jjeffery/sqlr/private/column"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag     reflect.StructTag
+		tagInfo column.TagInfo
+	}{
+		{
+			tag:     `sql:"primary key"`,
+			tagInfo: column.TagInfo{PrimaryKey: true},
+		},
+		{
+			tag:     `sql:"updated_at on_update:CURRENT_TIMESTAMP"`,
+			tagInfo: column.TagInfo{Name: "updated_at", OnUpdate: "CURRENT_TIMESTAMP"},
+		},
+		{
+			tag:     `sql:"on update:current_timestamp"`,
+			tagInfo: column.TagInfo{OnUpdate: "current_timestamp"},
+		},
+		{
+			tag:     `sql:"on_update CURRENT_TIMESTAMP"`,
+			tagInfo: column.TagInfo{OnUpdate: "CURRENT_TIMESTAMP"},
+		},
+		{
+			tag:     `sql:"updated_at on update CURRENT_TIMESTAMP(6) not null"`,
+			tagInfo: column.TagInfo{Name: "updated_at", OnUpdate: "CURRENT_TIMESTAMP(6)", NotNull: true},
+		},
+		{
+			tag:     `sql:"on_update:now() at time zone 'utc'"`,
+			tagInfo: column.TagInfo{OnUpdate: "now() at time zone 'utc'"},
+		},
+		{
+			tag:     `sql:"on"`,
+			tagInfo: column.TagInfo{Name: "on"},
+		},
+		{
+			tag:     `sql:"on primary key"`,
+			tagInfo: column.TagInfo{Name: "on", PrimaryKey: true},
+		},
+		{
+			tag:     `sql:"ssn encrypt"`,
+			tagInfo: column.TagInfo{Name: "ssn", Encrypt: true},
+		},
+		{
+			tag:     `sql:"encrypt_key_id:key1"`,
+			tagInfo: column.TagInfo{Encrypt: true, EncryptKeyID: "key1"},
+		},
+		{
+			tag:     `sql:"primary key nullsafe"`,
+			tagInfo: column.TagInfo{PrimaryKey: true, NullSafe: true},
+		},
+		{
+			tag:     `sql:"embedded_prefix:home_"`,
+			tagInfo: column.TagInfo{EmbeddedPrefix: "home_"},
+		},
+		{
+			tag:     `sql:"name not null"`,
+			tagInfo: column.TagInfo{Name: "name", NotNull: true},
+		},
+		{
+			tag:     `sql:"not_null"`,
+			tagInfo: column.TagInfo{NotNull: true},
+		},
+		{
+			tag:     `sql:"created_by immutable"`,
+			tagInfo: column.TagInfo{Name: "created_by", Immutable: true},
+		},
+		{
+			tag:     `sql:"precision:nano"`,
+			tagInfo: column.TagInfo{Precision: "nano"},
+		},
+		{
+			tag:     `sql:"rn window"`,
+			tagInfo: column.TagInfo{Name: "rn", Window: true},
+		},
+		{
+			tag:     `sql:"enum:active,inactive,pending"`,
+			tagInfo: column.TagInfo{Enum: []string{"active", "inactive", "pending"}},
+		},
+		{
+			tag:     `sql:"status enum: 'on hold', null , version not null"`,
+			tagInfo: column.TagInfo{Name: "status", Enum: []string{"on hold", "null", "version"}, NotNull: true},
+		},
+		{
+			tag:     `sql:"enum:a null"`,
+			tagInfo: column.TagInfo{Enum: []string{"a"}, EmptyNull: true},
+		},
+		{
+			tag:     `sql:"primary key generated always as identity"`,
+			tagInfo: column.TagInfo{PrimaryKey: true, AutoIncrement: true},
+		},
+		{
+			tag:     `sql:"row_id generated by default as identity"`,
+			tagInfo: column.TagInfo{Name: "row_id", AutoIncrement: true},
+		},
+		{
+			tag:     `sql:"body lazy"`,
+			tagInfo: column.TagInfo{Name: "body", Lazy: true},
+		},
+		{
+			tag:     `sql:"doc index:idx_doc index_type:GIN"`,
+			tagInfo: column.TagInfo{Name: "doc", Index: "idx_doc", IndexType: "gin"},
+		},
+		{
+			tag:     `sql:"index primary key"`,
+			tagInfo: column.TagInfo{Name: "index", PrimaryKey: true},
+		},
+		{
+			tag:     `sql:"index"`,
+			tagInfo: column.TagInfo{Name: "index"},
+		},
+		{
+			tag:     `sql:"xor_group:flag_bits bit:3"`,
+			tagInfo: column.TagInfo{BitGroup: "flag_bits", BitMask: 8},
+		},
+		{
+			tag:     `sql:"xor_group:flag_bits bit:0"`,
+			tagInfo: column.TagInfo{BitGroup: "flag_bits", BitMask: 1},
+		},
+		{
+			tag:     `sql:"xor_group:flag_bits bit:64"`,
+			tagInfo: column.TagInfo{BitGroup: "flag_bits", Err: errors.New(`invalid bit "64": expected a number from 0 to 63`)},
+		},
+		{
+			tag:     `sql:"window"`,
+			tagInfo: column.TagInfo{Name: "window"},
+		},
+		{
+			tag:     `sql:"lazy"`,
+			tagInfo: column.TagInfo{Name: "lazy"},
+		},
+		{
+			tag:     `sql:"immutable"`,
+			tagInfo: column.TagInfo{Name: "immutable"},
+		},
+		{
+			tag:     `sql:"precision"`,
+			tagInfo: column.TagInfo{Name: "precision"},
+		},
+		{
+			tag:     `sql:"enum"`,
+			tagInfo: column.TagInfo{Name: "enum"},
+		},
+		{
+			tag:     `sql:"encrypted"`,
+			tagInfo: column.TagInfo{Name: "encrypted"},
+		},
+		{
+			tag:     `sql:"generated"`,
+			tagInfo: column.TagInfo{Name: "generated"},
+		},
+		{
+			tag:     `sql:"not"`,
+			tagInfo: column.TagInfo{Name: "not"},
+		},
+		{
+			tag:     `sql:"nullsafe"`,
+			tagInfo: column.TagInfo{Name: "nullsafe"},
+		},
+		{
+			tag:     `sql:"embedded_prefix"`,
+			tagInfo: column.TagInfo{Name: "embedded_prefix"},
+		},
+		{
+			tag:     `sql:"window primary key"`,
+			tagInfo: column.TagInfo{Name: "window", PrimaryKey: true},
+		},
+		{
+			tag:     `sql:"rank window"`,
+			tagInfo: column.TagInfo{Name: "rank", Window: true},
+		},
+		{
+			tag:     `sql:"body json lazy"`,
+			tagInfo: column.TagInfo{Name: "body", JSON: true, Lazy: true},
+		},
+		{
+			tag:     `sql:"generated not null"`,
+			tagInfo: column.TagInfo{Name: "generated", NotNull: true},
+		},
+		{
+			tag:     `sql:"id generated always as identity"`,
+			tagInfo: column.TagInfo{Name: "id", AutoIncrement: true},
+		},
+		{
+			tag:     `sql:"bit"`,
+			tagInfo: column.TagInfo{Name: "bit"},
+		},
+		{
+			tag:     `sql:"xor_group not null"`,
+			tagInfo: column.TagInfo{Name: "xor_group", NotNull: true},
+		},
+		{
+			tag:     `sql:"xor_group:flag_bits bit"`,
+			tagInfo: column.TagInfo{BitGroup: "flag_bits", Err: errors.New(`invalid bit: expected a number from 0 to 63`)},
+		},
+		{
+			tag:     `sql:"xor_group:flag_bits bit:x"`,
+			tagInfo: column.TagInfo{BitGroup: "flag_bits", Err: errors.New(`invalid bit "x": expected a number from 0 to 63`)},
+		},
+	}
+	for i, tt := range tests {
+		if got, want := column.ParseTag(tt.tag), tt.tagInfo; !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: want=%+v, got=%+v", i, want, got)
+		}
+	}
+}
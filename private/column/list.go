@@ -2,6 +2,8 @@ package column
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"reflect"
 	"strings"
 	"sync"
@@ -11,23 +13,45 @@ import (
 // Standard types.
 var (
 	sqlScanType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
 	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
 )
 
+// typeKey is the key for cached column information.
+type typeKey struct {
+	rowType  reflect.Type
+	autoJSON bool
+}
+
 // typeMap contains a map of type to column information used
-// to cache results for ListForType.
+// to cache results for ListForType and ListForTypeAutoJSON.
 var typeMap = struct {
 	mu sync.RWMutex
-	m  map[reflect.Type][]*Info
+	m  map[typeKey][]*Info
 }{
-	m: make(map[reflect.Type][]*Info),
+	m: make(map[typeKey][]*Info),
 }
 
 // ListForType returns a list of column information
 // associated with the specified type, which must be a struct.
 func ListForType(rowType reflect.Type) []*Info {
+	return listForType(typeKey{rowType: rowType})
+}
+
+// ListForTypeAutoJSON returns a list of column information associated
+// with the specified type, which must be a struct. Unlike ListForType,
+// fields that are maps, slices or arrays are treated as JSON columns
+// even if they are not tagged as JSON. Byte slices and byte arrays,
+// and types that implement sql.Scanner or driver.Valuer, are not treated
+// as JSON columns.
+func ListForTypeAutoJSON(rowType reflect.Type) []*Info {
+	return listForType(typeKey{rowType: rowType, autoJSON: true})
+}
+
+func listForType(key typeKey) []*Info {
 	typeMap.mu.RLock()
-	list, ok := typeMap.m[rowType]
+	list, ok := typeMap.m[key]
 	typeMap.mu.RUnlock()
 	if ok {
 		return list
@@ -35,22 +59,65 @@ func ListForType(rowType reflect.Type) []*Info {
 
 	typeMap.mu.Lock()
 	defer typeMap.mu.Unlock()
-	list = newList(rowType)
-	typeMap.m[rowType] = list
+	list = newList(key.rowType, key.autoJSON)
+	typeMap.m[key] = list
 	return list
 }
 
+// ClearCache removes all cached column information, so that subsequent
+// calls to ListForType inspect the row type again. It is safe to call
+// concurrently with ListForType.
+func ClearCache() {
+	typeMap.mu.Lock()
+	typeMap.m = make(map[typeKey][]*Info)
+	typeMap.mu.Unlock()
+}
+
+// CacheLen returns the number of column lists in the cache. A row
+// type has a separate list for ListForType and ListForTypeAutoJSON.
+func CacheLen() int {
+	typeMap.mu.RLock()
+	defer typeMap.mu.RUnlock()
+	return len(typeMap.m)
+}
+
 // newList returns a list of column information for the row type.
-func newList(rowType reflect.Type) []*Info {
+func newList(rowType reflect.Type, autoJSON bool) []*Info {
 	var list columnList
-	var state = stateT{}
+	var state = stateT{
+		autoJSON: autoJSON,
+		options:  optionsForType(rowType),
+	}
 	list.addFields(rowType, state)
 	return list
 }
 
 type stateT struct {
-	index Index
-	path  Path
+	index    Index
+	path     Path
+	autoJSON bool               // treat maps, slices and arrays as JSON
+	options  map[string]Options // from the row type's ColumnMeta method, keyed by field names
+}
+
+// isAutoJSON returns true if a field of type fieldType should be
+// treated as a JSON column when it is not tagged as JSON.
+func isAutoJSON(fieldType reflect.Type) bool {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Implements(sqlScanType) ||
+		reflect.PtrTo(fieldType).Implements(sqlScanType) ||
+		fieldType.Implements(valuerType) {
+		return false
+	}
+	switch fieldType.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Slice, reflect.Array:
+		// byte slices and arrays are binary
+		return fieldType.Elem().Kind() != reflect.Uint8
+	}
+	return false
 }
 
 type columnList []*Info
@@ -93,6 +160,22 @@ func (list *columnList) addField(field reflect.StructField, i int, state stateT)
 	// it is necessary to know if the field will be serialized as JSON
 	// in order to decide whether to include the field or not.
 	info := newInfo(field)
+	var opts Options
+	var hasOpts bool
+	if state.options != nil {
+		opts, hasOpts = state.options[state.path.Append(field.Name, field.Tag).String()]
+		if hasOpts {
+			info.Tag = opts.merge(info.Tag)
+		}
+	}
+	if fieldType == rawJSONType {
+		// json.RawMessage holds JSON text, so it is always a JSON column,
+		// which means that the text is checked when it is scanned
+		info.Tag.JSON = true
+	}
+	if state.autoJSON && !info.Tag.JSON && isAutoJSON(fieldType) {
+		info.Tag.JSON = true
+	}
 
 	// Ignore certain types unless they are marked as JSON serialized.
 	if !info.Tag.JSON {
@@ -139,6 +222,15 @@ func (list *columnList) addField(field reflect.StructField, i int, state stateT)
 
 	info.Index = state.index
 	info.Path = state.path
+	if hasOpts && opts.Name != "" {
+		info.Path = info.Path.rename(opts.Name)
+	}
+	if info.Tag.BitGroup != "" && info.Tag.BitMask != 0 && fieldType.Kind() == reflect.Bool {
+		// all of the bool fields in the group share the group's column
+		info.BitGroup = info.Tag.BitGroup
+		info.BitMask = info.Tag.BitMask
+		info.Path = info.Path.rename(info.BitGroup)
+	}
 	info.FieldNames = info.Path.String()
 
 	*list = append(*list, info)
@@ -1,7 +1,10 @@
 package column_test
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -253,6 +256,80 @@ func TestNewList(t *testing.T) {
 	}
 }
 
+func TestClearCache(t *testing.T) {
+	type Row struct {
+		ID   int
+		Name string
+	}
+	rowType := reflect.TypeOf(Row{})
+	list1 := column.ListForType(rowType)
+	if column.CacheLen() == 0 {
+		t.Fatal("want non-empty cache")
+	}
+	column.ClearCache()
+	if got, want := column.CacheLen(), 0; got != want {
+		t.Errorf("want=%d, got=%d", want, got)
+	}
+	list2 := column.ListForType(rowType)
+	if &list1[0] == &list2[0] {
+		t.Errorf("want new column list after clearing cache")
+	}
+	if !reflect.DeepEqual(list1, list2) {
+		t.Errorf("expected list1 and list2 to have identical contents")
+	}
+}
+
+func TestListForTypeAutoJSON(t *testing.T) {
+	type Row struct {
+		ID       int
+		Tags     []string
+		Attrs    map[string]interface{}
+		Points   *[3]float64
+		Data     []byte
+		Raw      json.RawMessage
+		Hash     [16]byte
+		Valuer   valuerSlice
+		Tagged   []int `sql:"json"`
+		Any      interface{}
+		Callback func()
+	}
+	rowType := reflect.TypeOf(Row{})
+
+	tests := []struct {
+		list []*column.Info
+		want string
+	}{
+		{
+			list: column.ListForType(rowType),
+			want: "ID Data Raw:json Tagged:json",
+		},
+		{
+			list: column.ListForTypeAutoJSON(rowType),
+			want: "ID Tags:json Attrs:json Points:json Data Raw:json Tagged:json",
+		},
+	}
+	for i, tt := range tests {
+		var names []string
+		for _, col := range tt.list {
+			name := col.FieldNames
+			if col.Tag.JSON {
+				name += ":json"
+			}
+			names = append(names, name)
+		}
+		if got, want := strings.Join(names, " "), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+// valuerSlice is a slice that implements driver.Valuer
+type valuerSlice []string
+
+func (v valuerSlice) Value() (driver.Value, error) {
+	return strings.Join(v, ","), nil
+}
+
 func compareInfos(t *testing.T, testCase int, expected, actual []*column.Info) {
 	if len(expected) != len(actual) {
 		t.Errorf("%d: expected len=%d, actual len=%d", testCase, len(expected), len(actual))
@@ -0,0 +1,93 @@
+package column
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Options contains column information for a field that is supplied by
+// the ColumnMeta method of its row type, instead of by its struct tag.
+// It has the same fields as the ColumnOptions type in the sqlr package,
+// so that the options returned by ColumnMeta can be converted to Options.
+type Options struct {
+	Name          string
+	PrimaryKey    bool
+	AutoIncrement bool
+	Version       bool
+	NaturalKey    bool
+	JSON          bool
+	Null          bool
+	NullSafe      bool
+	NotNull       bool
+	Immutable     bool
+	Window        bool
+	Lazy          bool
+}
+
+var optionsType = reflect.TypeOf(Options{})
+
+// optionsForType calls the ColumnMeta method of the row type, if it has one,
+// and returns the options keyed by field name. The method can have a value
+// or pointer receiver, and is called on a pointer to the zero value. It must
+// return a map with string keys, and values that can be converted to Options.
+func optionsForType(rowType reflect.Type) map[string]Options {
+	method, ok := reflect.PtrTo(rowType).MethodByName("ColumnMeta")
+	if !ok {
+		return nil
+	}
+	// the method type includes the receiver
+	methodType := method.Type
+	if methodType.NumIn() != 1 || methodType.NumOut() != 1 {
+		return nil
+	}
+	mapType := methodType.Out(0)
+	if mapType.Kind() != reflect.Map ||
+		mapType.Key().Kind() != reflect.String ||
+		!mapType.Elem().ConvertibleTo(optionsType) {
+		return nil
+	}
+
+	result := method.Func.Call([]reflect.Value{reflect.New(rowType)})[0]
+	if result.Len() == 0 {
+		return nil
+	}
+	options := make(map[string]Options, result.Len())
+	for _, key := range result.MapKeys() {
+		options[key.String()] = result.MapIndex(key).Convert(optionsType).Interface().(Options)
+	}
+	return options
+}
+
+// merge adds the options to the tag information. The options take
+// precedence: a column name replaces the name in the tag, and options
+// that are set are added to the keywords in the tag. Options that are
+// not set do not clear keywords in the tag.
+func (opts Options) merge(tag TagInfo) TagInfo {
+	if opts.Name != "" {
+		tag.Name = opts.Name
+	}
+	tag.PrimaryKey = tag.PrimaryKey || opts.PrimaryKey
+	tag.AutoIncrement = tag.AutoIncrement || opts.AutoIncrement
+	tag.Version = tag.Version || opts.Version
+	tag.NaturalKey = tag.NaturalKey || opts.NaturalKey
+	tag.JSON = tag.JSON || opts.JSON
+	tag.EmptyNull = tag.EmptyNull || opts.Null
+	tag.NullSafe = tag.NullSafe || opts.NullSafe
+	tag.NotNull = tag.NotNull || opts.NotNull
+	tag.Immutable = tag.Immutable || opts.Immutable
+	tag.Window = tag.Window || opts.Window
+	tag.Lazy = tag.Lazy || opts.Lazy
+	return tag
+}
+
+// rename returns a copy of the path with the last field tagged with the
+// column name, so that the name takes precedence over the naming convention
+// and the original struct tag.
+func (path Path) rename(name string) Path {
+	clone := make(Path, len(path))
+	copy(clone, path)
+	last := &clone[len(clone)-1]
+	last.FieldTag = reflect.StructTag("sql:" + strconv.Quote("'"+strings.Replace(name, "'", "''", -1)+"'"))
+	return clone
+}
@@ -81,18 +81,42 @@ func (path Path) String() string {
 
 // ColumnName returns a column name by applying the naming
 // convention to the contents of the path.
+//
+// If a struct field in the path has an embedded prefix in its tag,
+// eg `sql:"embedded_prefix:home_"`, the column name for the rest of the path
+// is prepended with the prefix, instead of being joined to the name
+// of the struct field.
 func (path Path) ColumnName(nc NamingConvention, key string) string {
+	return path.ColumnNameForTagKeys(nc, key, nil)
+}
+
+// ColumnNameForTagKeys is like ColumnName, except that the column name is
+// searched for in the struct tag keys in the order given by tagKeys, instead
+// of "sqlr" then "sql". The first key present with a column name is used. For
+// keys other than "sqlr" and "sql", the column name is the tag value up to the
+// first comma, so that tags like `json:"name,omitempty"` can supply the name.
+// If tagKeys is empty, the default keys are searched.
+func (path Path) ColumnNameForTagKeys(nc NamingConvention, key string, tagKeys []string) string {
+	if len(tagKeys) == 0 {
+		tagKeys = structTagKeys
+	}
 	if len(path) == 1 {
 		// The path almost always has one element in it,
 		// so have a special case that requires less memory
 		// allocation.
-		return convertField(path[0].FieldName, path[0].FieldTag, nc, key)
+		return convertField(path[0].FieldName, path[0].FieldTag, nc, key, tagKeys)
 	}
 
 	// Less common case where there is more than one item in the path.
-	frags := make([]string, len(path))
+	frags := make([]string, 0, len(path))
 	for i, f := range path {
-		frags[i] = convertField(f.FieldName, f.FieldTag, nc, key)
+		if i < len(path)-1 {
+			if prefix := ParseTag(f.FieldTag).EmbeddedPrefix; prefix != "" {
+				frags = append(frags, prefix+path[i+1:].ColumnNameForTagKeys(nc, key, tagKeys))
+				break
+			}
+		}
+		frags = append(frags, convertField(f.FieldName, f.FieldTag, nc, key, tagKeys))
 	}
 	return nc.Join(frags)
 }
@@ -101,7 +125,7 @@ func (path Path) ColumnName(nc NamingConvention, key string) string {
 // in order for column information.
 var structTagKeys = []string{"sqlr", "sql"}
 
-func convertField(fieldName string, fieldTag reflect.StructTag, nc NamingConvention, key string) string {
+func convertField(fieldName string, fieldTag reflect.StructTag, nc NamingConvention, key string, tagKeys []string) string {
 	if fieldTag != "" {
 		var nameFromTag string  // the name extracted from the tag, which might be empty
 		var foundNameInTag bool // was the name extracted from the tag
@@ -117,12 +141,12 @@ func convertField(fieldName string, fieldTag reflect.StructTag, nc NamingConvent
 		}
 
 		// If the key for the naming convention was not provided, then
-		// look through the standard struct tag keys. Keep looking until
+		// look through the struct tag keys. Keep looking until
 		// one is found that specifies a name.
 		if !foundNameInTag {
-			for _, key := range structTagKeys {
+			for _, key := range tagKeys {
 				if value, ok := fieldTag.Lookup(key); ok {
-					nameFromTag = nameFromTagValue(value)
+					nameFromTag = nameFromOtherTagValue(key, value)
 					if nameFromTag != "" {
 						foundNameInTag = true
 						break
@@ -141,6 +165,25 @@ func convertField(fieldName string, fieldTag reflect.StructTag, nc NamingConvent
 	return nc.Convert(fieldName)
 }
 
+// nameFromOtherTagValue returns the column name from the value of the struct
+// tag key. The "sqlr" and "sql" keys have the full tag syntax. Other keys, such
+// as "db" and "json", only supply a name, which ends at the first comma, and
+// a name of "-" is ignored rather than excluding the field.
+func nameFromOtherTagValue(key string, tagValue string) string {
+	for _, k := range structTagKeys {
+		if k == key {
+			return nameFromTagValue(tagValue)
+		}
+	}
+	if index := strings.IndexByte(tagValue, ','); index >= 0 {
+		tagValue = tagValue[:index]
+	}
+	if tagValue = strings.TrimSpace(tagValue); tagValue == "-" {
+		return ""
+	}
+	return tagValue
+}
+
 func nameFromTagValue(tagValue string) string {
 	tagValue = strings.TrimSpace(tagValue)
 	if tagValue == "" {
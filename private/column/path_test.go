@@ -2,6 +2,8 @@ package column
 
 import (
 	"testing"
+
+	"github.com/jjeffery/sqlr/private/naming"
 )
 
 func TestPathString(t *testing.T) {
@@ -65,3 +67,41 @@ func TestPathEqual(t *testing.T) {
 		}
 	}
 }
+
+func TestPathColumnName(t *testing.T) {
+	tests := []struct {
+		path Path
+		want string
+	}{
+		{
+			path: NewPath("HomeAddress", "").Append("Street", ""),
+			want: "home_address_street",
+		},
+		{
+			path: NewPath("HomeAddress", `sql:"embedded_prefix:home_"`).Append("Street", ""),
+			want: "home_street",
+		},
+		{
+			path: NewPath("HomeAddress", `sql:"embedded_prefix:home_"`).Append("Street", `sql:"street_name"`),
+			want: "home_street_name",
+		},
+		{
+			path: NewPath("Contact", "").
+				Append("HomeAddress", `sql:"embedded_prefix:h"`).
+				Append("Street", ""),
+			want: "contact_hstreet",
+		},
+		{
+			path: NewPath("Contact", `sql:"embedded_prefix:'c_'"`).
+				Append("HomeAddress", `sql:"embedded_prefix:home_"`).
+				Append("Street", ""),
+			want: "c_home_street",
+		},
+	}
+
+	for i, tt := range tests {
+		if got, want := tt.path.ColumnName(naming.SnakeCase, ""), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
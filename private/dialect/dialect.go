@@ -3,22 +3,55 @@
 package dialect
 
 import (
+	"bytes"
 	"database/sql/driver"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
 // Dialect provides information about an SQL dialect.
 type Dialect struct {
+	name            string // name of a custom dialect
 	driverTypes     []string
 	quoteFunc       func(name string) string
+	quoteIdentFunc  func(name string) string
 	placeholderFunc func(n int) string
+	selectTop       bool   // limits rows with "select top n" instead of "limit n"
+	identityInsert  bool   // requires "set identity_insert" to insert identity values
+	nullSafeFormat  string // NULL-safe comparison of column %[1]s and placeholder %[2]s
+	returning       bool   // supports "insert ... returning"
+	truncate        bool   // "truncate table" can be rolled back, so it is safe in a transaction
+	notify          bool   // supports LISTEN/NOTIFY for table change notifications
+	nextValueFunc   func(sequence string) string
+	retryableFunc   func(err error) bool // reports transient errors from the driver
+	duplicateFunc   func(err error) bool // reports unique constraint violations from the driver
+	columnsQuery    string               // query for the column names of a table, args are table and schema
+	reserved        map[string]bool      // reserved words, in lower case
+	likeWildcards   string               // characters with special meaning in a LIKE pattern, default is "%_"
+	versionQuery    string               // query for the database server version
+	saveTransaction bool                 // uses "save transaction" instead of "savepoint"
+	explainPrefix   string               // prefix for a query that returns its plan, if supported
+	analyzePrefix   string               // prefix for a query that executes and returns its plan, if supported
+	versionFunc     func(version string) bool
+	driverNames     []string          // names that the drivers are registered with, eg "postgres"
+	multiRowInsert  bool              // supports inserting multiple rows in one statement
+	maxPlaceholders int               // maximum number of placeholders in a statement, zero if not known
+	rowValues       bool              // supports row value comparisons, eg "(a,b) > (?,?)"
+	recursiveCTE    string            // keywords that begin a recursive CTE, default is "with recursive"
+	upsert          string            // how an insert becomes an update, eg "on conflict", empty if not supported
+	columnTypes     map[string]string // column types for DDL that differ from ansiColumnTypes
+	autoIncrFormat  string            // definition of an auto-increment column of type %[1]s
+	onUpdate        bool              // supports "on update" in a column definition
+	enumType        bool              // supports "enum(...)" as a column type
+	indexMethod     bool              // supports "using method" when creating an index
 }
 
 // Pre-defined dialects
 var (
 	ANSI     *Dialect
+	MariaDB  *Dialect
 	MSSQL    *Dialect
 	MySQL    *Dialect
 	Postgres *Dialect
@@ -30,6 +63,17 @@ func (d *Dialect) Quote(name string) string {
 	return d.quoteFunc(name)
 }
 
+// QuoteIdentifier quotes a table name or column name that is not trusted,
+// such as a name provided at runtime. Unlike Quote, any quote characters
+// in the name are escaped, so that the name cannot close the quotes early.
+// A name containing periods is quoted as a qualified name, eg "schema"."table".
+func (d *Dialect) QuoteIdentifier(name string) string {
+	if d.quoteIdentFunc == nil {
+		return d.Quote(name)
+	}
+	return d.quoteIdentFunc(name)
+}
+
 // Placeholder returns the string for a placeholder.
 func (d *Dialect) Placeholder(n int) string {
 	if d.placeholderFunc == nil {
@@ -38,6 +82,304 @@ func (d *Dialect) Placeholder(n int) string {
 	return d.placeholderFunc(n)
 }
 
+// SelectTop returns true if the dialect limits the number of rows
+// returned by a query using "select top n", instead of "limit n".
+func (d *Dialect) SelectTop() bool {
+	return d.selectTop
+}
+
+// IdentityInsert returns true if the dialect requires "set identity_insert <table> on"
+// before an insert statement can set the value of an identity column explicitly.
+func (d *Dialect) IdentityInsert() bool {
+	return d.identityInsert
+}
+
+// NullSafeEqual returns an expression that compares the column with the
+// placeholder for equality, where NULL is considered equal to NULL. The
+// placeholder may appear more than once in the expression, but only for
+// dialects with numbered placeholders, so that there is still one arg.
+func (d *Dialect) NullSafeEqual(column, placeholder string) string {
+	format := d.nullSafeFormat
+	if format == "" {
+		format = "%[1]s is not distinct from %[2]s"
+	}
+	return fmt.Sprintf(format, column, placeholder)
+}
+
+// SupportsReturning returns true if the dialect supports a returning clause
+// in an insert statement, eg "insert into t(a, b) values(?, ?) returning id".
+func (d *Dialect) SupportsReturning() bool {
+	return d.returning
+}
+
+// ReturningClause returns the returning clause for an insert statement
+// that returns the named columns, or an empty string if the dialect does not
+// support a returning clause. The clause includes a leading space.
+func (d *Dialect) ReturningClause(columns ...string) string {
+	if !d.returning || len(columns) == 0 {
+		return ""
+	}
+	quoted := make([]string, 0, len(columns))
+	for _, col := range columns {
+		quoted = append(quoted, d.Quote(col))
+	}
+	return " returning " + strings.Join(quoted, ",")
+}
+
+// NextValue returns the SQL expression for obtaining the next value
+// from the named sequence, or an empty string if the dialect does not
+// support sequences.
+func (d *Dialect) NextValue(sequence string) string {
+	if d.nextValueFunc == nil {
+		return ""
+	}
+	return d.nextValueFunc(sequence)
+}
+
+// TruncateTable returns true if the dialect supports "truncate table", and
+// it is safe to use inside a transaction. Otherwise "delete from" is used
+// to remove all rows from a table.
+func (d *Dialect) TruncateTable() bool {
+	return d.truncate
+}
+
+// IsRetryable returns true if err is a transient error reported by the
+// database driver, such as a deadlock or serialization failure, where the
+// operation may succeed if it is attempted again.
+func (d *Dialect) IsRetryable(err error) bool {
+	if err == nil || d.retryableFunc == nil {
+		return false
+	}
+	return d.retryableFunc(err)
+}
+
+// IsDuplicateKey returns true if err is reported by the database driver
+// when an insert or update violates a primary key or unique constraint.
+func (d *Dialect) IsDuplicateKey(err error) bool {
+	if err == nil || d.duplicateFunc == nil {
+		return false
+	}
+	return d.duplicateFunc(err)
+}
+
+// SupportsNotify returns true if the dialect supports notifications of
+// table changes using LISTEN/NOTIFY.
+func (d *Dialect) SupportsNotify() bool {
+	return d.notify
+}
+
+// TableColumnsQuery returns a query that returns the names of the columns
+// in the table, and the args for the query. A table name of the form
+// "schema.table" refers to a table in the named schema, otherwise the
+// table is in the current schema.
+func (d *Dialect) TableColumnsQuery(table string) (string, []interface{}) {
+	var schema string
+	if index := strings.LastIndex(table, "."); index >= 0 {
+		schema, table = table[:index], table[index+1:]
+	}
+	query := d.columnsQuery
+	if query == "" {
+		query = "select column_name from information_schema.columns" +
+			" where table_name = ? and ? in ('', table_schema) order by ordinal_position"
+	}
+	parts := strings.Split(query, "?")
+	query = parts[0]
+	for i, part := range parts[1:] {
+		query += d.Placeholder(i+1) + part
+	}
+	return query, []interface{}{table, schema}
+}
+
+// IsReserved returns true if word is a reserved word in the dialect, and
+// needs to be quoted when used as an identifier. The comparison is not case
+// sensitive.
+func (d *Dialect) IsReserved(word string) bool {
+	return d.reserved[strings.ToLower(word)]
+}
+
+// EscapeLike escapes the characters in s that have a special meaning in a LIKE
+// pattern, so that s only matches itself. The escape character is backslash,
+// which is also escaped.
+func (d *Dialect) EscapeLike(s string) string {
+	wildcards := d.likeWildcards
+	if wildcards == "" {
+		wildcards = "%_"
+	}
+	if !strings.ContainsAny(s, wildcards+`\`) {
+		return s
+	}
+	var buf bytes.Buffer
+	for _, ch := range s {
+		if ch == '\\' || strings.ContainsRune(wildcards, ch) {
+			buf.WriteRune('\\')
+		}
+		buf.WriteRune(ch)
+	}
+	return buf.String()
+}
+
+// SavepointSQL returns the statements that create a savepoint with the
+// given name, release it, and roll back to it. The release statement is
+// empty if the dialect does not release savepoints.
+func (d *Dialect) SavepointSQL(name string) (save, release, rollback string) {
+	if d.saveTransaction {
+		return "save transaction " + name, "", "rollback transaction " + name
+	}
+	return "savepoint " + name, "release savepoint " + name, "rollback to savepoint " + name
+}
+
+// ExplainPrefix returns the prefix that turns a query into a query that returns
+// its execution plan, including a trailing space. If analyze is true, the prefix
+// is for a query that is executed, and returns its plan with actual statistics.
+// It returns an empty string if the dialect does not support the prefix.
+func (d *Dialect) ExplainPrefix(analyze bool) string {
+	if analyze {
+		return d.analyzePrefix
+	}
+	return d.explainPrefix
+}
+
+// VersionQuery returns a query that returns the version of the database
+// server as a single string, or an empty string if the dialect does not
+// know how to query the version.
+func (d *Dialect) VersionQuery() string {
+	return d.versionQuery
+}
+
+// MatchVersion returns true if version, as returned by the query from
+// VersionQuery, identifies a database server that uses the dialect.
+func (d *Dialect) MatchVersion(version string) bool {
+	if d.versionFunc == nil {
+		return true
+	}
+	return d.versionFunc(strings.ToLower(version))
+}
+
+// DriverNames returns the names that the dialect's database drivers are
+// registered with, for use with sql.Open.
+func (d *Dialect) DriverNames() []string {
+	return append([]string(nil), d.driverNames...)
+}
+
+// SupportsMultiRowInsert returns true if the dialect supports inserting
+// multiple rows with one statement, eg "insert into t(a) values(?),(?)".
+func (d *Dialect) SupportsMultiRowInsert() bool {
+	return d.multiRowInsert
+}
+
+// MaxPlaceholders returns the maximum number of placeholders in a statement,
+// or zero if the limit is not known.
+func (d *Dialect) MaxPlaceholders() int {
+	return d.maxPlaceholders
+}
+
+// Custom returns a dialect for a database that is not one of the pre-defined
+// dialects. Names are quoted using begin and end, eg "[" and "]". Placeholders
+// are formatted with the placeholder number using placeholderFormat, eg "$%d",
+// or are "?" if placeholderFormat is empty.
+func Custom(name, begin, end, placeholderFormat string, driverNames []string) *Dialect {
+	d := &Dialect{
+		name:           name,
+		quoteFunc:      quoteFunc(begin, end),
+		quoteIdentFunc: quoteIdentFunc(begin, end),
+		reserved:       reservedWords(sqlReserved),
+		driverNames:    append([]string(nil), driverNames...),
+		rowValues:      true,
+	}
+	if placeholderFormat != "" {
+		d.placeholderFunc = placeholderFunc(placeholderFormat)
+	}
+	return d
+}
+
+// Name returns the name of a custom dialect, or an empty string for
+// the pre-defined dialects.
+func (d *Dialect) Name() string {
+	return d.name
+}
+
+// SupportsRowValues returns true if the dialect supports comparing row values,
+// eg "(a,b) > (?,?)". SQLite supports row values from version 3.15.0.
+func (d *Dialect) SupportsRowValues() bool {
+	return d.rowValues
+}
+
+// RecursiveCTE returns the keywords that begin a query with a recursive
+// common table expression, eg "with recursive". MSSQL uses "with".
+func (d *Dialect) RecursiveCTE() string {
+	if d.recursiveCTE == "" {
+		return "with recursive"
+	}
+	return d.recursiveCTE
+}
+
+// UpsertStyle returns how the dialect inserts a row, or updates it if
+// a row with the same key exists: "on conflict" (Postgres and SQLite 3.24.0
+// and later), "on duplicate key" (MySQL and MariaDB), or "merge" (MSSQL).
+// It returns an empty string if the dialect does not support upserts.
+func (d *Dialect) UpsertStyle() string {
+	return d.upsert
+}
+
+// ansiColumnTypes are the column types used in DDL, keyed by the kind of
+// value stored in the column.
+var ansiColumnTypes = map[string]string{
+	"bool":    "boolean",
+	"int16":   "smallint",
+	"int32":   "integer",
+	"int64":   "bigint",
+	"float32": "real",
+	"float64": "double precision",
+	"string":  "varchar(255)",
+	"bytes":   "blob",
+	"time":    "timestamp",
+	"json":    "varchar(4000)",
+}
+
+// ColumnType returns the column type used in DDL for a kind of value: one of
+// "bool", "int16", "int32", "int64", "float32", "float64", "string", "bytes",
+// "time" or "json". It returns an empty string for any other kind.
+func (d *Dialect) ColumnType(kind string) string {
+	if columnType, ok := d.columnTypes[kind]; ok {
+		return columnType
+	}
+	return ansiColumnTypes[kind]
+}
+
+// AutoIncrement returns the definition of an auto-increment column of the
+// column type. It also returns true if the definition declares the column to be
+// the primary key, as it does for SQLite, where only an "integer primary key"
+// column can be auto-increment.
+func (d *Dialect) AutoIncrement(columnType string) (string, bool) {
+	format := d.autoIncrFormat
+	if format == "" {
+		format = "%[1]s generated by default as identity"
+	}
+	if !strings.Contains(format, "%") {
+		return format, strings.Contains(format, "primary key")
+	}
+	return fmt.Sprintf(format, columnType), strings.Contains(format, "primary key")
+}
+
+// SupportsOnUpdate returns true if a column definition can have an "on update"
+// clause, eg "on update CURRENT_TIMESTAMP" in MySQL.
+func (d *Dialect) SupportsOnUpdate() bool {
+	return d.onUpdate
+}
+
+// SupportsEnumType returns true if the dialect has an enum column type,
+// eg "enum('active','inactive')" in MySQL. Other dialects use a check constraint.
+func (d *Dialect) SupportsEnumType() bool {
+	return d.enumType
+}
+
+// SupportsIndexMethod returns true if an index can be created using a
+// method other than the default, eg "create index i on t using gin (c)"
+// in Postgres.
+func (d *Dialect) SupportsIndexMethod() bool {
+	return d.indexMethod
+}
+
 // Match returns true if the dialect is appropriate for the driver.
 func (d *Dialect) Match(drv driver.Driver) bool {
 	driverType := fmt.Sprint(reflect.TypeOf(drv))
@@ -49,26 +391,220 @@ func (d *Dialect) Match(drv driver.Driver) bool {
 	return false
 }
 
+// mysqlColumnTypes are the column types used in DDL for MySQL and MariaDB.
+var mysqlColumnTypes = map[string]string{
+	"int32":   "int",
+	"float32": "float",
+	"float64": "double",
+	"bytes":   "longblob",
+	"time":    "datetime(6)",
+	"json":    "json",
+}
+
+// mysqlColumnsQuery is the query for the column names of a MySQL table.
+const mysqlColumnsQuery = "select column_name from information_schema.columns" +
+	" where table_name = ? and table_schema = coalesce(nullif(?, ''), database())" +
+	" order by ordinal_position"
+
+// Checks for transient errors returned by the database drivers. The driver
+// packages are not imported, so the error types are identified by name.
+var (
+	// serialization_failure, deadlock_detected, lock_not_available
+	postgresRetryable = errorCodes("pq.Error", "Code", "40001", "40P01", "55P03")
+
+	// ER_LOCK_WAIT_TIMEOUT, ER_LOCK_DEADLOCK
+	mysqlRetryable = errorCodes("mysql.MySQLError", "Number", "1205", "1213")
+
+	// deadlock victim, lock request timeout
+	mssqlRetryable = errorCodes("mssql.Error", "Number", "1205", "1222")
+
+	// SQLITE_BUSY, SQLITE_LOCKED
+	sqliteRetryable = errorCodes("sqlite3.Error", "Code", "5", "6")
+)
+
+// Checks for duplicate key errors returned by the database drivers.
+var (
+	// unique_violation
+	postgresDuplicate = errorCodes("pq.Error", "Code", "23505")
+
+	// ER_DUP_ENTRY, ER_DUP_ENTRY_WITH_KEY_NAME
+	mysqlDuplicate = errorCodes("mysql.MySQLError", "Number", "1062", "1586")
+
+	// violation of primary key or unique constraint, duplicate key in unique index
+	mssqlDuplicate = errorCodes("mssql.Error", "Number", "2627", "2601")
+
+	// SQLITE_CONSTRAINT_PRIMARYKEY, SQLITE_CONSTRAINT_UNIQUE
+	sqliteDuplicate = errorCodes("sqlite3.Error", "ExtendedCode", "1555", "2067")
+)
+
 func init() {
 	ANSI = &Dialect{
-		quoteFunc: quoteFunc(`"`, `"`),
+		quoteFunc:      quoteFunc(`"`, `"`),
+		quoteIdentFunc: quoteIdentFunc(`"`, `"`),
+		// driver is not known, so check for any of them
+		retryableFunc: anyError(postgresRetryable, mysqlRetryable, mssqlRetryable, sqliteRetryable),
+		duplicateFunc: anyError(postgresDuplicate, mysqlDuplicate, mssqlDuplicate, sqliteDuplicate),
+		reserved:      reservedWords(sqlReserved),
+		explainPrefix: "explain ",
 	}
+	ANSI.nextValueFunc = nextValueFunc(ANSI, "next value for %s")
+	ANSI.rowValues = true
 	MSSQL = &Dialect{
-		quoteFunc:   quoteFunc("[", "]"),
-		driverTypes: []string{"*mssql.MssqlDriver"},
+		quoteFunc:      quoteFunc("[", "]"),
+		quoteIdentFunc: quoteIdentFunc("[", "]"),
+		driverTypes:    []string{"*mssql.MssqlDriver"},
+		selectTop:      true,
+		identityInsert: true,
+		truncate:       true,
+		reserved:       reservedWords(sqlReserved, mssqlReserved),
+		likeWildcards:  "%_[",
 	}
+	MSSQL.nextValueFunc = nextValueFunc(MSSQL, "next value for %s")
+	MSSQL.retryableFunc = mssqlRetryable
+	MSSQL.duplicateFunc = mssqlDuplicate
+	MSSQL.saveTransaction = true
+	MSSQL.driverNames = []string{"sqlserver", "mssql"}
+	MSSQL.multiRowInsert = true
+	MSSQL.recursiveCTE = "with"
+	MSSQL.upsert = "merge"
+	// "is not distinct from" requires SQL Server 2022
+	MSSQL.nullSafeFormat = "exists (select %[1]s intersect select %[2]s)"
+	MSSQL.maxPlaceholders = 2100
+	MSSQL.columnTypes = map[string]string{
+		"bool":    "bit",
+		"int32":   "int",
+		"float64": "float",
+		"string":  "nvarchar(255)",
+		"bytes":   "varbinary(max)",
+		"time":    "datetime2",
+		"json":    "nvarchar(max)",
+	}
+	MSSQL.autoIncrFormat = "%[1]s identity(1,1)"
+	MSSQL.versionQuery = "select @@version"
+	MSSQL.versionFunc = versionContains("microsoft sql server")
+	MSSQL.columnsQuery = "select column_name from information_schema.columns" +
+		" where table_name = ? and table_schema = coalesce(nullif(?, ''), schema_name())" +
+		" order by ordinal_position"
 	MySQL = &Dialect{
-		quoteFunc:   quoteFunc("`", "`"),
-		driverTypes: []string{"*mysql.MySQLDriver"},
+		quoteFunc:      quoteFunc("`", "`"),
+		quoteIdentFunc: quoteIdentFunc("`", "`"),
+		driverTypes:    []string{"*mysql.MySQLDriver"},
+		nullSafeFormat: "%[1]s<=>%[2]s",
+		retryableFunc:  mysqlRetryable,
+		duplicateFunc:  mysqlDuplicate,
+		columnsQuery:   mysqlColumnsQuery,
+		reserved:       reservedWords(sqlReserved, mysqlReserved),
+		versionQuery:   "select version()",
+		versionFunc: func(version string) bool {
+			return !strings.Contains(version, "mariadb")
+		},
+		explainPrefix: "explain ",
+		analyzePrefix: "explain analyze ",
+	}
+	// MariaDB uses the same driver as MySQL, so it has no driver types and
+	// is never matched by driver.
+	MariaDB = &Dialect{
+		quoteFunc:      quoteFunc("`", "`"),
+		quoteIdentFunc: quoteIdentFunc("`", "`"),
+		nullSafeFormat: "%[1]s<=>%[2]s",
+		returning:      true,
+		retryableFunc:  mysqlRetryable,
+		duplicateFunc:  mysqlDuplicate,
+		columnsQuery:   mysqlColumnsQuery,
+		reserved:       reservedWords(sqlReserved, mysqlReserved, "returning"),
+		versionQuery:   "select version()",
+		versionFunc:    versionContains("mariadb"),
+		explainPrefix:  "explain ",
+		analyzePrefix:  "analyze ",
 	}
+	MariaDB.nextValueFunc = nextValueFunc(MariaDB, "next value for %s")
+	MySQL.driverNames = []string{"mysql"}
+	MySQL.multiRowInsert = true
+	MySQL.rowValues = true
+	MySQL.upsert = "on duplicate key"
+	MySQL.maxPlaceholders = 65535
+	MariaDB.driverNames = []string{"mysql"}
+	MariaDB.multiRowInsert = true
+	MariaDB.rowValues = true
+	MariaDB.upsert = "on duplicate key"
+	MariaDB.maxPlaceholders = 65535
+	MySQL.columnTypes = mysqlColumnTypes
+	MySQL.autoIncrFormat = "%[1]s auto_increment"
+	MySQL.onUpdate = true
+	MySQL.enumType = true
+	MariaDB.columnTypes = mysqlColumnTypes
+	MariaDB.autoIncrFormat = "%[1]s auto_increment"
+	MariaDB.onUpdate = true
+	MariaDB.enumType = true
 	SQLite = &Dialect{
-		quoteFunc:   quoteFunc("`", "`"),
-		driverTypes: []string{"*sqlite3.SQLiteDriver"},
+		quoteFunc:      quoteFunc("`", "`"),
+		quoteIdentFunc: quoteIdentFunc("`", "`"),
+		driverTypes:    []string{"*sqlite3.SQLiteDriver"},
+		nullSafeFormat: "%[1]s is %[2]s",
+		retryableFunc:  sqliteRetryable,
+		duplicateFunc:  sqliteDuplicate,
+		columnsQuery:   "select name from pragma_table_info(?, coalesce(nullif(?, ''), 'main'))",
+		reserved:       reservedWords(sqlReserved, sqliteReserved),
+		versionQuery:   "select sqlite_version()",
+		explainPrefix:  "explain query plan ",
 	}
+	// the default limit is 999 before SQLite 3.32.0
+	SQLite.driverNames = []string{"sqlite3"}
+	SQLite.multiRowInsert = true
+	SQLite.rowValues = true
+	SQLite.upsert = "on conflict"
+	SQLite.maxPlaceholders = 32766
+	SQLite.columnTypes = map[string]string{
+		"int16":  "integer",
+		"int64":  "integer",
+		"json":   "text",
+		"string": "text",
+	}
+	// only an "integer primary key" column can be auto-increment
+	SQLite.autoIncrFormat = "integer primary key autoincrement"
 	Postgres = &Dialect{
 		quoteFunc:       quoteFunc(`"`, `"`),
+		quoteIdentFunc:  quoteIdentFunc(`"`, `"`),
 		placeholderFunc: placeholderFunc("$%d"),
 		driverTypes:     []string{"*pq.Driver"},
+		returning:       true,
+		truncate:        true,
+		notify:          true,
+		retryableFunc:   postgresRetryable,
+		duplicateFunc:   postgresDuplicate,
+		reserved:        reservedWords(sqlReserved, postgresReserved),
+		versionQuery:    "select version()",
+		versionFunc:     versionContains("postgresql"),
+		explainPrefix:   "explain ",
+		analyzePrefix:   "explain analyze ",
+		columnsQuery: "select column_name from information_schema.columns" +
+			" where table_name = ? and table_schema = coalesce(nullif(?, ''), current_schema())" +
+			" order by ordinal_position",
+		nextValueFunc: func(sequence string) string {
+			return "nextval('" + strings.Replace(sequence, "'", "''", -1) + "')"
+		},
+	}
+	Postgres.driverNames = []string{"postgres", "pgx"}
+	Postgres.multiRowInsert = true
+	Postgres.rowValues = true
+	Postgres.upsert = "on conflict"
+	// "is not distinct from" cannot use a btree index
+	Postgres.nullSafeFormat = "(%[1]s = %[2]s or (%[1]s is null and %[2]s is null))"
+	Postgres.maxPlaceholders = 65535
+	Postgres.columnTypes = map[string]string{
+		"string": "text",
+		"bytes":  "bytea",
+		"time":   "timestamp with time zone",
+		"json":   "jsonb",
+	}
+	Postgres.indexMethod = true
+}
+
+// versionContains returns a function that reports whether a lower case
+// version string contains the substring.
+func versionContains(substr string) func(version string) bool {
+	return func(version string) bool {
+		return strings.Contains(version, substr)
 	}
 }
 
@@ -84,8 +620,104 @@ func quoteFunc(begin string, end string) func(name string) string {
 	}
 }
 
+func quoteIdentFunc(begin string, end string) func(name string) string {
+	return func(name string) string {
+		var names []string
+		for _, n := range strings.Split(name, ".") {
+			names = append(names, begin+strings.Replace(n, end, end+end, -1)+end)
+		}
+		return strings.Join(names, ".")
+	}
+}
+
 func placeholderFunc(format string) func(n int) string {
 	return func(n int) string {
 		return fmt.Sprintf(format, n)
 	}
 }
+
+func nextValueFunc(d *Dialect, format string) func(sequence string) string {
+	return func(sequence string) string {
+		return fmt.Sprintf(format, d.Quote(sequence))
+	}
+}
+
+// errorCodes returns a function that reports whether an error is of the
+// driver error type (eg "pq.Error", pointer or not), and the named field
+// holds one of the codes. Errors that wrap the driver error are unwrapped,
+// see unwrapError.
+func errorCodes(errorType string, fieldName string, codes ...string) func(err error) bool {
+	match := errorCode(errorType, fieldName, codes...)
+	return func(err error) bool {
+		for ; err != nil; err = unwrapError(err) {
+			if match(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// unwrapError returns the error wrapped by err, using either the Unwrap method
+// used by the errors package in go1.13 and later, or the Cause method used by
+// github.com/pkg/errors. It returns nil if err does not wrap another error.
+func unwrapError(err error) error {
+	switch e := err.(type) {
+	case interface {
+		Unwrap() error
+	}:
+		return e.Unwrap()
+	case interface {
+		Cause() error
+	}:
+		return e.Cause()
+	}
+	return nil
+}
+
+// errorCode returns a function that reports whether an error, without
+// unwrapping it, is of the driver error type and holds one of the codes.
+func errorCode(errorType string, fieldName string, codes ...string) func(err error) bool {
+	return func(err error) bool {
+		v := reflect.ValueOf(err)
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return false
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct || fmt.Sprint(v.Type()) != errorType {
+			return false
+		}
+		var code string
+		switch field := v.FieldByName(fieldName); field.Kind() {
+		case reflect.String:
+			code = field.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			code = strconv.FormatInt(field.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			code = strconv.FormatUint(field.Uint(), 10)
+		default:
+			return false
+		}
+		for _, c := range codes {
+			if code == c {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// anyError returns a function that reports whether any of the functions
+// report true for the error.
+func anyError(funcs ...func(err error) bool) func(err error) bool {
+	return func(err error) bool {
+		for _, fn := range funcs {
+			if fn(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -32,6 +32,11 @@ func TestNew(t *testing.T) {
 			expectedQuoted:      "[xxx]",
 			expectedPlaceholder: "?",
 		},
+		{
+			dialect:             MariaDB,
+			expectedQuoted:      "`xxx`",
+			expectedPlaceholder: "?",
+		},
 		{
 			dialect:             ANSI,
 			expectedQuoted:      `"xxx"`,
@@ -99,3 +104,274 @@ func TestMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestReturningAndNextValue(t *testing.T) {
+	tests := []struct {
+		dialect   *Dialect
+		returning string
+		nextValue string
+	}{
+		{
+			dialect:   MariaDB,
+			returning: " returning `id`,`version`",
+			nextValue: "next value for `seq`",
+		},
+		{
+			dialect:   MySQL,
+			returning: "",
+			nextValue: "",
+		},
+		{
+			dialect:   Postgres,
+			returning: ` returning "id","version"`,
+			nextValue: "nextval('seq')",
+		},
+		{
+			dialect:   MSSQL,
+			returning: "",
+			nextValue: "next value for [seq]",
+		},
+	}
+	for i, tt := range tests {
+		if got, want := tt.dialect.SupportsReturning(), tt.returning != ""; got != want {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+		if got, want := tt.dialect.ReturningClause("id", "version"), tt.returning; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		if got, want := tt.dialect.NextValue("seq"), tt.nextValue; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func TestIsReserved(t *testing.T) {
+	tests := []struct {
+		dialect  *Dialect
+		word     string
+		reserved bool
+	}{
+		{ANSI, "select", true},
+		{ANSI, "Order", true},
+		{ANSI, "name", false},
+		{Postgres, "limit", true},
+		{Postgres, "key", false},
+		{MySQL, "key", true},
+		{MySQL, "returning", false},
+		{MariaDB, "returning", true},
+		{MSSQL, "top", true},
+		{SQLite, "index", true},
+		{SQLite, "status", false},
+	}
+	for i, tt := range tests {
+		if got, want := tt.dialect.IsReserved(tt.word), tt.reserved; got != want {
+			t.Errorf("%d: %s: want=%v, got=%v", i, tt.word, want, got)
+		}
+	}
+}
+
+func TestMatchVersion(t *testing.T) {
+	tests := []struct {
+		dialect *Dialect
+		version string
+		match   bool
+	}{
+		{Postgres, "PostgreSQL 15.4 on x86_64-pc-linux-gnu", true},
+		{Postgres, "8.0.34", false},
+		{MySQL, "8.0.34", true},
+		{MySQL, "10.11.4-MariaDB-1:10.11.4", false},
+		{MariaDB, "10.11.4-MariaDB-1:10.11.4", true},
+		{MariaDB, "8.0.34", false},
+		{MSSQL, "Microsoft SQL Server 2019 (RTM) - 15.0.2000.5", true},
+		{MSSQL, "PostgreSQL 15.4", false},
+		{SQLite, "3.39.4", true},
+	}
+	for i, tt := range tests {
+		if tt.dialect.VersionQuery() == "" {
+			t.Errorf("%d: missing version query", i)
+		}
+		if got, want := tt.dialect.MatchVersion(tt.version), tt.match; got != want {
+			t.Errorf("%d: %s: want=%v, got=%v", i, tt.version, want, got)
+		}
+	}
+	if got := ANSI.VersionQuery(); got != "" {
+		t.Errorf("ANSI: want empty version query, got=%q", got)
+	}
+}
+
+type testCodeError struct {
+	Code string
+}
+
+type testNumberError struct {
+	Number uint16
+}
+
+func (e *testCodeError) Error() string  { return "code " + e.Code }
+func (e testNumberError) Error() string { return "number error" }
+
+// testUnwrapError wraps an error in the same way as fmt.Errorf("%w").
+type testUnwrapError struct {
+	err error
+}
+
+// testCauseError wraps an error in the same way as github.com/pkg/errors.
+type testCauseError struct {
+	err error
+}
+
+func (e testUnwrapError) Error() string { return "unwrap: " + e.err.Error() }
+func (e testUnwrapError) Unwrap() error { return e.err }
+func (e testCauseError) Error() string  { return "cause: " + e.err.Error() }
+func (e testCauseError) Cause() error   { return e.err }
+
+func TestIsRetryable(t *testing.T) {
+	codeCheck := errorCodes("dialect.testCodeError", "Code", "40001", "40P01")
+	numberCheck := errorCodes("dialect.testNumberError", "Number", "1213")
+	tests := []struct {
+		dialect *Dialect
+		err     error
+		want    bool
+	}{
+		{
+			dialect: &Dialect{retryableFunc: codeCheck},
+			err:     &testCodeError{Code: "40P01"},
+			want:    true,
+		},
+		{
+			dialect: &Dialect{retryableFunc: codeCheck},
+			err:     &testCodeError{Code: "23505"},
+			want:    false,
+		},
+		{
+			dialect: &Dialect{retryableFunc: codeCheck},
+			err:     testNumberError{Number: 1213},
+			want:    false,
+		},
+		{
+			dialect: &Dialect{retryableFunc: numberCheck},
+			err:     testNumberError{Number: 1213},
+			want:    true,
+		},
+		{
+			dialect: &Dialect{retryableFunc: numberCheck},
+			err:     &testNumberError{Number: 1213},
+			want:    true,
+		},
+		{
+			dialect: &Dialect{retryableFunc: anyError(codeCheck, numberCheck)},
+			err:     &testCodeError{Code: "40001"},
+			want:    true,
+		},
+		{
+			dialect: &Dialect{},
+			err:     &testCodeError{Code: "40001"},
+			want:    false,
+		},
+		{
+			dialect: &Dialect{retryableFunc: codeCheck},
+			err:     testUnwrapError{&testCodeError{Code: "40P01"}},
+			want:    true,
+		},
+		{
+			dialect: &Dialect{retryableFunc: numberCheck},
+			err:     testCauseError{testUnwrapError{testNumberError{Number: 1213}}},
+			want:    true,
+		},
+		{
+			dialect: &Dialect{retryableFunc: codeCheck},
+			err:     testUnwrapError{&testCodeError{Code: "23505"}},
+			want:    false,
+		},
+		{
+			dialect: Postgres,
+			err:     errors.New("pq: could not serialize access (40001)"),
+			want:    false,
+		},
+		{
+			dialect: Postgres,
+			err:     nil,
+			want:    false,
+		},
+	}
+
+	for i, tt := range tests {
+		if got, want := tt.dialect.IsRetryable(tt.err), tt.want; got != want {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+	}
+}
+
+func TestIsDuplicateKey(t *testing.T) {
+	codeCheck := errorCodes("dialect.testCodeError", "Code", "23505")
+	tests := []struct {
+		dialect *Dialect
+		err     error
+		want    bool
+	}{
+		{
+			dialect: &Dialect{duplicateFunc: codeCheck},
+			err:     &testCodeError{Code: "23505"},
+			want:    true,
+		},
+		{
+			dialect: &Dialect{duplicateFunc: codeCheck},
+			err:     &testCodeError{Code: "40001"},
+			want:    false,
+		},
+		{
+			dialect: &Dialect{duplicateFunc: codeCheck},
+			err:     testCauseError{&testCodeError{Code: "23505"}},
+			want:    true,
+		},
+		{
+			dialect: &Dialect{retryableFunc: codeCheck},
+			err:     &testCodeError{Code: "23505"},
+			want:    false,
+		},
+		{
+			dialect: Postgres,
+			err:     errors.New("pq: duplicate key value violates unique constraint (23505)"),
+			want:    false,
+		},
+		{
+			dialect: Postgres,
+			err:     nil,
+			want:    false,
+		},
+	}
+
+	for i, tt := range tests {
+		if got, want := tt.dialect.IsDuplicateKey(tt.err), tt.want; got != want {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+	}
+}
+
+func TestColumnType(t *testing.T) {
+	tests := []struct {
+		dialect *Dialect
+		kind    string
+		want    string
+	}{
+		{dialect: Postgres, kind: "json", want: "jsonb"},
+		{dialect: Postgres, kind: "int64", want: "bigint"},
+		{dialect: MySQL, kind: "time", want: "datetime(6)"},
+		{dialect: MSSQL, kind: "bool", want: "bit"},
+		{dialect: SQLite, kind: "int32", want: "integer"},
+		{dialect: Custom("custom", `"`, `"`, "", nil), kind: "string", want: "varchar(255)"},
+		{dialect: ANSI, kind: "complex", want: ""},
+	}
+	for i, tt := range tests {
+		if got, want := tt.dialect.ColumnType(tt.kind), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+
+	if got, pk := Postgres.AutoIncrement("bigint"); got != "bigint generated by default as identity" || pk {
+		t.Errorf("Postgres: got=%q, %v", got, pk)
+	}
+	if got, pk := SQLite.AutoIncrement("integer"); got != "integer primary key autoincrement" || !pk {
+		t.Errorf("SQLite: got=%q, %v", got, pk)
+	}
+}
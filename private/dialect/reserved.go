@@ -0,0 +1,67 @@
+package dialect
+
+import "strings"
+
+// Reserved words that need to be quoted when used as identifiers. The lists
+// are not exhaustive, but include the words that are likely to be used as
+// table or column names.
+const (
+	// sqlReserved are reserved in the SQL standard and in most dialects.
+	sqlReserved = `all alter and any as asc between both by case cast check collate column
+		constraint create cross current_date current_time current_timestamp current_user
+		default delete desc distinct drop else end except exists false fetch for foreign
+		from full grant group having in inner insert intersect into is join leading left
+		like not null on or order outer primary references right select session_user set
+		some table then to trailing true union unique update user using values when where
+		with`
+
+	postgresReserved = `analyse analyze array asymmetric authorization binary concurrently
+		current_catalog current_role current_schema deferrable do freeze ilike initially
+		isnull lateral limit localtime localtimestamp notnull offset only overlaps placing
+		returning similar symmetric variadic verbose window`
+
+	mysqlReserved = `accessible add before bigint binary blob call cascade change char
+		character condition continue convert cume_dist database databases dec decimal
+		declare delayed dense_rank describe div double dual each elseif enclosed escaped
+		exit explain float force fulltext function generated groups high_priority if
+		ignore index infile int integer interval iterate key keys kill lag lead limit
+		linear lines load lock long loop match mod modifies natural numeric optimize
+		option optionally out outfile over partition precision procedure purge range
+		rank read real recursive regexp release rename repeat replace require resignal
+		restrict return revoke rlike row rows row_number schema schemas separator show
+		signal spatial sql sqlexception sqlstate sqlwarning ssl starting stored
+		straight_join terminated tinyint trigger undo unlock unsigned usage use varchar
+		varying virtual while window write xor zerofill`
+
+	mssqlReserved = `add authorization backup begin break browse bulk cascade checkpoint
+		close clustered coalesce commit compute contains containstable continue convert
+		database dbcc deallocate declare deny disk distributed double dump errlvl escape
+		exec execute exit external file fillfactor freetext freetexttable function goto
+		holdlock identity identity_insert identitycol if index key kill lineno load merge
+		national nocheck nonclustered nullif of off offsets open opendatasource openquery
+		openrowset openxml option over percent pivot plan precision print proc procedure
+		public raiserror read readtext reconfigure replication restore restrict return
+		revert revoke rollback rowcount rowguidcol rule save schema securityaudit
+		semantickeyphrasetable semanticsimilaritydetailstable semanticsimilaritytable
+		setuser shutdown statistics system_user tablesample textsize top tran transaction
+		trigger truncate try_convert tsequal unpivot updatetext use view waitfor while
+		within writetext`
+
+	sqliteReserved = `abort action add after analyze attach autoincrement before begin
+		cascade commit conflict database deferrable deferred detach each escape exclusive
+		explain fail glob if ignore immediate index indexed initially instead isnull key
+		limit match natural no notnull of offset plan pragma query raise recursive regexp
+		reindex release rename replace restrict rollback row savepoint temp temporary
+		transaction trigger vacuum view virtual without`
+)
+
+// reservedWords returns a set of the words in the lists.
+func reservedWords(lists ...string) map[string]bool {
+	words := make(map[string]bool)
+	for _, list := range lists {
+		for _, word := range strings.Fields(list) {
+			words[word] = true
+		}
+	}
+	return words
+}
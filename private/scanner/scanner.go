@@ -36,6 +36,10 @@ const (
 type Scanner struct {
 	IgnoreWhiteSpace bool
 
+	// ColonPlaceholders causes a colon followed by digits, eg ":1", to be
+	// scanned as a placeholder instead of an operator and a literal.
+	ColonPlaceholders bool
+
 	r        *bufio.Reader
 	keywords map[string]bool
 	err      error
@@ -101,6 +105,14 @@ func (s *Scanner) Scan() bool {
 		s.unread(ch2)
 		return s.setToken(OP, runeToString(ch))
 	}
+	if ch == '/' {
+		ch2 := s.read()
+		if ch2 == '*' {
+			return s.scanBlockComment()
+		}
+		s.unread(ch2)
+		return s.setToken(OP, runeToString(ch))
+	}
 	if ch == '[' {
 		return s.scanDelimitedIdentifier('[', ']')
 	}
@@ -149,6 +161,13 @@ func (s *Scanner) Scan() bool {
 	if ch == '$' || ch == '?' {
 		return s.scanPlaceholder(ch)
 	}
+	if ch == ':' && s.ColonPlaceholders {
+		ch2 := s.read()
+		s.unread(ch2)
+		if isDigit(ch2) {
+			return s.scanPlaceholder(ch)
+		}
+	}
 	if strings.ContainsRune(operators, ch) {
 		return s.setToken(OP, runeToString(ch))
 	}
@@ -200,6 +219,26 @@ func (s *Scanner) scanComment(prefix string) bool {
 	return s.setToken(COMMENT, buf.String())
 }
 
+// scanBlockComment scans a comment of the form "/* comment */".
+// An unterminated comment continues to the end of the input.
+func (s *Scanner) scanBlockComment() bool {
+	var buf bytes.Buffer
+	buf.WriteString("/*")
+	var prev rune
+	for {
+		ch := s.read()
+		if ch == eof {
+			break
+		}
+		buf.WriteRune(ch)
+		if prev == '*' && ch == '/' {
+			break
+		}
+		prev = ch
+	}
+	return s.setToken(COMMENT, buf.String())
+}
+
 func (s *Scanner) scanDelimitedIdentifier(startCh rune, endCh rune) bool {
 	var buf bytes.Buffer
 	buf.WriteRune(startCh)
@@ -300,6 +339,14 @@ func (s *Scanner) scanQuote(startChs ...rune) bool {
 }
 
 func (s *Scanner) scanPlaceholder(startCh rune) bool {
+	if startCh == '?' {
+		ch := s.read()
+		if ch == '?' {
+			// "??" is an escaped question mark, which is not a placeholder
+			return s.setToken(OP, "??")
+		}
+		s.unread(ch)
+	}
 	var buf bytes.Buffer
 	buf.WriteRune(startCh)
 	for {
@@ -105,6 +105,31 @@ func TestScan(t *testing.T) {
 				{EOF, ""},
 			},
 		},
+		{ // escaped question marks
+			sql: "a ?? 'k' and b ??| c and ???",
+			tokens: []tokenLit{
+				{IDENT, "a"},
+				{WS, " "},
+				{OP, "??"},
+				{WS, " "},
+				{LITERAL, "'k'"},
+				{WS, " "},
+				{IDENT, "and"},
+				{WS, " "},
+				{IDENT, "b"},
+				{WS, " "},
+				{OP, "??"},
+				{OP, "|"},
+				{WS, " "},
+				{IDENT, "c"},
+				{WS, " "},
+				{IDENT, "and"},
+				{WS, " "},
+				{OP, "??"},
+				{PLACEHOLDER, "?"},
+				{EOF, ""},
+			},
+		},
 		{ // comments
 			sql: "select -- this is a comment\n5-2-- another comment",
 			tokens: []tokenLit{
@@ -118,6 +143,20 @@ func TestScan(t *testing.T) {
 				{EOF, ""},
 			},
 		},
+		{ // block comments
+			sql: "/* app:users,op:get */select 4/2 /* a * b",
+			tokens: []tokenLit{
+				{COMMENT, "/* app:users,op:get */"},
+				{KEYWORD, "select"},
+				{WS, " "},
+				{LITERAL, "4"},
+				{OP, "/"},
+				{LITERAL, "2"},
+				{WS, " "},
+				{COMMENT, "/* a * b"},
+				{EOF, ""},
+			},
+		},
 		{ // literals
 			sql: "'literal ''string''',x'1010',X'1010',n'abc',N'abc',xy,X,nm,N,",
 			tokens: []tokenLit{
@@ -312,3 +351,34 @@ func TestScan(t *testing.T) {
 		check(scanner, tc.ignoreWhiteSpaceTokens, tc.sql, tc.errText)
 	}
 }
+
+func TestScanColonPlaceholders(t *testing.T) {
+	tests := []struct {
+		sql   string
+		colon bool
+		want  []Token
+	}{
+		{sql: "a=:1", colon: true, want: []Token{IDENT, OP, PLACEHOLDER}},
+		{sql: "a=:1", colon: false, want: []Token{IDENT, OP, OP, LITERAL}},
+		{sql: "a::int", colon: true, want: []Token{IDENT, OP, OP, IDENT}},
+		{sql: "a=:", colon: true, want: []Token{IDENT, OP, OP}},
+	}
+	for i, tt := range tests {
+		scan := New(strings.NewReader(tt.sql))
+		scan.ColonPlaceholders = tt.colon
+		var got []Token
+		for scan.Scan() {
+			got = append(got, scan.Token())
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%d: want=%v, got=%v", i, tt.want, got)
+			continue
+		}
+		for j := range got {
+			if got[j] != tt.want[j] {
+				t.Errorf("%d: want=%v, got=%v", i, tt.want, got)
+				break
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package wherein
 
 import (
 	"bytes"
+	"database/sql"
 	"database/sql/driver"
 	"errors"
 	"fmt"
@@ -15,13 +16,13 @@ import (
 )
 
 var (
-	placeholderNumberRE = regexp.MustCompile(`(\?|\$)([0-9]+)?$`)
+	placeholderNumberRE = regexp.MustCompile(`(\?|\$|:)([0-9]+)?$`)
 )
 
 type placeholderInfoT struct {
 	leadingSQL        string // SQL before the placeholder
 	placeholderText   string
-	placeholderPrefix string // prefix that indicates a placeholder ("$", "?")
+	placeholderPrefix string // prefix that indicates a placeholder ("$", "?", ":")
 	origNumber        int    // zero for positional, non-zero for numbered
 	argInfo           *argInfoT
 }
@@ -32,6 +33,108 @@ type argInfoT struct {
 	arg    interface{}
 	slice  reflect.Value
 	len    int
+	width  int // number of values in each tuple, or zero if not tuples
+}
+
+// count returns the number of placeholders that the arg expands to.
+func (argInfo *argInfoT) count() int {
+	if argInfo.width > 0 {
+		return argInfo.len * argInfo.width
+	}
+	return argInfo.len
+}
+
+// TupleStrategy determines how an arg that is a slice of tuples is expanded,
+// eg [][]int{{1, 2}, {3, 4}} for "where (a,b) in (?)".
+type TupleStrategy int
+
+const (
+	// TupleValues expands tuples as a list of row values,
+	// eg "where (a,b) in ((?,?),(?,?))".
+	TupleValues TupleStrategy = iota
+
+	// TupleUnionSelect expands tuples as a union of selects,
+	// eg "where (a,b) in (select ?,? union all select ?,?)".
+	TupleUnionSelect
+)
+
+// List is an argument whose values are always expanded, even if it
+// would otherwise be passed as a single value, such as a []byte or a slice
+// type that implements driver.Valuer. Values should be a slice or an array,
+// otherwise it is passed as a single value.
+type List struct {
+	Values interface{}
+}
+
+// ExpandedQuery is an SQL query and its arguments, after any arguments that
+// are a slice of values have been expanded.
+type ExpandedQuery struct {
+	SQL  string
+	Args []interface{}
+}
+
+// Execer is implemented by *sql.DB and *sql.Tx.
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// Queryer is implemented by *sql.DB and *sql.Tx.
+type Queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// ExecOn executes the query on db.
+func (q *ExpandedQuery) ExecOn(db Execer) (sql.Result, error) {
+	return db.Exec(q.SQL, q.Args...)
+}
+
+// QueryOn executes the query on db, returning the rows.
+func (q *ExpandedQuery) QueryOn(db Queryer) (*sql.Rows, error) {
+	return db.Query(q.SQL, q.Args...)
+}
+
+// String returns the SQL query.
+func (q *ExpandedQuery) String() string {
+	return q.SQL
+}
+
+// ExpandQuery takes an SQL query and associated arguments and expands out any arguments
+// that are a slice of values. Returns the new, expanded SQL query with arguments that have
+// been flattened into a slice of scalar argument values.
+//
+// If args contains only scalar values, then the query and args are unchanged.
+//
+// An argument that is a slice of slices or arrays, such as [][]int{{1, 2}, {3, 4}},
+// is a slice of tuples, and is expanded to a list of row values, as in
+// "(a,b) in ((?,?),(?,?))".
+func ExpandQuery(query string, args []interface{}) (*ExpandedQuery, error) {
+	return ExpandQueryTuples(query, args, TupleValues)
+}
+
+// ExpandQueryTuples is like ExpandQuery, except that args that are slices of
+// tuples are expanded using strategy.
+func ExpandQueryTuples(query string, args []interface{}, strategy TupleStrategy) (*ExpandedQuery, error) {
+	return ExpandQueryOptions(query, args, Options{Tuples: strategy})
+}
+
+// Options control how ExpandQueryOptions expands a query.
+type Options struct {
+	Tuples            TupleStrategy // how args that are slices of tuples are expanded
+	ColonPlaceholders bool          // the query has placeholders like ":1"
+}
+
+// ExpandQueryOptions is like ExpandQuery, except that the query is expanded
+// according to opts.
+func ExpandQueryOptions(query string, args []interface{}, opts Options) (*ExpandedQuery, error) {
+	if !hasSlice(args) {
+		// no changes need to be made
+		return &ExpandedQuery{SQL: query, Args: args}, nil
+	}
+	newQuery, newArgs, err := flattenQuery(query, args, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &ExpandedQuery{SQL: newQuery, Args: newArgs}, nil
 }
 
 // Expand takes an SQL query and associated arguments and expands out any arguments that
@@ -39,22 +142,26 @@ type argInfoT struct {
 // flattened into a slice of scalar argument values.
 //
 // If args contains only scalar values, then query and args are returned unchanged.
+//
+// Expand is equivalent to ExpandQuery, but returns the query and args separately.
 func Expand(query string, args []interface{}) (newQuery string, newArgs []interface{}, err error) {
-	if !hasSlice(args) {
-		// no changes need to be made
-		return query, args, nil
+	q, err := ExpandQuery(query, args)
+	if err != nil {
+		return "", nil, err
 	}
-
-	return flattenQuery(query, args)
+	return q.SQL, q.Args, nil
 }
 
-func flattenQuery(query string, args []interface{}) (newQuery string, newArgs []interface{}, err error) {
-	placeholderInfos, trailingSQL, err := newPlaceholderInfos(query)
+func flattenQuery(query string, args []interface{}, opts Options) (newQuery string, newArgs []interface{}, err error) {
+	placeholderInfos, trailingSQL, err := newPlaceholderInfos(query, opts.ColonPlaceholders)
 	if err != nil {
 		return "", nil, err
 	}
 
-	argInfos := newArgInfos(args)
+	argInfos, err := newArgInfos(args)
+	if err != nil {
+		return "", nil, err
+	}
 
 	numericPlaceholders, err := arePlaceholdersNumeric(placeholderInfos)
 	if err != nil {
@@ -72,19 +179,12 @@ func flattenQuery(query string, args []interface{}) (newQuery string, newArgs []
 				return "", nil, fmt.Errorf("not enough arguments for placeholder %s", placeholderInfo.placeholderText)
 			}
 			argInfo := argInfos[argIndex]
-			start := placeholderInfo.origNumber + argInfo.offset
-			count := argInfo.len
-			if count == 0 {
-				count = 1
-			}
-			end := start + count
-			for n := start; n < end; n++ {
-				if n > start {
-					buf.WriteRune(',')
-				}
+			n := placeholderInfo.origNumber + argInfo.offset
+			writePlaceholders(&buf, argInfo, opts.Tuples, func() {
 				buf.WriteString(placeholderInfo.placeholderPrefix)
 				buf.WriteString(strconv.Itoa(n))
-			}
+				n++
+			})
 		}
 	} else {
 		if len(argInfos) < len(placeholderInfos) {
@@ -92,17 +192,9 @@ func flattenQuery(query string, args []interface{}) (newQuery string, newArgs []
 		}
 		for i, placeholderInfo := range placeholderInfos {
 			buf.WriteString(placeholderInfo.leadingSQL)
-			argInfo := argInfos[i]
-			if argInfo.len == 0 {
+			writePlaceholders(&buf, argInfos[i], opts.Tuples, func() {
 				buf.WriteString(placeholderInfo.placeholderText)
-			} else {
-				for j := 0; j < argInfo.len; j++ {
-					if j > 0 {
-						buf.WriteRune(',')
-					}
-					buf.WriteString(placeholderInfo.placeholderText)
-				}
-			}
+			})
 		}
 	}
 
@@ -113,9 +205,50 @@ func flattenQuery(query string, args []interface{}) (newQuery string, newArgs []
 	return newQuery, newArgs, nil
 }
 
+// writePlaceholders writes the placeholders for the arg, calling placeholder
+// to write each one.
+func writePlaceholders(buf *bytes.Buffer, argInfo *argInfoT, strategy TupleStrategy, placeholder func()) {
+	if argInfo.len == 0 {
+		placeholder()
+		return
+	}
+	if argInfo.width == 0 {
+		for j := 0; j < argInfo.len; j++ {
+			if j > 0 {
+				buf.WriteRune(',')
+			}
+			placeholder()
+		}
+		return
+	}
+	for j := 0; j < argInfo.len; j++ {
+		switch {
+		case strategy == TupleUnionSelect && j > 0:
+			buf.WriteString(" union all select ")
+		case strategy == TupleUnionSelect:
+			buf.WriteString("select ")
+		case j > 0:
+			buf.WriteString(",(")
+		default:
+			buf.WriteRune('(')
+		}
+		for k := 0; k < argInfo.width; k++ {
+			if k > 0 {
+				buf.WriteRune(',')
+			}
+			placeholder()
+		}
+		if strategy != TupleUnionSelect {
+			buf.WriteRune(')')
+		}
+	}
+}
+
 func hasSlice(args []interface{}) bool {
 	for _, arg := range args {
 		switch arg.(type) {
+		case List:
+			return true
 		case string, []byte, int, uint,
 			int8, byte,
 			int16, uint16,
@@ -134,10 +267,11 @@ func hasSlice(args []interface{}) bool {
 	return false
 }
 
-func newPlaceholderInfos(query string) ([]*placeholderInfoT, string, error) {
+func newPlaceholderInfos(query string, colon bool) ([]*placeholderInfoT, string, error) {
 	var placeholderInfos []*placeholderInfoT
 	var buf bytes.Buffer
 	scan := scanner.New(strings.NewReader(query))
+	scan.ColonPlaceholders = colon
 	for scan.Scan() {
 		switch scan.Token() {
 		case scanner.PLACEHOLDER:
@@ -162,7 +296,14 @@ func newPlaceholderInfos(query string) ([]*placeholderInfoT, string, error) {
 				placeholder.origNumber = n
 			}
 			placeholderInfos = append(placeholderInfos, placeholder)
-		case scanner.WS, scanner.COMMENT:
+		case scanner.COMMENT:
+			if strings.HasPrefix(scan.Text(), "/*") {
+				// block comments are kept, as they may be optimizer hints
+				buf.WriteString(scan.Text())
+			} else {
+				buf.WriteRune(' ')
+			}
+		case scanner.WS:
 			buf.WriteRune(' ')
 		default:
 			buf.WriteString(scan.Text())
@@ -171,7 +312,36 @@ func newPlaceholderInfos(query string) ([]*placeholderInfoT, string, error) {
 	//if len(placeholderInfos) == 0 {
 	//	return nil, fmt.Errorf("no placeholders in query")
 	//}
-	return placeholderInfos, buf.String(), nil
+	placeholderInfos, trailingSQL := literalQuestionMarks(placeholderInfos, buf.String())
+	return placeholderInfos, trailingSQL, nil
+}
+
+// literalQuestionMarks treats any "?" placeholders as literal text if the query
+// has "$" placeholders, eg "where data ? 'key' and id = $1". This happens when
+// a postgres query contains an escaped question mark for a jsonb operator.
+func literalQuestionMarks(placeholderInfos []*placeholderInfoT, trailingSQL string) ([]*placeholderInfoT, string) {
+	var dollar bool
+	for _, placeholder := range placeholderInfos {
+		if placeholder.placeholderPrefix == "$" {
+			dollar = true
+			break
+		}
+	}
+	if !dollar {
+		return placeholderInfos, trailingSQL
+	}
+	var infos []*placeholderInfoT
+	var literalSQL string
+	for _, placeholder := range placeholderInfos {
+		if placeholder.placeholderText == "?" {
+			literalSQL += placeholder.leadingSQL + placeholder.placeholderText
+			continue
+		}
+		placeholder.leadingSQL = literalSQL + placeholder.leadingSQL
+		literalSQL = ""
+		infos = append(infos, placeholder)
+	}
+	return infos, literalSQL + trailingSQL
 }
 
 func arePlaceholdersNumeric(placeholderInfos []*placeholderInfoT) (bool, error) {
@@ -192,14 +362,20 @@ func arePlaceholdersNumeric(placeholderInfos []*placeholderInfoT) (bool, error)
 	return numericPlaceholders, nil
 }
 
-func newArgInfos(args []interface{}) []*argInfoT {
+func newArgInfos(args []interface{}) ([]*argInfoT, error) {
 	argInfos := make([]*argInfoT, 0, len(args))
 	for i, arg := range args {
 		argInfo := &argInfoT{
 			index: i,
 			arg:   arg,
 		}
-		switch arg.(type) {
+		switch v := arg.(type) {
+		case List:
+			argInfo.arg = v.Values
+			if rv := reflect.ValueOf(v.Values); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+				argInfo.slice = rv
+				argInfo.len = rv.Len()
+			}
 		case []byte, string,
 			int, uint, int8, byte, int16, uint16, int32, uint32, int64, uint64,
 			float32, float64, driver.Valuer:
@@ -210,17 +386,64 @@ func newArgInfos(args []interface{}) []*argInfoT {
 				argInfo.len = rv.Len()
 			}
 		}
+		if err := setTupleWidth(argInfo); err != nil {
+			return nil, err
+		}
 		argInfos = append(argInfos, argInfo)
 	}
-	return argInfos
+	return argInfos, nil
+}
+
+// setTupleWidth sets the width of the arg if it is a slice of tuples, where
+// each tuple is a slice or an array of values. It is an error if the tuples
+// do not all have the same number of values.
+func setTupleWidth(argInfo *argInfoT) error {
+	if argInfo.len == 0 || !isTuple(argInfo.slice.Type().Elem()) {
+		return nil
+	}
+	for i := 0; i < argInfo.len; i++ {
+		tuple := argInfo.slice.Index(i)
+		if tuple.Len() == 0 {
+			return fmt.Errorf("arg %d: tuple %d is empty", argInfo.index+1, i)
+		}
+		if i == 0 {
+			argInfo.width = tuple.Len()
+		} else if tuple.Len() != argInfo.width {
+			return fmt.Errorf("arg %d: tuple %d has %d values, expected %d", argInfo.index+1, i, tuple.Len(), argInfo.width)
+		}
+	}
+	return nil
+}
+
+// isTuple returns true if values of type t are tuples of values, ie slices
+// or arrays, other than byte slices and types that implement driver.Valuer.
+func isTuple(t reflect.Type) bool {
+	if t.Implements(valuerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return t.Elem().Kind() != reflect.Uint8
+	}
+	return false
 }
 
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+
 func flattenArgs(argInfos []*argInfoT) []interface{} {
 	var args []interface{}
 	for _, argInfo := range argInfos {
 		if argInfo.len == 0 {
 			// not a slice
 			args = append(args, argInfo.arg)
+		} else if argInfo.width > 0 {
+			for i := 0; i < argInfo.len; i++ {
+				tuple := argInfo.slice.Index(i)
+				for j := 0; j < argInfo.width; j++ {
+					args = append(args, tuple.Index(j).Interface())
+				}
+			}
 		} else {
 			for i := 0; i < argInfo.len; i++ {
 				args = append(args, argInfo.slice.Index(i).Interface())
@@ -234,8 +457,8 @@ func setOffsets(argInfos []*argInfoT) {
 	var offset int
 	for _, argInfo := range argInfos {
 		argInfo.offset = offset
-		if argInfo.len > 0 {
-			offset += argInfo.len - 1
+		if count := argInfo.count(); count > 0 {
+			offset += count - 1
 		}
 	}
 }
@@ -53,6 +53,36 @@ func TestFlatten(t *testing.T) {
 			wantSQL:  "select * from tbl where id in (?,?,?)",
 			wantArgs: []interface{}{1, 2, 3},
 		},
+		{
+			sql:      "select * from tbl where id in (?)",
+			args:     []interface{}{List{Values: []byte{1, 2}}},
+			wantSQL:  "select * from tbl where id in (?,?)",
+			wantArgs: []interface{}{byte(1), byte(2)},
+		},
+		{
+			sql:      "select * from tbl where id in ($1) and name = $2",
+			args:     []interface{}{List{Values: [2]string{"a", "b"}}, "zoe"},
+			wantSQL:  "select * from tbl where id in ($1,$2) and name = $3",
+			wantArgs: []interface{}{"a", "b", "zoe"},
+		},
+		{
+			sql:      "select * from tbl where id = ?",
+			args:     []interface{}{List{Values: 100}},
+			wantSQL:  "select * from tbl where id = ?",
+			wantArgs: []interface{}{100},
+		},
+		{
+			sql:      "select * from tbl where data ? 'a' and id in ($1) and data ?| array['b']",
+			args:     []interface{}{[]int{1, 2}},
+			wantSQL:  "select * from tbl where data ? 'a' and id in ($1,$2) and data ?| array['b']",
+			wantArgs: []interface{}{1, 2},
+		},
+		{
+			sql:      "select /*+ hint */ * from tbl where id in (?) -- comment",
+			args:     []interface{}{[]int{1, 2}},
+			wantSQL:  "select /*+ hint */ * from tbl where id in (?,?)  ",
+			wantArgs: []interface{}{1, 2},
+		},
 		{
 			sql:      "select * from tbl where id in ($1)",
 			args:     []interface{}{[]int{1, 2, 3}},
@@ -83,6 +113,29 @@ func TestFlatten(t *testing.T) {
 			wantSQL:  "select * from tbl where age > ? and id in (?,?,?,?) and name in (?,?,?)",
 			wantArgs: []interface{}{16, "zoe", "michaela", "nick", "claire", 1, 2, 3},
 		},
+		{
+			sql:      "select * from tbl where (a,b) in (?) and c = ?",
+			args:     []interface{}{[][]int{{1, 2}, {3, 4}}, "x"},
+			wantSQL:  "select * from tbl where (a,b) in ((?,?),(?,?)) and c = ?",
+			wantArgs: []interface{}{1, 2, 3, 4, "x"},
+		},
+		{
+			sql:      "select * from tbl where c = $2 and (a,b) in ($1)",
+			args:     []interface{}{[][2]interface{}{{1, "a"}, {2, "b"}}, "x"},
+			wantSQL:  "select * from tbl where c = $5 and (a,b) in (($1,$2),($3,$4))",
+			wantArgs: []interface{}{1, "a", 2, "b", "x"},
+		},
+		{
+			sql:      "select * from tbl where data in (?)",
+			args:     []interface{}{[][]byte{{1}, {2}}},
+			wantSQL:  "select * from tbl where data in (?,?)",
+			wantArgs: []interface{}{[]byte{1}, []byte{2}},
+		},
+		{
+			sql:     "select * from tbl where (a,b) in (?)",
+			args:    []interface{}{[][]int{{1, 2}, {3}}},
+			wantErr: "arg 1: tuple 1 has 1 values, expected 2",
+		},
 	}
 
 	for i, tt := range tests {
@@ -108,3 +161,117 @@ func TestFlatten(t *testing.T) {
 		t.Logf("args: %+v", gotArgs)
 	}
 }
+
+func TestExpandQuery(t *testing.T) {
+	q, err := ExpandQuery("select * from tbl where id in (?) and name = ?", []interface{}{[]int{1, 2}, "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.SQL, "select * from tbl where id in (?,?) and name = ?"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+	if got, want := q.Args, []interface{}{1, 2, "x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+
+	// compatibility with Expand
+	sql, args, err := Expand("select * from tbl where id in (?) and name = ?", []interface{}{[]int{1, 2}, "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sql != q.SQL || !reflect.DeepEqual(args, q.Args) {
+		t.Errorf("want=%q %v, got=%q %v", q.SQL, q.Args, sql, args)
+	}
+
+	if _, err := ExpandQuery("select * from tbl where id in ($0)", []interface{}{[]int{1}}); err == nil {
+		t.Error("want error, got nil")
+	}
+}
+
+func TestExpandQueryTuples(t *testing.T) {
+	tests := []struct {
+		sql      string
+		args     []interface{}
+		strategy TupleStrategy
+		wantSQL  string
+	}{
+		{
+			sql:      "select * from tbl where (a,b) in (?)",
+			args:     []interface{}{[][]int{{1, 2}, {3, 4}}},
+			strategy: TupleValues,
+			wantSQL:  "select * from tbl where (a,b) in ((?,?),(?,?))",
+		},
+		{
+			sql:      "select * from tbl where (a,b) in (?)",
+			args:     []interface{}{[][]int{{1, 2}, {3, 4}}},
+			strategy: TupleUnionSelect,
+			wantSQL:  "select * from tbl where (a,b) in (select ?,? union all select ?,?)",
+		},
+		{
+			sql:      "select * from tbl where (a,b) in ($1) and c in ($2)",
+			args:     []interface{}{[][]int{{1, 2}, {3, 4}}, []int{5, 6}},
+			strategy: TupleUnionSelect,
+			wantSQL:  "select * from tbl where (a,b) in (select $1,$2 union all select $3,$4) and c in ($5,$6)",
+		},
+	}
+	for i, tt := range tests {
+		q, err := ExpandQueryTuples(tt.sql, tt.args, tt.strategy)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := q.SQL, tt.wantSQL; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func TestExpandQueryColonPlaceholders(t *testing.T) {
+	tests := []struct {
+		sql     string
+		args    []interface{}
+		wantSQL string
+	}{
+		{
+			sql:     "select * from tbl where a = :1 and b in (:2)",
+			args:    []interface{}{1, []int{2, 3}},
+			wantSQL: "select * from tbl where a = :1 and b in (:2,:3)",
+		},
+		{
+			sql:     "select a::text from tbl where b in (?)",
+			args:    []interface{}{[]int{2, 3}},
+			wantSQL: "select a::text from tbl where b in (?,?)",
+		},
+	}
+	for i, tt := range tests {
+		q, err := ExpandQueryOptions(tt.sql, tt.args, Options{ColonPlaceholders: true})
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := q.SQL, tt.wantSQL; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func BenchmarkExpandQueryTuples(b *testing.B) {
+	tuples := make([][2]int, 1000)
+	for i := range tuples {
+		tuples[i] = [2]int{i, i * 2}
+	}
+	args := []interface{}{tuples}
+	for _, strategy := range []TupleStrategy{TupleValues, TupleUnionSelect} {
+		name := "values"
+		if strategy == TupleUnionSelect {
+			name = "union"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := ExpandQueryTuples("select * from tbl where (a,b) in (?)", args, strategy); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
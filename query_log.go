@@ -0,0 +1,90 @@
+package sqlr
+
+import (
+	"time"
+
+	"github.com/jjeffery/sqlr/private/wherein"
+)
+
+// QueryEvent describes one execution of a statement. It is passed to the
+// function for the WithQueryObserver option after the statement is executed.
+type QueryEvent struct {
+	Dialect  string        // name of the dialect, eg "postgres", or empty for a custom dialect
+	Op       string        // "select", "insert", "update", "delete", or empty if not known
+	Query    string        // query sent to the database
+	Args     []interface{} // args sent to the database
+	Start    time.Time     // time the query was sent
+	Duration time.Duration // time taken to execute the query
+	Rows     int           // number of rows affected, or returned by a select
+	Err      error         // error returned, if any
+}
+
+// queryLogger is called each time a statement is executed, with the query
+// and args sent to the database, the time taken, the number of rows affected
+// or returned, and any error.
+type queryLogger func(event *QueryEvent)
+
+// queryLog records the execution of a statement for its queryLogger. A nil
+// *queryLog is valid, and does nothing.
+type queryLog struct {
+	logger queryLogger
+	event  QueryEvent
+	sent   bool
+}
+
+// newQueryLog returns a log for one execution of the statement, or nil if
+// the statement is not logged.
+func (stmt *Stmt) newQueryLog() *queryLog {
+	if stmt.queryLogger == nil {
+		return nil
+	}
+	return &queryLog{
+		logger: stmt.queryLogger,
+		event: QueryEvent{
+			Dialect: dialectName(stmt.dialect),
+			Op:      stmt.queryType.String(),
+		},
+	}
+}
+
+// executing records the query that is about to be sent to the database.
+func (l *queryLog) executing(query *wherein.ExpandedQuery) {
+	if l != nil {
+		l.sent = true
+		l.event.Query = query.SQL
+		l.event.Args = query.Args
+		l.event.Start = time.Now()
+	}
+}
+
+// done calls the logger, unless the query was never sent to the database.
+func (l *queryLog) done(rows int, err error) {
+	if l != nil && l.sent {
+		l.event.Duration = time.Since(l.event.Start)
+		l.event.Rows = rows
+		l.event.Err = err
+		l.logger(&l.event)
+	}
+}
+
+// queryLoggers returns a logger that calls each of the non-nil loggers,
+// or nil if there are none.
+func queryLoggers(loggers ...queryLogger) queryLogger {
+	var active []queryLogger
+	for _, logger := range loggers {
+		if logger != nil {
+			active = append(active, logger)
+		}
+	}
+	switch len(active) {
+	case 0:
+		return nil
+	case 1:
+		return active[0]
+	}
+	return func(event *QueryEvent) {
+		for _, logger := range active {
+			logger(event)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package sqlr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// QuerySet contains prepared statements for the common operations
+// on the table for a row type. Each statement can be used independently.
+type QuerySet struct {
+	TableName string
+	Insert    *Stmt // insert into <table>({}) values({})
+	Update    *Stmt // update <table> set {} where {}
+	Delete    *Stmt // delete from <table> where {}
+	Get       *Stmt // select {} from <table> where {}
+	SelectAll *Stmt // select {} from <table>
+
+	errs []string
+}
+
+// Validate returns an error if any of the statements in the query set
+// could not be prepared. It is intended to be called when a program starts,
+// so that errors in row types are detected early.
+func (qs *QuerySet) Validate() error {
+	if len(qs.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("query set for table %s: %s", qs.TableName, strings.Join(qs.errs, "; "))
+}
+
+// tableName returns the table name for the row type. This is obtained
+// from the row type's TableName() method if it has one, otherwise it
+// is the name of the row type converted using the naming convention.
+// The name of a row type registered with WithTypes is also pluralized.
+func (s *Schema) tableName(rowType reflect.Type) string {
+	if tableName := inferTableName(rowType); tableName != "" {
+		return tableName
+	}
+	// the naming convention is not called concurrently
+	return s.cache.tableName(rowType, s.tableNameUncached)
+}
+
+func (s *Schema) tableNameUncached(rowType reflect.Type) string {
+	convention := s.convention
+	if convention == nil {
+		convention = defaultNamingConvention
+	}
+	tableName := convention.Convert(rowType.Name())
+	if s.rowTypes[rowType] {
+		tableName = s.pluralize(tableName)
+	}
+	return tableName
+}
+
+// PrepareFromStruct returns a query set containing prepared statements for
+// inserting, updating, deleting and selecting rows of the row type. The row
+// argument can be a struct, a pointer to a struct, or a slice of structs.
+//
+// The table name is obtained from the row type's TableName() method if it
+// has one, otherwise it is the name of the row type converted using the
+// schema's naming convention.
+//
+// Any statement that cannot be prepared is nil. The Update, Delete and Get
+// statements cannot be prepared if the row type has no primary key. Call
+// Validate to check for errors.
+func (s *Schema) PrepareFromStruct(row interface{}) *QuerySet {
+	qs := &QuerySet{}
+	rowType, err := inferRowType(row)
+	if err != nil {
+		qs.errs = append(qs.errs, err.Error())
+		return qs
+	}
+	qs.TableName = s.tableName(rowType)
+
+	prepare := func(name string, format string) *Stmt {
+		stmt, err := s.Prepare(row, fmt.Sprintf(format, qs.TableName))
+		if err != nil {
+			qs.errs = append(qs.errs, fmt.Sprintf("%s: %v", name, err))
+			return nil
+		}
+		return stmt
+	}
+	qs.Insert = prepare("Insert", insertFormat)
+	qs.SelectAll = prepare("SelectAll", "select {} from %s")
+
+	// statements that identify a single row require a primary key
+	var hasPK bool
+	for _, col := range s.columnsForType(rowType) {
+		if col.Tag.PrimaryKey {
+			hasPK = true
+			break
+		}
+	}
+	if !hasPK {
+		qs.errs = append(qs.errs, fmt.Sprintf("Update, Delete, Get: no primary key for type %s", rowType.Name()))
+		return qs
+	}
+	qs.Update = prepare("Update", updateFormat)
+	qs.Delete = prepare("Delete", deleteFormat)
+	qs.Get = prepare("Get", "select {} from %s where {}")
+	return qs
+}
@@ -0,0 +1,61 @@
+package sqlr
+
+import "testing"
+
+type querySetRow struct {
+	ID   int `sql:"primary key autoincrement"`
+	Name string
+}
+
+func TestPrepareFromStruct(t *testing.T) {
+	schema := NewSchema(WithDialect(MySQL))
+	qs := schema.PrepareFromStruct(&querySetRow{})
+	if err := qs.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := qs.TableName, "query_set_row"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+	tests := []struct {
+		stmt *Stmt
+		want string
+	}{
+		{qs.Insert, "insert into query_set_row(`name`) values(?)"},
+		{qs.Update, "update query_set_row set `name`=? where `id`=?"},
+		{qs.Delete, "delete from query_set_row where `id`=?"},
+		{qs.Get, "select `id`,`name` from query_set_row where `id`=?"},
+		{qs.SelectAll, "select `id`,`name` from query_set_row"},
+	}
+	for i, tt := range tests {
+		if got := tt.stmt.String(); got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+
+	// table name from TableName method
+	qs = schema.PrepareFromStruct(tableNameRow{})
+	if err := qs.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := qs.Get.String(), "select `id`,`name` from table_name_rows where `id`=?"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	type NoPKRow struct {
+		Name string
+	}
+	qs = schema.PrepareFromStruct(NoPKRow{})
+	if err := qs.Validate(); err == nil {
+		t.Error("want error for row without primary key, got nil")
+	} else if got, want := err.Error(), "query set for table no_pk_row: Update, Delete, Get: no primary key for type NoPKRow"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+	if qs.Insert == nil || qs.SelectAll == nil {
+		t.Error("want insert and select all statements for row without primary key")
+	}
+
+	qs = schema.PrepareFromStruct(1)
+	if err := qs.Validate(); err == nil {
+		t.Error("want error, got nil")
+	}
+}
@@ -0,0 +1,54 @@
+package sqlr
+
+import (
+	"github.com/jjeffery/sqlr/private/dialect"
+)
+
+// QuoteMode determines when column names are quoted in the queries
+// generated by a schema. See WithIdentifierQuoting.
+type QuoteMode int
+
+// Quote modes
+const (
+	QuoteAlways QuoteMode = iota // always quote column names (the default)
+	QuoteNever                   // never quote column names
+	QuoteAuto                    // quote column names only when necessary
+)
+
+// quote returns the column name, quoted if required by the mode.
+func (m QuoteMode) quote(d Dialect, name string) string {
+	switch m {
+	case QuoteNever:
+		return name
+	case QuoteAuto:
+		if !needsQuote(d, name) {
+			return name
+		}
+	}
+	return d.Quote(name)
+}
+
+// needsQuote returns true if the name is a reserved word in the dialect, or if
+// it is not a plain identifier consisting of lower case letters, digits and
+// underscores. Names with upper case letters are quoted, because some dialects
+// fold unquoted identifiers to lower case.
+func needsQuote(d Dialect, name string) bool {
+	if name == "" {
+		return true
+	}
+	for i, ch := range name {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch == '_':
+		case ch >= '0' && ch <= '9' && i > 0:
+		default:
+			return true
+		}
+	}
+	if r, ok := d.(interface {
+		IsReserved(word string) bool
+	}); ok {
+		return r.IsReserved(name)
+	}
+	// dialect does not know its reserved words, so use the standard ones
+	return dialect.ANSI.IsReserved(name)
+}
@@ -0,0 +1,76 @@
+// Package rediscache provides a statement cache that stores statement
+// metadata in Redis, so that it can be shared between processes.
+//
+// This package does not depend on any particular Redis client package.
+// Most Redis clients can be adapted to the Client interface with a few
+// lines of code. For example, using github.com/go-redis/redis:
+//  type client struct {
+//      rc *redis.Client
+//  }
+//
+//  func (c client) Get(key string) ([]byte, error) {
+//      return c.rc.Get(key).Bytes()
+//  }
+//
+//  func (c client) Set(key string, value []byte) error {
+//      return c.rc.Set(key, value, 0).Err()
+//  }
+//
+//  schema := sqlr.NewSchema(
+//      sqlr.WithCache(rediscache.New(client{rc}, "myapp:")),
+//  )
+package rediscache
+
+import (
+	"encoding/json"
+
+	"github.com/jjeffery/sqlr"
+)
+
+// Client is the interface for the Redis client used by the cache.
+type Client interface {
+	// Get returns the value for key. It should return an error if
+	// the key does not exist.
+	Get(key string) ([]byte, error)
+
+	// Set sets the value for key.
+	Set(key string, value []byte) error
+}
+
+// cache implements the sqlr.StatementCache interface.
+type cache struct {
+	client Client
+	prefix string
+}
+
+// New returns a statement cache that stores statement metadata using client.
+// Each key is prefixed with prefix, which can be used to distinguish between
+// schemas with different configurations.
+//
+// The cache is best effort: errors returned by client are treated as cache misses.
+func New(client Client, prefix string) sqlr.StatementCache {
+	return &cache{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (c *cache) Get(key string) (*sqlr.StmtMetadata, bool) {
+	data, err := c.client.Get(c.prefix + key)
+	if err != nil || len(data) == 0 {
+		return nil, false
+	}
+	var m sqlr.StmtMetadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, false
+	}
+	return &m, true
+}
+
+func (c *cache) Set(key string, m *sqlr.StmtMetadata) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	c.client.Set(c.prefix+key, data)
+}
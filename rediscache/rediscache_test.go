@@ -0,0 +1,54 @@
+package rediscache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jjeffery/sqlr"
+)
+
+type fakeClient map[string][]byte
+
+func (c fakeClient) Get(key string) ([]byte, error) {
+	data, ok := c[key]
+	if !ok {
+		return nil, errors.New("redis: nil")
+	}
+	return data, nil
+}
+
+func (c fakeClient) Set(key string, value []byte) error {
+	c[key] = value
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	client := make(fakeClient)
+	schema := sqlr.NewSchema(sqlr.WithDialect(sqlr.Postgres), sqlr.WithCache(New(client, "test:")))
+	stmt1, err := schema.Prepare(Row{}, "update rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(client), 1; got != want {
+		t.Fatalf("want=%d, got=%d", want, got)
+	}
+	for key := range client {
+		if got, want := key[:5], "test:"; got != want {
+			t.Errorf("want=%q, got=%q", want, got)
+		}
+	}
+
+	// a new schema with the same client uses the cached metadata
+	schema = sqlr.NewSchema(sqlr.WithDialect(sqlr.Postgres), sqlr.WithCache(New(client, "test:")))
+	stmt2, err := schema.Prepare(Row{}, "update rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt2.String(), stmt1.String(); got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
@@ -0,0 +1,51 @@
+package sqlr
+
+// IsRetryable returns true if err is a transient error, such as a deadlock
+// or a serialization failure, where the operation may succeed if it is
+// attempted again.
+//
+// If the schema was created with the WithRetryableCheck option, its function
+// is used. Otherwise the check is performed by the schema's dialect, which
+// inspects the error returned by the database driver. The built-in dialects
+// recognise the following errors:
+//
+//  Postgres (lib/pq):   *pq.Error codes 40001, 40P01, 55P03
+//  MySQL, MariaDB:      *mysql.MySQLError numbers 1205, 1213
+//  MS SQL Server:       mssql.Error numbers 1205, 1222
+//  SQLite:              sqlite3.Error codes SQLITE_BUSY, SQLITE_LOCKED
+//
+// The driver error is found even if it has been wrapped, either using
+// fmt.Errorf with "%w" or using github.com/pkg/errors.
+//
+// Dialects that do not implement an IsRetryable method report that
+// no errors are retryable.
+func (s *Schema) IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if s.retryableCheck != nil {
+		return s.retryableCheck(err)
+	}
+	if d, ok := s.getDialect().(interface {
+		IsRetryable(err error) bool
+	}); ok {
+		return d.IsRetryable(err)
+	}
+	return false
+}
+
+// isDuplicateKey returns true if err is reported by the database driver when
+// a row violates a primary key or unique constraint. The check is performed by
+// the schema's dialect, and dialects that do not implement an IsDuplicateKey
+// method report that no errors are duplicate key errors.
+func (s *Schema) isDuplicateKey(err error) bool {
+	if err == nil {
+		return false
+	}
+	if d, ok := s.getDialect().(interface {
+		IsDuplicateKey(err error) bool
+	}); ok {
+		return d.IsDuplicateKey(err)
+	}
+	return false
+}
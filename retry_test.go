@@ -0,0 +1,66 @@
+package sqlr
+
+import (
+	"errors"
+	"testing"
+)
+
+// retryDialect is a dialect that does not implement IsRetryable
+type retryDialect struct{}
+
+func (d retryDialect) Quote(column string) string { return column }
+func (d retryDialect) Placeholder(n int) string   { return "?" }
+
+func TestIsRetryable(t *testing.T) {
+	errTransient := errors.New("transient error")
+	errOther := errors.New("other error")
+	isTransient := func(err error) bool {
+		return err == errTransient
+	}
+	tests := []struct {
+		schema *Schema
+		err    error
+		want   bool
+	}{
+		{
+			schema: NewSchema(WithDialect(Postgres)),
+			err:    errTransient,
+			want:   false,
+		},
+		{
+			schema: NewSchema(WithDialect(Postgres), WithRetryableCheck(isTransient)),
+			err:    errTransient,
+			want:   true,
+		},
+		{
+			schema: NewSchema(WithDialect(Postgres), WithRetryableCheck(isTransient)),
+			err:    errOther,
+			want:   false,
+		},
+		{
+			schema: NewSchema(WithDialect(Postgres), WithRetryableCheck(isTransient)),
+			err:    nil,
+			want:   false,
+		},
+		{
+			schema: NewSchema(WithDialect(retryDialect{})),
+			err:    errTransient,
+			want:   false,
+		},
+		{
+			schema: NewSchema(WithDialect(retryDialect{}), WithRetryableCheck(isTransient)),
+			err:    errTransient,
+			want:   true,
+		},
+		{
+			schema: NewSchema(WithDialect(retryDialect{})).Clone(WithRetryableCheck(isTransient)),
+			err:    errTransient,
+			want:   true,
+		},
+	}
+	for i, tt := range tests {
+		if got, want := tt.schema.IsRetryable(tt.err), tt.want; got != want {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+	}
+}
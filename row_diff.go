@@ -0,0 +1,95 @@
+package sqlr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/jjeffery/sqlr/private/column"
+)
+
+// RowDiff compares oldRow and newRow field by field, and returns an update
+// statement for the table that sets only the columns whose fields have changed.
+// The rows must have the same struct type, and the statement is executed with
+// the new row:
+//  stmt, err := sqlr.RowDiff(schema, "users", &oldUser, &newUser)
+//  if err != nil {
+//      return err
+//  }
+//  if stmt != nil {
+//      _, err = stmt.Exec(db, &newUser)
+//  }
+//
+// Primary key columns, immutable columns and window columns are never set.
+// If no fields have changed, RowDiff returns a nil statement and no error.
+//
+// The statements are cached by the schema, so there is one statement for each
+// row type, table name and set of changed fields.
+func RowDiff(schema *Schema, tableName string, oldRow, newRow interface{}) (*Stmt, error) {
+	oldValue := reflect.Indirect(reflect.ValueOf(oldRow))
+	newValue := reflect.Indirect(reflect.ValueOf(newRow))
+	if oldValue.Kind() != reflect.Struct || newValue.Kind() != reflect.Struct {
+		return nil, errors.New("expected old and new rows to be structs or pointers to structs")
+	}
+	if oldValue.Type() != newValue.Type() {
+		return nil, fmt.Errorf("cannot compare rows of different types: %s and %s", oldValue.Type(), newValue.Type())
+	}
+	if tableName == "" {
+		tableName = schema.tableName(newValue.Type())
+	}
+
+	var changed int
+	var unchanged []string
+	for _, col := range schema.columnsForType(newValue.Type()) {
+		if col.Tag.PrimaryKey || col.Tag.Immutable || col.Tag.Window {
+			continue
+		}
+		if fieldsEqual(fieldValue(col, oldValue), fieldValue(col, newValue)) {
+			unchanged = append(unchanged, col.FieldNames)
+		} else {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return nil, nil
+	}
+
+	stmt, err := schema.Prepare(newRow, fmt.Sprintf(updateFormat, tableName))
+	if err != nil {
+		return nil, err
+	}
+	if len(unchanged) == 0 {
+		return stmt, nil
+	}
+	// variants are remembered by the statement, keyed by the omitted fields
+	return stmt.omitting(unchanged)
+}
+
+// fieldValue returns the value of the column's field in the row, or an
+// invalid value if the field is in an embedded struct pointer that is nil.
+func fieldValue(col *column.Info, rowValue reflect.Value) reflect.Value {
+	v := rowValue
+	for _, i := range col.Index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// fieldsEqual reports whether two field values are equal. Times are equal if
+// they represent the same instant, even if their locations differ.
+func fieldsEqual(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if ta, ok := a.Interface().(time.Time); ok {
+		return ta.Equal(b.Interface().(time.Time))
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
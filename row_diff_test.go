@@ -0,0 +1,106 @@
+package sqlr
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestRowDiff(t *testing.T) {
+	type Row struct {
+		ID        int `sql:"primary key"`
+		Name      string
+		Email     string
+		Tags      []string  `sql:"json"`
+		CreatedAt time.Time `sql:"created_at immutable"`
+		UpdatedAt time.Time
+	}
+	schema := NewSchema(WithDialect(MySQL))
+	now := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := Row{ID: 1, Name: "Alice", Email: "alice@example.com", Tags: []string{"a"}, UpdatedAt: now}
+
+	tests := []struct {
+		update func(row *Row)
+		want   string
+	}{
+		{
+			update: func(row *Row) {},
+			want:   "",
+		},
+		{
+			update: func(row *Row) { row.Name = "Bob" },
+			want:   "update users set `name`=? where `id`=?",
+		},
+		{
+			update: func(row *Row) { row.Email = "bob@example.com"; row.Tags = []string{"a", "b"} },
+			want:   "update users set `email`=?,`tags`=? where `id`=?",
+		},
+		{
+			// same instant in a different location, and immutable field changed
+			update: func(row *Row) { row.UpdatedAt = now.In(time.FixedZone("X", 3600)); row.CreatedAt = now },
+			want:   "",
+		},
+		{
+			update: func(row *Row) {
+				row.Name, row.Email, row.Tags, row.UpdatedAt = "Bob", "bob@example.com", nil, now.Add(time.Second)
+			},
+			want: "update users set `name`=?,`email`=?,`tags`=?,`updated_at`=? where `id`=?",
+		},
+	}
+	for i, tt := range tests {
+		row := old
+		tt.update(&row)
+		stmt, err := RowDiff(schema, "users", &old, &row)
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		var got string
+		if stmt != nil {
+			got = stmt.String()
+		}
+		if got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+
+	// statement for the same set of changed fields is reused
+	row := old
+	row.Name = "Carol"
+	stmt1, err := RowDiff(schema, "users", old, row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row.Name = "Dave"
+	stmt2, err := RowDiff(schema, "users", old, row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt1 != stmt2 {
+		t.Error("want same statement for the same changed fields")
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectExec(regexp.QuoteMeta("update users set `name`=? where `id`=?")).
+		WithArgs("Dave", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := stmt2.Exec(db, &row); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	type Other struct {
+		ID int `sql:"primary key"`
+	}
+	if _, err := RowDiff(schema, "users", &old, &Other{}); err == nil {
+		t.Error("want error for different row types, got nil")
+	}
+}
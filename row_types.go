@@ -0,0 +1,68 @@
+package sqlr
+
+import (
+	"reflect"
+	"strings"
+)
+
+// shorthandTableName returns the table name for shorthand queries, such as
+// "insert" and "select", for the row type. This is obtained from the row
+// type's TableName() method if it has one. Otherwise, if the row type was
+// registered with WithTypes, it is the row type's name converted using the
+// naming convention and pluralized. Returns an empty string otherwise.
+func (s *Schema) shorthandTableName(rowType reflect.Type) string {
+	if tableName := inferTableName(rowType); tableName != "" {
+		return tableName
+	}
+	if s.rowTypes[rowType] {
+		return s.tableName(rowType)
+	}
+	return ""
+}
+
+// warmRowTypes obtains the column information for the row types registered
+// with WithTypes, so that the reflection is done when the schema is created.
+// It is called after all of the options have been applied, because some
+// options, such as WithAutoJSON, affect the column information.
+func (s *Schema) warmRowTypes() {
+	for rowType := range s.rowTypes {
+		s.columnsForType(rowType)
+	}
+}
+
+// pluralize converts a table name to its plural form, using the schema's
+// pluralizer if it has one.
+func (s *Schema) pluralize(name string) string {
+	if s.pluralizer != nil {
+		return s.pluralizer(name)
+	}
+	return Pluralize(name)
+}
+
+// Pluralize is the default pluralizer for the table names of row types
+// registered with WithTypes. It applies simple English rules to the end
+// of name, eg "user" => "users", "address" => "addresses",
+// "order_category" => "order_categories". Irregular plurals are not
+// recognised, so row types with irregular names should have a TableName()
+// method, or the schema should have a pluralizer (see WithPluralizer).
+func Pluralize(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case name == "":
+		return name
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return name + suffixCase(name, "es")
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return name[:len(name)-1] + suffixCase(name, "ies")
+	}
+	return name + suffixCase(name, "s")
+}
+
+// suffixCase returns suffix in upper case if name ends in an upper case letter.
+func suffixCase(name string, suffix string) string {
+	if last := name[len(name)-1]; last >= 'A' && last <= 'Z' {
+		return strings.ToUpper(suffix)
+	}
+	return suffix
+}
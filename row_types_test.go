@@ -0,0 +1,95 @@
+package sqlr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"user", "users"},
+		{"order_line", "order_lines"},
+		{"address", "addresses"},
+		{"box", "boxes"},
+		{"batch", "batches"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"USER", "USERS"},
+		{"Company", "Companies"},
+		{"", ""},
+	}
+	for i, tt := range tests {
+		if got, want := Pluralize(tt.name), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+type rowTypesOrderLine struct {
+	ID  int `sql:"primary key"`
+	Qty int
+}
+
+type rowTypesProduct struct {
+	ID   int `sql:"primary key"`
+	Name string
+}
+
+func (rowTypesProduct) TableName() string { return "catalog" }
+
+func TestWithTypes(t *testing.T) {
+	schema := NewSchema(
+		WithDialect(Postgres),
+		WithTypes(rowTypesOrderLine{}, &rowTypesProduct{}, 42),
+	)
+	clone := schema.Clone(WithPluralizer(strings.ToUpper))
+	tests := []struct {
+		schema *Schema
+		row    interface{}
+		query  string
+		want   string
+	}{
+		{
+			schema: schema,
+			row:    rowTypesOrderLine{},
+			query:  "insert",
+			want:   `insert into row_types_order_lines("id","qty") values($1,$2)`,
+		},
+		{
+			schema: schema,
+			row:    []*rowTypesOrderLine{},
+			query:  "select",
+			want:   `select "id","qty" from row_types_order_lines where "id"=$1`,
+		},
+		{
+			schema: schema,
+			row:    rowTypesProduct{},
+			query:  "update",
+			want:   `update catalog set "name"=$1 where "id"=$2`,
+		},
+		{
+			schema: clone,
+			row:    rowTypesOrderLine{},
+			query:  "update",
+			want:   `update ROW_TYPES_ORDER_LINE set "qty"=$1 where "id"=$2`,
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := tt.schema.Prepare(tt.row, tt.query)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := stmt.String(), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+
+	// unregistered row types without a TableName method have no table name
+	if _, err := NewSchema().Prepare(rowTypesOrderLine{}, "insert"); err == nil {
+		t.Error("want error, got nil")
+	}
+}
@@ -1,6 +1,17 @@
 package sqlr
 
-import "github.com/jjeffery/sqlr/private/column"
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/jjeffery/sqlr/private/column"
+	"github.com/jjeffery/sqlr/private/scanner"
+)
 
 // Schema contains information about the database that is used
 // when generating SQL statements.
@@ -27,6 +38,115 @@ type Schema struct {
 	fieldMap   *fieldMap
 	identMap   *identMap
 	key        string
+
+	// tableConventions contains naming conventions for particular
+	// tables, keyed by table name in lower case
+	tableConventions map[string]NamingConvention
+
+	// jsonErrorContext indicates that errors unmarshaling JSON columns
+	// should include a snippet of the offending JSON text
+	jsonErrorContext bool
+
+	// argCoercion indicates that string args should be converted
+	// to the type expected by the query
+	argCoercion bool
+
+	// defaultLimit is the maximum number of rows returned by
+	// select queries that do not specify a limit, zero for no limit
+	defaultLimit int
+
+	// statementCache is an optional cache of statement metadata that is
+	// consulted before parsing a query
+	statementCache StatementCache
+
+	// encryptor encrypts and decrypts columns with the "encrypt" tag,
+	// using the active encryption key ID when encrypting
+	encryptor       ColumnEncryptor
+	encryptionKeyID string
+
+	// columnRewriter optionally rewrites column names after the
+	// naming convention has been applied
+	columnRewriter *columnRewriter
+
+	// rowTypes are the row types registered with WithTypes, which have
+	// table names for shorthand queries even without a TableName method
+	rowTypes map[reflect.Type]bool
+
+	// pluralizer converts the name of a registered row type, after the
+	// naming convention, into a table name
+	pluralizer func(name string) string
+
+	// prepareHooks are called each time a statement is prepared, and
+	// any error prevents the statement from being prepared
+	prepareHooks []func(rowType reflect.Type, query string) error
+
+	// tagKeys optionally lists the struct tag keys that are searched
+	// in order for column names, instead of "sqlr" then "sql"
+	tagKeys []string
+
+	// dialectNegotiator optionally determines the dialect for the DB
+	// handle passed to ForDB, which is kept in dialectDB
+	dialectNegotiator func(db *sql.DB) Dialect
+	dialectDB         *sql.DB
+
+	// lazyConnect optionally opens the DB handle that is used when
+	// a nil DB is passed to Exec or Select, the first time it is needed
+	lazyConnect *lazyConnect
+
+	// autoJSON indicates that map, slice and array fields are treated
+	// as JSON columns without needing a "json" tag
+	autoJSON bool
+
+	// emptyStringNull indicates that all string fields are stored as
+	// NULL when empty, as if they had the "null" tag
+	emptyStringNull bool
+
+	// enumValidation indicates that the values of fields with the "enum"
+	// tag are checked before they are sent to the database
+	enumValidation bool
+
+	// errorOnMultipleRows indicates that selecting into a single struct
+	// is an error if the query returns more than one row
+	errorOnMultipleRows bool
+
+	// preserveComments indicates that line comments in queries are
+	// kept, instead of being stripped
+	preserveComments bool
+
+	// quoteMode determines when column names are quoted
+	quoteMode QuoteMode
+
+	// inStrategy determines how args that are slices of tuples are
+	// expanded for tuple IN clauses
+	inStrategy InStrategy
+
+	// columnCase is the letter case of the column names returned by
+	// the database driver, if known
+	columnCase columnCase
+
+	// queryLogger is called each time a statement is executed
+	queryLogger queryLogger
+
+	// queryObserver is called each time a statement is executed,
+	// after the queryLogger
+	queryObserver queryLogger
+
+	// retryableCheck optionally overrides the dialect's check for
+	// transient errors
+	retryableCheck func(err error) bool
+
+	// notificationListener receives notifications for WatchTable
+	notificationListener NotificationListener
+
+	// shardKey optionally computes the table name from a field of the row
+	shardKey *shardKey
+
+	// transforms are used by SelectInto to convert between row types
+	transforms transformMap
+
+	// identityInsert indicates that insert statements that set an identity
+	// column explicitly should be wrapped with "set identity_insert" (MSSQL)
+	identityInsert bool
 }
 
 // NewSchema creates a schema with options.
@@ -37,6 +157,7 @@ func NewSchema(opts ...SchemaOption) *Schema {
 			opt(schema)
 		}
 	}
+	schema.warmRowTypes()
 	return schema
 }
 
@@ -44,8 +165,65 @@ func NewSchema(opts ...SchemaOption) *Schema {
 // for the schema. The column namer returns the column name based on the
 // list of field name/column name mappings for the schema, and the naming
 // convention.
+//
+// The column namer is safe for concurrent use. Column names are cached, and
+// the naming convention is never called concurrently, so implementations of
+// NamingConvention do not need to be safe for concurrent use.
 func (s *Schema) columnNamer() columnNamer {
+	return s.columnNamerForTable("")
+}
+
+// columnNamerForTable returns the column namer for columns of the table. If
+// the table has its own naming convention (see WithConventionPerTable), it is
+// used instead of the schema's naming convention.
+func (s *Schema) columnNamerForTable(table string) columnNamer {
+	convention := s.tableConvention(table)
+	if convention == nil {
+		// the schema's convention applies, so the table is not part of the cache key
+		table = ""
+	}
 	return columnNamerFunc(func(col *column.Info) string {
+		return s.cache.columnName(col, table, func(col *column.Info) string {
+			return s.columnNameUncached(col, convention)
+		})
+	})
+}
+
+// columnNamerForQuery returns the column namer for columns of the table
+// in the query, see columnNamerForTable.
+func (s *Schema) columnNamerForQuery(query string) columnNamer {
+	if len(s.tableConventions) > 0 {
+		return s.columnNamerForTable(statementTable(query))
+	}
+	return s.columnNamer()
+}
+
+// tableConvention returns the naming convention for the table, or nil if
+// the table does not have its own naming convention. Table names are matched
+// without regard to case, and a table name of the form "schema.table" also
+// matches a convention for the unqualified table name.
+func (s *Schema) tableConvention(table string) NamingConvention {
+	if table == "" || len(s.tableConventions) == 0 {
+		return nil
+	}
+	table = strings.ToLower(table)
+	if convention, ok := s.tableConventions[table]; ok {
+		return convention
+	}
+	if index := strings.LastIndex(table, "."); index >= 0 {
+		return s.tableConventions[table[index+1:]]
+	}
+	return nil
+}
+
+// columnNameUncached returns the column name for col without consulting
+// the schema's cache of column names. If convention is nil, the schema's
+// naming convention is used.
+func (s *Schema) columnNameUncached(col *column.Info, convention NamingConvention) string {
+	if convention == nil {
+		convention = s.convention
+	}
+	namer := columnNamerFunc(func(col *column.Info) string {
 		if s.fieldMap != nil {
 			if columnName, ok := s.fieldMap.lookup(col.FieldNames); ok {
 				// If the field map returns an empty string, this means to
@@ -56,12 +234,23 @@ func (s *Schema) columnNamer() columnNamer {
 				}
 			}
 		}
-		convention := s.convention
 		if convention == nil {
 			convention = defaultNamingConvention
 		}
-		return col.Path.ColumnName(convention, s.key)
+		return col.Path.ColumnNameForTagKeys(convention, s.key, s.tagKeys)
 	})
+	if s.columnRewriter != nil {
+		return s.columnRewriter.columnNamer(namer).ColumnName(col)
+	}
+	return namer.ColumnName(col)
+}
+
+// columnsForType returns the column information for the row type.
+func (s *Schema) columnsForType(rowType reflect.Type) []*column.Info {
+	if s.autoJSON {
+		return column.ListForTypeAutoJSON(rowType)
+	}
+	return column.ListForType(rowType)
 }
 
 // renameIdent implements the identRenamer interface.
@@ -79,9 +268,26 @@ func (s *Schema) getDialect() Dialect {
 	if s.dialect != nil {
 		return s.dialect
 	}
+	if s.lazyConnect != nil {
+		if dialect := s.lazyConnect.getDialect(); dialect != nil {
+			return dialect
+		}
+	}
 	return DefaultDialect
 }
 
+// dialectForDB returns the dialect for the open DB handle. The dialect
+// negotiator is used if one has been specified, otherwise the dialect
+// is determined by the DB driver.
+func (s *Schema) dialectForDB(db *sql.DB) Dialect {
+	if s.dialectNegotiator != nil {
+		if dialect := s.dialectNegotiator(db); dialect != nil {
+			return dialect
+		}
+	}
+	return dialectFor(db)
+}
+
 // Clone creates a copy of the schema, with options applied.
 func (s *Schema) Clone(opts ...SchemaOption) *Schema {
 	clone := &Schema{
@@ -90,17 +296,105 @@ func (s *Schema) Clone(opts ...SchemaOption) *Schema {
 		fieldMap:   newFieldMap(s.fieldMap),
 		identMap:   newIdentMap(s.identMap),
 		key:        s.key,
+
+		jsonErrorContext: s.jsonErrorContext,
+		argCoercion:      s.argCoercion,
+		defaultLimit:     s.defaultLimit,
+		statementCache:   s.statementCache,
+		encryptor:        s.encryptor,
+		encryptionKeyID:  s.encryptionKeyID,
+		identityInsert:   s.identityInsert,
+		autoJSON:         s.autoJSON,
+		emptyStringNull:  s.emptyStringNull,
+		enumValidation:   s.enumValidation,
+		retryableCheck:   s.retryableCheck,
+		preserveComments: s.preserveComments,
+		quoteMode:        s.quoteMode,
+		columnCase:       s.columnCase,
+		queryLogger:      s.queryLogger,
+		queryObserver:    s.queryObserver,
+
+		errorOnMultipleRows:  s.errorOnMultipleRows,
+		notificationListener: s.notificationListener,
+		shardKey:             s.shardKey,
+		inStrategy:           s.inStrategy,
+
+		dialectNegotiator: s.dialectNegotiator,
+		dialectDB:         s.dialectDB,
+		lazyConnect:       s.lazyConnect,
+		pluralizer:        s.pluralizer,
+	}
+	if len(s.rowTypes) > 0 {
+		clone.rowTypes = make(map[reflect.Type]bool, len(s.rowTypes))
+		for rowType := range s.rowTypes {
+			clone.rowTypes[rowType] = true
+		}
+	}
+	if len(s.prepareHooks) > 0 {
+		clone.prepareHooks = append([]func(reflect.Type, string) error(nil), s.prepareHooks...)
+	}
+	if len(s.tagKeys) > 0 {
+		clone.tagKeys = append([]string(nil), s.tagKeys...)
+	}
+	if len(s.tableConventions) > 0 {
+		clone.tableConventions = make(map[string]NamingConvention, len(s.tableConventions))
+		for table, convention := range s.tableConventions {
+			clone.tableConventions[table] = convention
+		}
+	}
+	s.transforms.copyTo(&clone.transforms)
+	if s.columnRewriter != nil {
+		clone.columnRewriter = newColumnRewriter(s.columnRewriter.fn)
 	}
 	for _, opt := range opts {
 		opt(clone)
 	}
+	clone.warmRowTypes()
 	return clone
 }
 
+// ClearTypeCache discards the column information that has been cached for
+// row types, and the statements cached by the schema. Subsequent statements
+// inspect their row types again. This is mainly useful in test suites that
+// define many struct types, and for programs that reload plugins.
+//
+// Column information for row types is shared by all schemas, but only the
+// statements cached by this schema are discarded. It is safe to call
+// ClearTypeCache concurrently with other methods, although statements that
+// are being prepared at the time may use the previous column information.
+func (s *Schema) ClearTypeCache() {
+	column.ClearCache()
+	s.cache.clear()
+}
+
+// TypeCacheLen returns the number of row types that have cached
+// column information. As the column information is shared by all
+// schemas, the count includes row types used by other schemas.
+func (s *Schema) TypeCacheLen() int {
+	return column.CacheLen()
+}
+
 // Prepare creates a prepared statement for later queries or executions.
 // Multiple queries or executions may be run concurrently from the returned
 // statement.
+//
+// If the query is a shorthand notation without a table name, such as "insert"
+// or "select", and the row type has a method "TableName() string", then the
+// table name is obtained by calling that method. If the schema was created with
+// the WithShardKey option and row is a struct with the shard key field, then the
+// table name for an "insert" or "update" shorthand is computed from the field's
+// value instead. A "select" shorthand cannot be used for a row type with the shard
+// key field: use ShardTable to name the table.
+//
+// Any functions registered with WithPrepareHook are called each time, even if
+// the statement has been prepared before, and an error from any of them is
+// returned by Prepare.
 func (s *Schema) Prepare(row interface{}, query string) (*Stmt, error) {
+	// connect first if WithLazyConnect, as the dialect depends on the DB
+	if _, err := s.lazyDB(nil); err != nil {
+		return nil, err
+	}
+
 	// determine row type to use for statement
 	rowType, err := inferRowType(row)
 	if err != nil {
@@ -108,18 +402,40 @@ func (s *Schema) Prepare(row interface{}, query string) (*Stmt, error) {
 	}
 
 	// convert common shorthand SQL notations
-	if query, err = checkSQL(query); err != nil {
+	tableName, err := s.shardTableForQuery(row, rowType, query)
+	if err != nil {
 		return nil, err
 	}
+	if tableName == "" {
+		tableName = s.shorthandTableName(rowType)
+	}
+	if query, err = checkSQL(query, tableName); err != nil {
+		return nil, err
+	}
+	for _, hook := range s.prepareHooks {
+		if err := hook(rowType, query); err != nil {
+			return nil, err
+		}
+	}
 
 	// attempt to get statement from the schema's statement cache
 	stmt, ok := s.cache.lookup(rowType, query)
 	if !ok {
 		// build statement from scratch
-		stmt, err = newStmt(s.getDialect(), s.columnNamer(), s, rowType, query)
+		stmt, err = s.newStmt(rowType, query)
 		if err != nil {
 			return nil, err
 		}
+		stmt.jsonErrorContext = s.jsonErrorContext
+		stmt.argCoercion = s.argCoercion
+		stmt.encryptor = s.encryptor
+		stmt.encryptionKeyID = s.encryptionKeyID
+		stmt.emptyStringNull = s.emptyStringNull
+		stmt.enumValidation = s.enumValidation
+		stmt.errorOnMultipleRows = s.errorOnMultipleRows
+		stmt.columnCase = s.columnCase
+		stmt.inStrategy = s.inStrategy
+		stmt.queryLogger = queryLoggers(s.queryLogger, s.queryObserver)
 		// add to schema's statement cache, returning the statement in the
 		// cache -- this is just in case another goroutine has beaten us to it
 		stmt = s.cache.set(rowType, query, stmt)
@@ -127,6 +443,96 @@ func (s *Schema) Prepare(row interface{}, query string) (*Stmt, error) {
 	return stmt, nil
 }
 
+// newStmt creates a new statement for the row type and query, using
+// the schema's statement cache if it has one.
+func (s *Schema) newStmt(rowType reflect.Type, query string) (*Stmt, error) {
+	columns := s.columnsForType(rowType)
+	namer := s.columnNamerForQuery(query)
+	var cacheKey string
+	if s.statementCache != nil {
+		cacheKey = statementCacheKey(rowType, columns, namer, s.statementSchemaKey(), query)
+		if m, ok := s.statementCache.Get(cacheKey); ok && m != nil {
+			stmt, err := newStmtFromMetadata(s.getDialect(), namer, s, rowType, columns, m, s.preserveComments, s.quoteMode, s.identityInsert)
+			if err == nil {
+				return stmt, nil
+			}
+			// metadata does not match the row type, so parse the query
+		}
+	}
+
+	stmtQuery := query
+	if s.defaultLimit > 0 {
+		var err error
+		stmtQuery, err = defaultLimitQuery(s.getDialect(), query, s.defaultLimit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	stmt, err := newStmt(s.getDialect(), namer, s, rowType, columns, stmtQuery, s.preserveComments, s.quoteMode, s.identityInsert)
+	if err != nil {
+		return nil, err
+	}
+	if s.statementCache != nil {
+		s.statementCache.Set(cacheKey, stmt.metadata())
+	}
+	return stmt, nil
+}
+
+// statementSchemaKey returns the part of the statement cache key that depends on
+// the schema's options. It includes every option that changes the query rendered
+// for a statement, so that schemas with different options (eg a clone with another
+// dialect) can share a statement cache. Column names are part of the key, so options
+// that only change column names (eg the naming convention) are not included here.
+func (s *Schema) statementSchemaKey() string {
+	d := s.getDialect()
+	var buf bytes.Buffer
+	// a dialect without a name is identified by its type and its rendering
+	fmt.Fprintf(&buf, "%s\x00%s\x00%T\x00%s\x00%s", s.key, dialectName(d), d, d.Quote("x"), d.Placeholder(1))
+	fmt.Fprintf(&buf, "\x00quote%d\x00limit%d", s.quoteMode, s.defaultLimit)
+	if s.preserveComments {
+		buf.WriteString("\x00comments")
+	}
+	if s.identityInsert {
+		buf.WriteString("\x00identity")
+	}
+	if s.identMap != nil {
+		buf.WriteString("\x00idents:")
+		buf.WriteString(s.identMap.key())
+	}
+	return buf.String()
+}
+
+// ColumnsSQL returns the list of columns for the row type as an SQL string,
+// suitable for use in the column list of a SELECT query. Column names are
+// quoted according to the schema's dialect. The row argument can be a struct,
+// a pointer to a struct, or a slice of structs.
+//
+// The optional filter has the same syntax as the text inside the curly braces
+// of a column list in a query, eg "pk", "nopk" or "alias u". With no filter all
+// columns are returned.
+//
+// ColumnsSQL is useful for building custom queries that do not fit the
+// "{}" pattern used by Prepare.
+func (s *Schema) ColumnsSQL(row interface{}, filter ...string) (string, error) {
+	rowType, err := inferRowType(row)
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(strings.Join(filter, " "))
+	if strings.HasPrefix(text, "{") {
+		text = strings.TrimSpace(scanner.Unquote(text))
+	}
+	cols := newColumns(s.columnsForType(rowType))
+	cols.tableAlias = inferTableAlias(rowType)
+	cols, err = cols.Parse(clauseSelectColumns, text)
+	if err != nil {
+		return "", err
+	}
+	// no placeholders in a select column list
+	counter := func() int { return 0 }
+	return cols.String(s.getDialect(), s.quoteMode, s.columnNamer(), counter), nil
+}
+
 // Select executes a SELECT query and stores the result in rows.
 // The argument passed to rows can be one of the following:
 //  A pointer to an array of structs; or
@@ -140,29 +546,165 @@ func (s *Schema) Prepare(row interface{}, query string) (*Stmt, error) {
 // option when the query will only return one row.
 //
 // Select returns the number of rows returned by the SELECT
-// query.
+// query. If the schema was created with the WithLazyConnect
+// option, db can be nil to use the schema's DB handle.
 func (s *Schema) Select(db DB, rows interface{}, sql string, args ...interface{}) (int, error) {
 	stmt, err := s.Prepare(rows, sql)
 	if err != nil {
 		return 0, err
 	}
+	if db, err = s.lazyDB(db); err != nil {
+		return 0, err
+	}
 	return stmt.Select(db, rows, args...)
 }
 
+// SelectOne executes a SELECT query and stores the first row returned in row,
+// which must be a pointer to a struct. It returns sql.ErrNoRows if the query
+// does not return any rows.
+func (s *Schema) SelectOne(db DB, row interface{}, sql string, args ...interface{}) error {
+	stmt, err := s.Prepare(row, sql)
+	if err != nil {
+		return err
+	}
+	return stmt.SelectOne(db, row, args...)
+}
+
+// SelectTop executes a SELECT query that returns at most n rows, and stores the
+// result in rows. It is the same as Select, except that the query is modified to
+// limit the number of rows returned. For MSSQL a "top n" clause is inserted after
+// the select keyword. For other dialects a "limit n" clause is appended.
+//
+// If the query already limits the number of rows, then the lesser of the two limits
+// is used. It is an error if the existing limit is not a number, such as a placeholder.
+//
+// The statement for the query is prepared and cached in the same way as for Select,
+// and the limit is applied to a copy of the statement that is not cached, so calling
+// SelectTop with many different values of n does not fill the statement cache.
+func (s *Schema) SelectTop(db DB, rows interface{}, n int, sql string, args ...interface{}) (int, error) {
+	stmt, err := s.Prepare(rows, sql)
+	if err != nil {
+		return 0, err
+	}
+	query, err := limitQuery(stmt.dialect, stmt.query, n)
+	if err != nil {
+		return 0, err
+	}
+	top := stmt.clone()
+	top.query = query
+	return top.Select(db, rows, args...)
+}
+
+// SelectEach executes a SELECT query and calls fn once for each row returned.
+// The row argument is used only to determine the row type, and can be a struct,
+// a pointer to a struct, or a slice of structs. The value passed to fn is a pointer
+// to a struct of the row type.
+//
+// If fn returns an error, iteration stops and SelectEach returns that error.
+func (s *Schema) SelectEach(db DB, row interface{}, fn func(row interface{}) error, sql string, args ...interface{}) error {
+	stmt, err := s.Prepare(row, sql)
+	if err != nil {
+		return err
+	}
+	return stmt.SelectEach(db, fn, args...)
+}
+
+// SelectScalar executes a SELECT query that returns a single column, and stores
+// the value from the first row in dest. The row argument is used only to determine
+// the row type, which is used for expanding any column lists and renaming
+// identifiers. SelectScalar returns the number of rows returned by the query.
+func (s *Schema) SelectScalar(db DB, row interface{}, dest interface{}, sql string, args ...interface{}) (int, error) {
+	stmt, err := s.Prepare(row, sql)
+	if err != nil {
+		return 0, err
+	}
+	return stmt.SelectScalar(db, dest, args...)
+}
+
+// SelectJSON executes a SELECT query and writes the rows returned to w as a
+// JSON array. The row argument is used only to determine the row type, and can
+// be a struct, a pointer to a struct, or a slice of structs. SelectJSON returns
+// the number of rows returned by the query. See Stmt.SelectJSON for details.
+func (s *Schema) SelectJSON(db DB, row interface{}, w io.Writer, sql string, args ...interface{}) (int, error) {
+	stmt, err := s.Prepare(row, sql)
+	if err != nil {
+		return 0, err
+	}
+	return stmt.SelectJSON(db, w, args...)
+}
+
 // Exec executes the query with the given row and optional arguments.
 // It returns the number of rows affected by the statement.
 //
 // If the statement is an INSERT statement and the row has an auto-increment field,
 // then the row is updated with the value of the auto-increment column, as long as
 // the SQL driver supports this functionality.
+//
+// If the schema was created with the WithLazyConnect option, db can be nil to
+// use the schema's DB handle.
 func (s *Schema) Exec(db DB, row interface{}, sql string, args ...interface{}) (int, error) {
 	stmt, err := s.Prepare(row, sql)
 	if err != nil {
 		return 0, err
 	}
+	if db, err = s.lazyDB(db); err != nil {
+		return 0, err
+	}
 	return stmt.Exec(db, row, args...)
 }
 
+// UpdateMany executes an UPDATE statement once for each row in rows, which
+// must be a slice of structs or a slice of struct pointers. It returns the number
+// of rows affected by the statement for each row, in the same order as rows. This
+// makes it possible to detect which rows were not updated, for example when
+// using optimistic locking.
+//
+// If db is a *sql.DB, all of the updates are performed in a single transaction,
+// and no updates are committed if any update fails. If db is a *sql.Tx, the
+// calling program is responsible for committing or rolling back the transaction.
+//
+// If the row type has a method "TableName() string", then the query can be
+// the shorthand notation "update":
+//  counts, err := schema.UpdateMany(db, rows, "update")
+func (s *Schema) UpdateMany(db DB, rows interface{}, query string, args ...interface{}) ([]int, error) {
+	sliceValue := reflect.Indirect(reflect.ValueOf(rows))
+	if sliceValue.Kind() != reflect.Slice {
+		return nil, errors.New("expected rows to be a slice of structs or a slice of struct pointers")
+	}
+	stmt, err := s.Prepare(rows, query)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.queryType != queryUpdate {
+		return nil, fmt.Errorf("expected update statement, got %q", stmt.query)
+	}
+
+	var tx *sql.Tx
+	if beginner, ok := db.(interface {
+		Begin() (*sql.Tx, error)
+	}); ok {
+		if tx, err = beginner.Begin(); err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+		db = tx
+	}
+
+	counts := make([]int, sliceValue.Len())
+	for i := range counts {
+		if counts[i], err = stmt.Exec(db, sliceValue.Index(i).Interface(), args...); err != nil {
+			return nil, fmt.Errorf("cannot update row %d: %v", i, err)
+		}
+	}
+
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
 // Key returns the key associated with the schema.
 func (s *Schema) Key() string {
 	return s.key
@@ -1,15 +1,22 @@
 package sqlr
 
-import "database/sql"
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+)
 
 // A SchemaOption provides optional configuration and is supplied when
 // creating a new Schema, or cloning a Schema.
 type SchemaOption func(schema *Schema)
 
 // ForDB creates an option that sets the dialect for the open DB handle.
+// The dialect is determined by the DB driver, unless a dialect negotiator
+// has been specified using the WithDialectNegotiator option.
 func ForDB(db *sql.DB) SchemaOption {
 	return func(schema *Schema) {
-		schema.dialect = dialectFor(db)
+		schema.dialectDB = db
+		schema.dialect = schema.dialectForDB(db)
 		schema.cache.clear()
 	}
 }
@@ -64,6 +71,7 @@ func WithField(fieldName string, columnName string) SchemaOption {
 			schema.fieldMap = newFieldMap(schema.fieldMap)
 		}
 		schema.fieldMap.add(fieldName, columnName)
+		schema.cache.clear()
 	}
 }
 
@@ -82,6 +90,7 @@ func WithIdentifier(identifier string, meaning string) SchemaOption {
 			schema.identMap = newIdentMap(schema.identMap)
 		}
 		schema.identMap.add(meaning, identifier)
+		schema.cache.clear()
 	}
 }
 
@@ -92,5 +101,456 @@ func WithIdentifier(identifier string, meaning string) SchemaOption {
 func WithKey(key string) SchemaOption {
 	return func(schema *Schema) {
 		schema.key = key
+		schema.cache.clear()
+	}
+}
+
+// WithJSONErrorContext creates an option that includes a snippet of the
+// offending JSON text in the error returned when a JSON column cannot be
+// unmarshaled. This can be helpful for debugging, but is not enabled by default
+// because the column contents could contain sensitive information that should
+// not appear in error messages or logs.
+func WithJSONErrorContext() SchemaOption {
+	return func(schema *Schema) {
+		schema.jsonErrorContext = true
+		schema.cache.clear()
+	}
+}
+
+// WithArgCoercion creates an option that converts string args to the type
+// expected by the query before they are sent to the database driver. This
+// is useful when args originate from strings, such as HTTP query parameters.
+//
+// The expected type is known for args that are compared with a column of the
+// row type, eg "where id = ?", and for args that are supplied for columns,
+// eg "select {} from users where {}". A string arg is converted if the expected
+// type is an integer, floating point or boolean type. It is an error if the
+// string cannot be converted.
+func WithArgCoercion() SchemaOption {
+	return func(schema *Schema) {
+		schema.argCoercion = true
+		schema.cache.clear()
+	}
+}
+
+// WithDefaultLimit creates an option that limits the number of rows returned
+// by select queries that do not already limit the number of rows. This is a
+// safeguard against accidentally returning a very large number of rows, for
+// example when a query is missing a where clause.
+//
+// The limit is applied using the syntax of the schema's dialect, eg "limit n"
+// for Postgres, or "top n" for MSSQL. Queries that specify a limit, including
+// a limit using a placeholder, are not modified. A value of n less than or equal
+// to zero means that there is no default limit.
+func WithDefaultLimit(n int) SchemaOption {
+	return func(schema *Schema) {
+		if n < 0 {
+			n = 0
+		}
+		schema.defaultLimit = n
+		schema.cache.clear()
+	}
+}
+
+// WithCache creates an option that sets a cache for statement metadata. The
+// schema consults the cache before parsing a query, and adds the metadata for
+// each query that it parses. Using a cache that is shared between processes
+// can reduce the work performed when a program starts.
+//
+// A schema always keeps its own in-memory cache of prepared statements, so
+// the cache is only consulted the first time a query is prepared by the schema.
+func WithCache(cache StatementCache) SchemaOption {
+	return func(schema *Schema) {
+		schema.statementCache = cache
+		schema.cache.clear()
+	}
+}
+
+// WithEncryptor creates an option that sets the encryptor used to encrypt and
+// decrypt the contents of columns whose fields have the "encrypt" struct tag.
+// Encrypted fields must be of type string or []byte.
+func WithEncryptor(encryptor ColumnEncryptor) SchemaOption {
+	return func(schema *Schema) {
+		schema.encryptor = encryptor
+		schema.cache.clear()
+	}
+}
+
+// WithActiveEncryptionKeyID creates an option that sets the ID of the key used
+// when encrypting columns. Columns are decrypted using the key ID stored with the
+// encrypted text, so changing the active key ID does not prevent existing rows from
+// being read. The active key ID takes precedence over any key ID specified in the
+// struct tag of a field, eg `sql:"encrypt_key_id:key1"`.
+func WithActiveEncryptionKeyID(keyID string) SchemaOption {
+	return func(schema *Schema) {
+		schema.encryptionKeyID = keyID
+		schema.cache.clear()
+	}
+}
+
+// WithColumnRewriter creates an option that rewrites column names using fn,
+// which is called with the name of the field (multiple field names for embedded
+// structs are joined with periods), and the column name determined by the naming
+// convention and any field mappings. The column name returned by fn is used in
+// queries.
+//
+// The column name returned by fn is remembered, so fn is called at most once for
+// each column. Passing a nil fn removes any column rewriter.
+func WithColumnRewriter(fn func(field, col string) string) SchemaOption {
+	return func(schema *Schema) {
+		if fn == nil {
+			schema.columnRewriter = nil
+		} else {
+			schema.columnRewriter = newColumnRewriter(fn)
+		}
+		schema.cache.clear()
+	}
+}
+
+// WithIdentityInsert creates an option that allows insert statements to set the
+// value of an identity (auto-increment) column explicitly when using MSSQL. SQL Server
+// does not permit this unless "set identity_insert <table> on" is in effect, so any
+// insert statement that sets the value of the auto-increment column is wrapped:
+//  set identity_insert <table> on; insert into <table> ...; set identity_insert <table> off
+// This option has no effect for dialects other than MSSQL.
+func WithIdentityInsert() SchemaOption {
+	return func(schema *Schema) {
+		schema.identityInsert = true
+		schema.cache.clear()
+	}
+}
+
+// WithDialectNegotiator creates an option that provides a custom function for
+// determining the dialect of an open DB handle, overriding the detection based
+// on the DB driver. This is useful when connections are proxied through a driver
+// that does not identify the actual database.
+//
+// The function is called when the ForDB option is applied, regardless of the
+// order in which the options are supplied. It may issue a cheap query (eg
+// "select version()") to identify the database. If fn returns nil, the dialect
+// is determined by the DB driver.
+func WithDialectNegotiator(fn func(db *sql.DB) Dialect) SchemaOption {
+	return func(schema *Schema) {
+		schema.dialectNegotiator = fn
+		if schema.dialectDB != nil {
+			schema.dialect = schema.dialectForDB(schema.dialectDB)
+		}
+		schema.cache.clear()
+	}
+}
+
+// WithAutoJSON creates an option that treats struct fields that are maps,
+// slices or arrays as JSON columns, even if they are not tagged as JSON.
+// Without this option, such fields are ignored unless they have a "json" tag,
+// eg `sql:"json"`.
+//
+// Byte slices and byte arrays are not treated as JSON, as they are binary
+// data. Neither are types that implement the sql.Scanner or driver.Valuer
+// interfaces. Slice args passed to queries (eg "where id in (?)") are not
+// affected.
+func WithAutoJSON() SchemaOption {
+	return func(schema *Schema) {
+		schema.autoJSON = true
+		schema.cache.clear()
+	}
+}
+
+// WithEmptyStringAsNull creates an option that stores all string fields (and
+// fields that are pointers to strings) as NULL when they are empty, and scans
+// NULL as an empty string. This is the same as tagging each string field with
+// "null", eg `sql:"null"`. Individual fields can opt out with the "not null"
+// tag, eg `sql:"not null"`.
+func WithEmptyStringAsNull() SchemaOption {
+	return func(schema *Schema) {
+		schema.emptyStringNull = true
+		schema.cache.clear()
+	}
+}
+
+// WithRetryableCheck creates an option that overrides the dialect's check for
+// transient errors, such as deadlocks and serialization failures, which is used
+// by the schema's IsRetryable method.
+func WithRetryableCheck(fn func(err error) bool) SchemaOption {
+	return func(schema *Schema) {
+		schema.retryableCheck = fn
+	}
+}
+
+// WithPrepareHook creates an option that calls fn each time a statement is
+// prepared, with the row type and the query. The query is as passed to Prepare,
+// except that shorthand queries such as "insert" have been expanded, eg
+// "insert into users({}) values({})". If fn returns an error, the statement is
+// not prepared and the error is returned. This can be used to check queries
+// against the conventions of a project, for example:
+//  schema := sqlr.NewSchema(sqlr.WithPrepareHook(func(rowType reflect.Type, query string) error {
+//      if strings.Contains(query, "*") {
+//          return fmt.Errorf("%s: use {} instead of *", rowType.Name())
+//      }
+//      return nil
+//  }))
+//
+// The option can be specified more than once, and the functions are called in
+// the order that they were added. All of them must succeed for the statement to
+// be prepared. A nil fn is ignored.
+func WithPrepareHook(fn func(rowType reflect.Type, query string) error) SchemaOption {
+	return func(schema *Schema) {
+		if fn != nil {
+			schema.prepareHooks = append(schema.prepareHooks, fn)
+		}
+	}
+}
+
+// WithNotificationListener creates an option that provides the listener used by
+// WatchTable to receive table change notifications from the database.
+func WithNotificationListener(listener NotificationListener) SchemaOption {
+	return func(schema *Schema) {
+		schema.notificationListener = listener
+	}
+}
+
+// WithShardKey creates an option for horizontally partitioned tables, where the
+// table for a row depends on the value of one of its fields. When a query is an
+// "insert" or "update" shorthand notation without a table name, and the row is a
+// struct (or a pointer to a struct) with the named field, the table name is obtained
+// by calling shardFn with the field's value:
+//  schema := sqlr.NewSchema(sqlr.WithShardKey("UserID", func(v interface{}) string {
+//      return fmt.Sprintf("users_%d", v.(int)%4)
+//  }))
+//
+//  // inserts into users_2
+//  _, err := schema.Exec(db, &User{UserID: 42}, "insert")
+//
+// Select shorthands are not routed, because the row passed to Select receives the
+// results, so its shard key field is not set. A "select" shorthand for a row type
+// with the shard key field returns an error. Use ShardTable to name the table:
+//  // selects from users_2
+//  _, err := schema.Select(db, &user, "select "+schema.ShardTable(42), 42)
+//
+// Statements are cached using the full sharded table name, so each shard has its own
+// prepared statement. The field name can refer to a field of an embedded struct
+// using periods, eg "Key.UserID". Slices of rows are not routed by shard key, because
+// the rows may belong to different shards.
+func WithShardKey(field string, shardFn func(value interface{}) string) SchemaOption {
+	return func(schema *Schema) {
+		if shardFn == nil {
+			schema.shardKey = nil
+			return
+		}
+		schema.shardKey = &shardKey{field: field, fn: shardFn}
+	}
+}
+
+// WithPreserveComments creates an option that determines whether comments in
+// queries are kept in the statements sent to the database server. This is
+// useful for tagging queries with information that appears in the server's
+// logs and query statistics, eg "select {} from users -- list users".
+//
+// Block comments, eg "/* list users */", are always kept, because they can
+// contain optimizer hints. Line comments, eg "-- list users", are stripped
+// unless preserve is true, in which case each line comment is converted into
+// a block comment, because the white space in the query is not preserved.
+func WithPreserveComments(preserve bool) SchemaOption {
+	return func(schema *Schema) {
+		schema.preserveComments = preserve
+		schema.cache.clear()
+	}
+}
+
+// WithIdentifierQuoting creates an option that determines when the column names
+// generated by the schema are quoted. The default, QuoteAlways, quotes every column
+// name. QuoteNever leaves column names unquoted, which makes queries easier to read
+// in logs. QuoteAuto only quotes column names that are reserved words in the
+// schema's dialect, or that contain characters other than lower case letters,
+// digits and underscores.
+//
+// Identifiers that are quoted in the query text, and names quoted by the
+// schema's QuoteIdentifier method, are always quoted.
+func WithIdentifierQuoting(mode QuoteMode) SchemaOption {
+	return func(schema *Schema) {
+		schema.quoteMode = mode
+		schema.cache.clear()
+	}
+}
+
+// WithUppercaseColumns creates an option for database drivers that return the
+// column names of query results in upper case, such as Oracle drivers. Column
+// names are matched with fields by converting both to upper case, which avoids
+// a second, case-insensitive pass the first time each statement is run.
+func WithUppercaseColumns() SchemaOption {
+	return func(schema *Schema) {
+		schema.columnCase = columnCaseUpper
+		schema.cache.clear()
+	}
+}
+
+// WithLowercaseColumns creates an option for database drivers that return the
+// column names of query results in lower case, such as Postgres drivers for
+// unquoted identifiers. Column names are matched with fields by converting both
+// to lower case, which avoids a second, case-insensitive pass the first time
+// each statement is run.
+func WithLowercaseColumns() SchemaOption {
+	return func(schema *Schema) {
+		schema.columnCase = columnCaseLower
+		schema.cache.clear()
+	}
+}
+
+// WithEnumValidation creates an option that checks the value of each field
+// tagged with "enum", eg `sql:"enum:active,inactive,pending"`, before it is
+// sent to the database. If the value is not one of the values in the tag, the
+// statement is not executed and the error is an *EnumError. Empty values of
+// fields that are stored as NULL, and nil pointers, are not checked.
+func WithEnumValidation(validate bool) SchemaOption {
+	return func(schema *Schema) {
+		schema.enumValidation = validate
+		schema.cache.clear()
+	}
+}
+
+// WithErrorOnMultipleRows creates an option for "expected exactly one" semantics
+// when selecting into a single struct. The Select and SelectOne methods return
+// ErrMultipleRows if the query returns more than one row, instead of ignoring the
+// additional rows. This catches queries where a filter that is expected to be
+// unique matches several rows.
+func WithErrorOnMultipleRows() SchemaOption {
+	return func(schema *Schema) {
+		schema.errorOnMultipleRows = true
+		schema.cache.clear()
+	}
+}
+
+// WithConventionPerTable creates an option that uses a different naming
+// convention for the columns of a particular table, for databases that mix
+// naming conventions:
+//  schema := sqlr.NewSchema(
+//      sqlr.WithNamingConvention(sqlr.SnakeCase),
+//      sqlr.WithConventionPerTable("LegacyOrders", sqlr.SameCase),
+//  )
+//
+// The table of a statement is the table that it inserts into, updates,
+// deletes from, or selects from first. Table names are matched without regard
+// to case. Field mappings from WithField take precedence over the convention.
+func WithConventionPerTable(table string, convention NamingConvention) SchemaOption {
+	return func(schema *Schema) {
+		if schema.tableConventions == nil {
+			schema.tableConventions = make(map[string]NamingConvention)
+		}
+		schema.tableConventions[strings.ToLower(table)] = convention
+		schema.cache.clear()
+	}
+}
+
+// WithQueryObserver creates an option that calls fn each time a statement is
+// executed, with the query and args sent to the database, the time taken, and
+// the result. It is intended for instrumentation, such as metrics and tracing,
+// and is called after any logger set by WithSlogLogger. The event must not be
+// retained after fn returns. Only one observer is called, and the observer is
+// removed if fn is nil.
+//
+// The ottrace and oteltrace sub-packages use WithQueryObserver to create a span
+// for each execution, using OpenTracing and OpenTelemetry respectively.
+func WithQueryObserver(fn func(event *QueryEvent)) SchemaOption {
+	return func(schema *Schema) {
+		schema.queryObserver = fn
+		schema.cache.clear()
+	}
+}
+
+// WithLazyConnect creates an option that opens a DB handle for the driver and
+// data source name the first time it is needed, instead of when the schema is
+// created. This suits programs that construct all of their objects at startup,
+// before the database is available.
+//
+// The DB handle is opened by the first call to Prepare, Exec or Select, and is
+// used by Exec and Select when they are passed a nil DB. If the connection fails,
+// the error is returned by the call, and the next call tries again. Once opened,
+// the DB handle is shared by clones of the schema. Unless the schema has a dialect,
+// the dialect is determined by the DB driver. Call Connect to fail fast at startup.
+func WithLazyConnect(driverName, dsn string) SchemaOption {
+	return func(schema *Schema) {
+		schema.lazyConnect = &lazyConnect{driverName: driverName, dsn: dsn}
+		schema.cache.clear()
+	}
+}
+
+// WithTagKeys creates an option that sets the struct tag keys that are searched,
+// in order, for the column name of a field. The first key that is present with a
+// column name is used, eg with WithTagKeys("db", "json", "sql"), the column name
+// for a field tagged `json:"user_name" sql:"name"` is "user_name". This makes it
+// possible to reuse existing tags, such as "json" tags, for column names. Without
+// this option, the keys searched are "sqlr" and then "sql".
+//
+// For keys other than "sqlr" and "sql", the column name is the tag value up to
+// the first comma, eg "user_name" in `json:"user_name,omitempty"`, and a value
+// of "-" is ignored. Only the "sql" and "sqlr" keys supply options such as
+// "primary key" and "json", and exclude fields with `sql:"-"`, regardless of the
+// order of the keys. A name in the tag key set by WithKey takes precedence over
+// all of the keys, and a field mapped with WithField takes precedence over tags.
+func WithTagKeys(keys ...string) SchemaOption {
+	return func(schema *Schema) {
+		schema.tagKeys = append([]string(nil), keys...)
+		schema.cache.clear()
+	}
+}
+
+// WithInStrategy creates an option that determines how an arg that is a slice
+// of tuples is expanded, for a tuple IN clause such as "where (a,b) in (?)".
+// Each tuple is a slice or an array of values, eg [][]int{{1, 2}, {3, 4}}.
+//
+// The default, InValues, expands the tuples as a list of row values:
+//  where (a,b) in ((?,?),(?,?))
+// InUnionSelect expands the tuples as a union of selects:
+//  where (a,b) in (select ?,? union all select ?,?)
+//
+// A list of row values is best for a small number of tuples. MySQL can be slow
+// to evaluate a long list of row values, as it may not use an index, whereas it
+// can execute the union as a semi-join against a derived table. Consider
+// InUnionSelect for MySQL and MariaDB when fetching hundreds of composite keys
+// at a time, and measure with Stmt.Explain. MSSQL supports neither form.
+func WithInStrategy(strategy InStrategy) SchemaOption {
+	return func(schema *Schema) {
+		schema.inStrategy = strategy
+		schema.cache.clear()
+	}
+}
+
+// WithTypes creates an option that registers row types with the schema. Each
+// row can be a struct, a pointer to a struct, or a slice of structs. The column
+// information for each row type is obtained when the schema is created, which
+// moves the cost of the reflection to program startup.
+//
+// A registered row type has a table name, even if it has no TableName() method,
+// so that it can be used with shorthand queries such as "insert" and "select".
+// The table name is the name of the row type converted using the naming convention,
+// and then pluralized, eg "order_lines" for OrderLine. See WithPluralizer.
+//  schema := sqlr.NewSchema(sqlr.WithTypes(User{}, Order{}, Product{}))
+//
+//  // inserts into users
+//  _, err := schema.Exec(db, &user, "insert")
+func WithTypes(rows ...interface{}) SchemaOption {
+	return func(schema *Schema) {
+		for _, row := range rows {
+			rowType, err := inferRowType(row)
+			if err != nil {
+				// not a row type, so ignore
+				continue
+			}
+			if schema.rowTypes == nil {
+				schema.rowTypes = make(map[reflect.Type]bool)
+			}
+			schema.rowTypes[rowType] = true
+		}
+		schema.cache.clear()
+	}
+}
+
+// WithPluralizer creates an option that sets the function that converts the name
+// of a row type registered with WithTypes into a table name. The function is called
+// with the name after the naming convention has been applied, eg "order_line".
+// The default is Pluralize.
+func WithPluralizer(fn func(name string) string) SchemaOption {
+	return func(schema *Schema) {
+		schema.pluralizer = fn
+		schema.cache.clear()
 	}
 }
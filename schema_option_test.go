@@ -1,9 +1,17 @@
 package sqlr
 
 import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/jjeffery/sqlr/private/column"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
 )
 
 func TestWithNamingConvention(t *testing.T) {
@@ -116,3 +124,902 @@ func TestWithKey(t *testing.T) {
 		t.Errorf("got=%q want=%q", got, want)
 	}
 }
+
+func TestWithJSONErrorContext(t *testing.T) {
+	type Row struct {
+		ID   int                    `sql:"primary key"`
+		Data map[string]interface{} `sql:"json"`
+	}
+	for _, tt := range []struct {
+		schema *Schema
+		want   bool
+	}{
+		{schema: NewSchema(), want: false},
+		{schema: NewSchema(WithJSONErrorContext()), want: true},
+		{schema: NewSchema().Clone(WithJSONErrorContext()), want: true},
+		{schema: NewSchema(WithJSONErrorContext()).Clone(), want: true},
+	} {
+		stmt, err := tt.schema.Prepare(Row{}, "select {} from rows where {}")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := stmt.jsonErrorContext, tt.want; got != want {
+			t.Errorf("want=%v, got=%v", want, got)
+		}
+	}
+}
+
+func TestWithColumnRewriter(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+		Home struct {
+			Postcode string
+		}
+	}
+	var calls int
+	rewrite := func(field, col string) string {
+		calls++
+		if field == "Home.Postcode" {
+			return "home_zip"
+		}
+		return col + "_v2"
+	}
+	schema := NewSchema(WithDialect(MySQL), WithColumnRewriter(rewrite))
+	for _, query := range []string{"select {} from rows where {}", "update rows"} {
+		if _, err := schema.Prepare(Row{}, query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stmt, err := schema.Prepare(Row{}, "select {} from rows where {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), "select `id_v2`,`name_v2`,`home_zip` from rows where `id_v2`=?"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+	if got, want := calls, 3; got != want {
+		t.Errorf("want %d calls, got %d", want, got)
+	}
+
+	// the rewriter is applied after the naming convention
+	stmt, err = schema.Clone(WithNamingConvention(SameCase)).Prepare(Row{}, "select {} from rows where {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), "select `ID_v2`,`Name_v2`,`home_zip` from rows where `ID_v2`=?"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	// remove the rewriter
+	stmt, err = schema.Clone(WithColumnRewriter(nil)).Prepare(Row{}, "select {} from rows where {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), "select `id`,`name`,`home_postcode` from rows where `id`=?"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
+
+func TestWithIdentityInsert(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key identity"`
+		Name string
+	}
+	tests := []struct {
+		schema *Schema
+		query  string
+		want   string
+	}{
+		{
+			schema: NewSchema(WithDialect(MSSQL), WithIdentityInsert()),
+			query:  "insert into users({all}) values({})",
+			want:   "set identity_insert users on; insert into users([id],[name]) values(?,?); set identity_insert users off",
+		},
+		{
+			schema: NewSchema(WithDialect(MSSQL), WithIdentityInsert()),
+			query:  "insert into [dbo].[users]({all}) values({})",
+			want:   "set identity_insert [dbo].[users] on; insert into [dbo].[users]([id],[name]) values(?,?); set identity_insert [dbo].[users] off",
+		},
+		{
+			// identity column not set explicitly
+			schema: NewSchema(WithDialect(MSSQL), WithIdentityInsert()),
+			query:  "insert users",
+			want:   "insert into users([name]) values(?)",
+		},
+		{
+			schema: NewSchema(WithDialect(MSSQL)),
+			query:  "insert into users({all}) values({})",
+			want:   "insert into users([id],[name]) values(?,?)",
+		},
+		{
+			schema: NewSchema(WithDialect(MySQL), WithIdentityInsert()),
+			query:  "insert into users({all}) values({})",
+			want:   "insert into users(`id`,`name`) values(?,?)",
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := tt.schema.Prepare(Row{}, tt.query)
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		if got := stmt.String(); got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+
+	// the statement is wrapped whenever it is rendered
+	cache := NewInMemoryCache(0)
+	schema := NewSchema(WithDialect(MSSQL), WithIdentityInsert(), WithCache(cache))
+	want := tests[0].want
+	for i := 0; i < 2; i++ {
+		// the second time the statement is created from the cached metadata
+		stmt, err := schema.Clone().Prepare(Row{}, tests[0].query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := stmt.String(); got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		variant, err := stmt.ForDialect(MSSQL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := variant.String(); got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		if variant, err = stmt.WithPlaceholderOffset(0); err != nil {
+			t.Fatal(err)
+		}
+		if got := variant.String(); got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func TestWithDialectNegotiator(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	negotiator := func(db *sql.DB) Dialect {
+		var version string
+		if err := db.QueryRow("select version()").Scan(&version); err != nil {
+			t.Errorf("cannot query version: %v", err)
+			return nil
+		}
+		if strings.HasPrefix(version, "PostgreSQL") {
+			return Postgres
+		}
+		return nil
+	}
+
+	tests := []struct {
+		opts    []SchemaOption
+		version string
+		want    Dialect
+	}{
+		{
+			opts:    []SchemaOption{WithDialectNegotiator(negotiator), ForDB(db)},
+			version: "PostgreSQL 9.6.2",
+			want:    Postgres,
+		},
+		{
+			// order of options does not matter
+			opts:    []SchemaOption{ForDB(db), WithDialectNegotiator(negotiator)},
+			version: "PostgreSQL 10.1",
+			want:    Postgres,
+		},
+		{
+			// negotiator returns nil, so dialect is determined by driver
+			opts:    []SchemaOption{WithDialectNegotiator(negotiator), ForDB(db)},
+			version: "5.7.19",
+			want:    dialectFor(db),
+		},
+	}
+	for i, tt := range tests {
+		mock.ExpectQuery(regexp.QuoteMeta("select version()")).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(tt.version))
+		schema := NewSchema(tt.opts...)
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%d: %v", i, err)
+		}
+		if got, want := schema.getDialect(), tt.want; got != want {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+	}
+
+	// negotiator is not called without a DB handle
+	schema := NewSchema(WithDialect(MySQL), WithDialectNegotiator(negotiator))
+	if got, want := schema.Clone().getDialect(), MySQL; got != want {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+}
+
+// countingConvention is a naming convention that is not safe for
+// concurrent use, because it counts the calls to Convert without a lock.
+type countingConvention struct {
+	calls map[string]int
+}
+
+func (c *countingConvention) Convert(fieldName string) string {
+	c.calls[fieldName]++
+	return SnakeCase.Convert(fieldName)
+}
+
+func (c *countingConvention) Join(names []string) string {
+	return SnakeCase.Join(names)
+}
+
+func TestNamingConventionConcurrency(t *testing.T) {
+	type Row struct {
+		ID        int `sql:"primary key"`
+		FullName  string
+		UpdatedAt time.Time
+	}
+	convention := &countingConvention{calls: make(map[string]int)}
+	conventions := []NamingConvention{SnakeCase, SameCase, LowerCase, convention}
+	for _, c := range conventions {
+		schema := NewSchema(WithDialect(MySQL), WithNamingConvention(c))
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				query := fmt.Sprintf("select {} from rows where {} and %d = %d", i, i)
+				if _, err := schema.Prepare(Row{}, query); err != nil {
+					t.Error(err)
+				}
+			}(i)
+		}
+		wg.Wait()
+	}
+	for fieldName, n := range convention.calls {
+		if n != 1 {
+			t.Errorf("%s: want 1 call, got %d", fieldName, n)
+		}
+	}
+}
+
+func TestTableNameConcurrency(t *testing.T) {
+	type Widget struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	convention := &countingConvention{calls: make(map[string]int)}
+	schema := NewSchema(WithDialect(MySQL), WithNamingConvention(convention), WithTypes(Widget{}))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			query := fmt.Sprintf("select {} from widgets where {} and %d = %d", i, i)
+			if _, err := schema.Prepare(Widget{}, query); err != nil {
+				t.Error(err)
+			}
+			if _, err := schema.Prepare(Widget{}, "insert"); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got, want := convention.calls["Widget"], 1; got != want {
+		t.Errorf("want=%d calls, got=%d", want, got)
+	}
+}
+
+func TestWithAutoJSON(t *testing.T) {
+	type Row struct {
+		ID    int `sql:"primary key"`
+		Tags  []string
+		Attrs map[string]int
+		Data  []byte
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// without the option, maps and slices are ignored
+	stmt, err := NewSchema(WithDialect(MySQL)).Prepare(Row{}, "insert rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), "insert into rows(`id`,`data`) values(?,?)"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	schema := NewSchema(WithDialect(MySQL), WithAutoJSON())
+	mock.ExpectExec(regexp.QuoteMeta("insert into rows(`id`,`tags`,`attrs`,`data`) values(?,?,?,?)")).
+		WithArgs(1, []byte(`["a","b"]`), []byte(`{"x":1}`), []byte("bin")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	row := Row{ID: 1, Tags: []string{"a", "b"}, Attrs: map[string]int{"x": 1}, Data: []byte("bin")}
+	if _, err := schema.Exec(db, &row, "insert rows"); err != nil {
+		t.Fatal(err)
+	}
+
+	// slice args are still expanded for "in" clauses
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`tags`,`attrs`,`data` from rows where id in (?,?)")).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tags", "attrs", "data"}).
+			AddRow(1, []byte(`["c"]`), []byte(`{"y":2}`), []byte("bin")))
+	var rows []Row
+	if _, err := schema.Select(db, &rows, "select {} from rows where id in (?)", []int{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(rows), "[{1 [c] map[y:2] [98 105 110]}]"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithEmptyStringAsNull(t *testing.T) {
+	type Row struct {
+		ID       int `sql:"primary key"`
+		Name     string
+		Nickname *string
+		Code     string `sql:"not null"`
+		Count    int
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL), WithEmptyStringAsNull())
+
+	empty := ""
+	mock.ExpectExec(regexp.QuoteMeta("insert into rows(`id`,`name`,`nickname`,`code`,`count`) values(?,?,?,?,?)")).
+		WithArgs(1, nil, nil, "", 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	row := Row{ID: 1, Nickname: &empty}
+	if _, err := schema.Exec(db, &row, "insert rows"); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name`,`nickname`,`code`,`count` from rows where `id`=?")).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "nickname", "code", "count"}).
+			AddRow(2, nil, nil, "x", 3))
+	row = Row{Name: "previous"}
+	if _, err := schema.Select(db, &row, "select {} from rows where {}", 2); err != nil {
+		t.Fatal(err)
+	}
+	if row.Name != "" || row.Nickname != nil || row.Code != "x" {
+		t.Errorf("unexpected row: %+v", row)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithPreserveComments(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	tests := []struct {
+		schema *Schema
+		query  string
+		want   string
+	}{
+		{
+			schema: NewSchema(WithDialect(MySQL)),
+			query:  "select {} from users -- list users\nwhere {}",
+			want:   "select `id`,`name` from users where `id`=?",
+		},
+		{
+			schema: NewSchema(WithDialect(MySQL), WithPreserveComments(true)),
+			query:  "select {} from users -- list users\nwhere {}",
+			want:   "select `id`,`name` from users /* list users */ where `id`=?",
+		},
+		{
+			schema: NewSchema(WithDialect(MySQL), WithPreserveComments(true)),
+			query:  "select {} from users where {} -- a */ b",
+			want:   "select `id`,`name` from users where `id`=? /* a * / b */",
+		},
+		{
+			schema: NewSchema(WithDialect(MySQL)),
+			query:  "select /*+ MAX_EXECUTION_TIME(1000) */ {} from users",
+			want:   "select /*+ MAX_EXECUTION_TIME(1000) */ `id`,`name` from users",
+		},
+		{
+			schema: NewSchema(WithDialect(Postgres), WithPreserveComments(true)),
+			query:  "/* app:users */ update users set {} where {}",
+			want:   `/* app:users */ update users set "name"=$1 where "id"=$2`,
+		},
+		{
+			schema: NewSchema(WithDialect(MySQL), WithPreserveComments(true), WithPreserveComments(false)),
+			query:  "select {} from users -- list users",
+			want:   "select `id`,`name` from users",
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := tt.schema.Prepare(Row{}, tt.query)
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		if got := stmt.String(); got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+}
+
+func TestWithIdentifierQuoting(t *testing.T) {
+	type Row struct {
+		ID      int `sql:"primary key"`
+		Name    string
+		Order   int
+		Key     string
+		Address string `sql:"Address"`
+	}
+	tests := []struct {
+		schema *Schema
+		want   string
+	}{
+		{
+			schema: NewSchema(WithDialect(MySQL)),
+			want:   "update users set `name`=?,`order`=?,`key`=?,`Address`=? where `id`=?",
+		},
+		{
+			schema: NewSchema(WithDialect(MySQL), WithIdentifierQuoting(QuoteNever)),
+			want:   "update users set name=?,order=?,key=?,Address=? where id=?",
+		},
+		{
+			schema: NewSchema(WithDialect(MySQL), WithIdentifierQuoting(QuoteAuto)),
+			want:   "update users set name=?,`order`=?,`key`=?,`Address`=? where id=?",
+		},
+		{
+			schema: NewSchema(WithDialect(Postgres), WithIdentifierQuoting(QuoteAuto)),
+			want:   `update users set name=$1,"order"=$2,key=$3,"Address"=$4 where id=$5`,
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := tt.schema.Prepare(Row{}, "update users set {} where {}")
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		if got := stmt.String(); got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+
+	// quoted identifiers in the query are always quoted
+	schema := NewSchema(WithDialect(MySQL), WithIdentifierQuoting(QuoteNever))
+	stmt, err := schema.Prepare(Row{}, "select {} from users where `key` = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), "select id,name,order,key,Address from users where `key` = ?"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
+
+func TestWithColumnCase(t *testing.T) {
+	type Row struct {
+		ID       int `sql:"primary key"`
+		Name     string
+		HomeCity string
+	}
+	tests := []struct {
+		schema  *Schema
+		columns []string
+	}{
+		{
+			schema:  NewSchema(WithDialect(MySQL), WithUppercaseColumns()),
+			columns: []string{"ID", "NAME", "HOME_CITY"},
+		},
+		{
+			schema:  NewSchema(WithDialect(MySQL), WithLowercaseColumns()),
+			columns: []string{"id", "name", "home_city"},
+		},
+		{
+			// other cases still match
+			schema:  NewSchema(WithDialect(MySQL), WithUppercaseColumns()),
+			columns: []string{"id", "Name", "HOME_CITY"},
+		},
+	}
+	for i, tt := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name`,`home_city` from rows")).
+			WillReturnRows(sqlmock.NewRows(tt.columns).AddRow(1, "Alice", "Sydney"))
+		var rows []Row
+		if _, err := tt.schema.Select(db, &rows, "select {} from rows"); err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+		} else if len(rows) != 1 || rows[0] != (Row{ID: 1, Name: "Alice", HomeCity: "Sydney"}) {
+			t.Errorf("%d: unexpected rows: %+v", i, rows)
+		}
+		db.Close()
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name`,`home_city` from rows")).
+		WillReturnRows(sqlmock.NewRows([]string{"ID", "NAME", "CITY"}).AddRow(1, "Alice", "Sydney"))
+	var rows []Row
+	_, err = NewSchema(WithDialect(MySQL), WithUppercaseColumns()).Select(db, &rows, "select {} from rows")
+	if got, want := fmt.Sprint(err), `unknown column name="CITY"`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
+
+func TestWithErrorOnMultipleRows(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	query := regexp.QuoteMeta("select `id`,`name` from rows where name = ?")
+	twoRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "x").AddRow(2, "x")
+	}
+	oneRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "y")
+	}
+	selectSQL := "select {} from rows where name = ?"
+
+	// without the option, additional rows are ignored
+	schema := NewSchema(WithDialect(MySQL))
+	mock.ExpectQuery(query).WillReturnRows(twoRows())
+	var row Row
+	n, err := schema.Select(db, &row, selectSQL, "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || row.ID != 1 {
+		t.Errorf("want n=2, ID=1, got n=%d, ID=%d", n, row.ID)
+	}
+
+	schema = schema.Clone(WithErrorOnMultipleRows())
+	row = Row{}
+	mock.ExpectQuery(query).WillReturnRows(twoRows())
+	n, err = schema.Select(db, &row, selectSQL, "x")
+	if got, want := err, ErrMultipleRows; got != want {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+	if n != 2 || row.ID != 0 {
+		t.Errorf("want n=2, ID=0, got n=%d, ID=%d", n, row.ID)
+	}
+
+	mock.ExpectQuery(query).WillReturnRows(twoRows())
+	if got, want := schema.SelectOne(db, &row, selectSQL, "x"), ErrMultipleRows; got != want {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+	if row.ID != 0 {
+		t.Errorf("want row not modified, got ID=%d", row.ID)
+	}
+
+	// exactly one row
+	mock.ExpectQuery(query).WillReturnRows(oneRow())
+	if n, err = schema.Select(db, &row, selectSQL, "y"); err != nil || n != 1 || row.ID != 3 {
+		t.Errorf("want n=1, ID=3, got n=%d, ID=%d, err=%v", n, row.ID, err)
+	}
+	row = Row{}
+	mock.ExpectQuery(query).WillReturnRows(oneRow())
+	if err := schema.SelectOne(db, &row, selectSQL, "y"); err != nil || row.ID != 3 {
+		t.Errorf("want ID=3, got ID=%d, err=%v", row.ID, err)
+	}
+
+	// slices are not affected
+	var rows []Row
+	mock.ExpectQuery(query).WillReturnRows(twoRows())
+	if n, err = schema.Select(db, &rows, selectSQL, "x"); err != nil || n != 2 {
+		t.Errorf("want n=2, got n=%d, err=%v", n, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithConventionPerTable(t *testing.T) {
+	type Row struct {
+		OrderID   int `sql:"primary key"`
+		OrderDate string
+	}
+	schema := NewSchema(
+		WithDialect(MySQL),
+		WithConventionPerTable("LegacyOrders", SameCase),
+	)
+	clone := schema.Clone(WithConventionPerTable("archive.orders", LowerCase))
+	tests := []struct {
+		schema *Schema
+		query  string
+		want   string
+	}{
+		{
+			schema: schema,
+			query:  "insert orders",
+			want:   "insert into orders(`order_id`,`order_date`) values(?,?)",
+		},
+		{
+			schema: schema,
+			query:  "insert LegacyOrders",
+			want:   "insert into LegacyOrders(`OrderID`,`OrderDate`) values(?,?)",
+		},
+		{
+			schema: schema,
+			query:  "update legacyorders set {} where {}",
+			want:   "update legacyorders set `OrderDate`=? where `OrderID`=?",
+		},
+		{
+			schema: schema,
+			query:  "select {alias o} from dbo.LegacyOrders o where {alias o}",
+			want:   "select o.`OrderID`,o.`OrderDate` from dbo.LegacyOrders o where o.`OrderID`=?",
+		},
+		{
+			schema: schema,
+			query:  "delete from archive.orders where {}",
+			want:   "delete from archive.orders where `order_id`=?",
+		},
+		{
+			schema: clone,
+			query:  "delete from archive.orders where {}",
+			want:   "delete from archive.orders where `orderid`=?",
+		},
+		{
+			schema: clone,
+			query:  "insert LegacyOrders",
+			want:   "insert into LegacyOrders(`OrderID`,`OrderDate`) values(?,?)",
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := tt.schema.Prepare(Row{}, tt.query)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := stmt.String(), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func TestWithTagKeys(t *testing.T) {
+	type Row struct {
+		ID        int    `json:"id" sql:"primary key"`
+		UserName  string `db:"login" json:"username,omitempty"`
+		Email     string `json:"email_address" sql:"email"`
+		Password  string `json:"-"`
+		CreatedAt string `json:",omitempty" sql:"created immutable"`
+	}
+	tests := []struct {
+		schema *Schema
+		want   string
+	}{
+		{
+			schema: NewSchema(WithDialect(Postgres)),
+			want:   `insert into users("id","user_name","email","password","created") values($1,$2,$3,$4,$5)`,
+		},
+		{
+			schema: NewSchema(WithDialect(Postgres), WithTagKeys("db", "json", "sql")),
+			want:   `insert into users("id","login","email_address","password","created") values($1,$2,$3,$4,$5)`,
+		},
+		{
+			schema: NewSchema(WithDialect(Postgres), WithTagKeys("sql", "json")),
+			want:   `insert into users("id","username","email","password","created") values($1,$2,$3,$4,$5)`,
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := tt.schema.Prepare(Row{}, "insert into users({}) values({})")
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := stmt.String(), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func TestStatementTable(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"insert into users({}) values({})", "users"},
+		{"update `users` set {} where {}", "users"},
+		{"delete from dbo.users where {}", "dbo.users"},
+		{"select {} from users u inner join roles r on r.id = u.role_id", "users"},
+		{`select {} from "tenant"."users" where {}`, "tenant.users"},
+		{"select 1", ""},
+	}
+	for i, tt := range tests {
+		if got, want := statementTable(tt.query), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func TestWithQueryObserver(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var events []QueryEvent
+	schema := NewSchema(WithDialect(MySQL), WithQueryObserver(func(event *QueryEvent) {
+		events = append(events, *event)
+	}))
+
+	mock.ExpectExec(regexp.QuoteMeta("insert into rows(`id`,`name`) values(?,?)")).
+		WithArgs(1, "x").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name` from rows")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "x").AddRow(2, "y"))
+	if _, err := schema.Exec(db, &Row{ID: 1, Name: "x"}, "insert into rows({}) values({})"); err != nil {
+		t.Fatal(err)
+	}
+	var rows []Row
+	if _, err := schema.Select(db, &rows, "select {} from rows"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []QueryEvent{
+		{Dialect: "mysql", Op: "insert", Query: "insert into rows(`id`,`name`) values(?,?)", Rows: 1},
+		{Dialect: "mysql", Op: "select", Query: "select `id`,`name` from rows", Rows: 2},
+	}
+	if got, want := len(events), len(want); got != want {
+		t.Fatalf("want=%d, got=%d", want, got)
+	}
+	for i, event := range events {
+		if event.Dialect != want[i].Dialect || event.Op != want[i].Op || event.Query != want[i].Query ||
+			event.Rows != want[i].Rows || event.Err != nil || event.Start.IsZero() {
+			t.Errorf("%d: want=%+v, got=%+v", i, want[i], event)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithInStrategy(t *testing.T) {
+	type Row struct {
+		OrderID int `sql:"primary key"`
+		LineNo  int `sql:"primary key"`
+		Qty     int
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	keys := [][2]int{{1, 1}, {1, 2}, {2, 1}}
+	tests := []struct {
+		schema *Schema
+		query  string
+	}{
+		{
+			schema: NewSchema(WithDialect(MySQL)),
+			query:  "select `order_id`,`line_no`,`qty` from order_lines where (order_id,line_no) in ((?,?),(?,?),(?,?))",
+		},
+		{
+			schema: NewSchema(WithDialect(MySQL), WithInStrategy(InUnionSelect)),
+			query:  "select `order_id`,`line_no`,`qty` from order_lines where (order_id,line_no) in (select ?,? union all select ?,? union all select ?,?)",
+		},
+	}
+	for i, tt := range tests {
+		mock.ExpectQuery(regexp.QuoteMeta(tt.query)).
+			WithArgs(1, 1, 1, 2, 2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "line_no", "qty"}).AddRow(1, 2, 5))
+		var rows []Row
+		n, err := tt.schema.Select(db, &rows, "select {} from order_lines where (order_id,line_no) in (?)", keys)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := n, 1; got != want {
+			t.Errorf("%d: want=%d, got=%d", i, want, got)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithPrepareHook(t *testing.T) {
+	type User struct {
+		ID       int `sql:"primary key"`
+		TenantID int
+		Name     string
+	}
+	var calls []string
+	noStar := func(rowType reflect.Type, query string) error {
+		calls = append(calls, "noStar")
+		if strings.Contains(query, "*") {
+			return fmt.Errorf("%s: use {} instead of *", rowType.Name())
+		}
+		return nil
+	}
+	tenant := func(rowType reflect.Type, query string) error {
+		calls = append(calls, "tenant")
+		if strings.HasPrefix(query, "select") && !strings.Contains(query, "tenant_id") {
+			return fmt.Errorf("%s: query must filter by tenant_id", rowType.Name())
+		}
+		return nil
+	}
+	schema := NewSchema(WithDialect(Postgres), WithPrepareHook(noStar), WithPrepareHook(tenant), WithPrepareHook(nil))
+
+	tests := []struct {
+		query     string
+		wantCalls string
+		wantErr   string
+	}{
+		{
+			query:     "select {} from users where tenant_id = ?",
+			wantCalls: "noStar,tenant",
+		},
+		{
+			// hooks are called even when the statement is cached
+			query:     "select {} from users where tenant_id = ?",
+			wantCalls: "noStar,tenant",
+		},
+		{
+			query:     "select * from users where tenant_id = ?",
+			wantCalls: "noStar",
+			wantErr:   "User: use {} instead of *",
+		},
+		{
+			query:     "select {} from users",
+			wantCalls: "noStar,tenant",
+			wantErr:   "User: query must filter by tenant_id",
+		},
+		{
+			query:     "insert users",
+			wantCalls: "noStar,tenant",
+		},
+	}
+	for i, tt := range tests {
+		calls = nil
+		_, err := schema.Prepare(User{}, tt.query)
+		if got, want := strings.Join(calls, ","), tt.wantCalls; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%d: want=%q, got=%v", i, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+		}
+	}
+
+	// a clone keeps the hooks, and can add to them
+	calls = nil
+	clone := schema.Clone(WithPrepareHook(func(rowType reflect.Type, query string) error {
+		calls = append(calls, "clone")
+		return nil
+	}))
+	if _, err := clone.Prepare(User{}, "select {} from users where tenant_id = ?"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(calls, ","), "noStar,tenant,clone"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
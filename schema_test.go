@@ -1 +1,246 @@
 package sqlr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestColumnsSQL(t *testing.T) {
+	type UserRow struct {
+		ID         int `sql:"primary key"`
+		GivenName  string
+		FamilyName string
+	}
+	tests := []struct {
+		schema  *Schema
+		row     interface{}
+		filter  []string
+		want    string
+		errText string
+	}{
+		{
+			schema: NewSchema(WithDialect(Postgres)),
+			row:    UserRow{},
+			want:   `"id","given_name","family_name"`,
+		},
+		{
+			schema: NewSchema(WithDialect(MySQL)),
+			row:    []*UserRow{},
+			filter: []string{"nopk"},
+			want:   "`given_name`,`family_name`",
+		},
+		{
+			schema: NewSchema(WithDialect(MySQL)),
+			row:    &UserRow{},
+			filter: []string{"{pk, alias u}"},
+			want:   "u.`id`",
+		},
+		{
+			schema: NewSchema(WithDialect(MSSQL), WithNamingConvention(SameCase)),
+			row:    UserRow{},
+			filter: []string{"alias", "u"},
+			want:   "u.[ID],u.[GivenName],u.[FamilyName]",
+		},
+		{
+			schema:  NewSchema(),
+			row:     1,
+			errText: "expected arg to refer to a struct type",
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := tt.schema.ColumnsSQL(tt.row, tt.filter...)
+		if err != nil {
+			if got, want := err.Error(), tt.errText; got != want {
+				t.Errorf("%d: want=%q, got=%q", i, want, got)
+			}
+			continue
+		}
+		if got, want := got, tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+type tableNameRow struct {
+	ID   int `sql:"primary key"`
+	Name string
+}
+
+func (tableNameRow) TableName() string { return "table_name_rows" }
+
+type tableNamePtrRow struct {
+	ID int `sql:"primary key"`
+}
+
+func (*tableNamePtrRow) TableName() string { return "table_name_ptr_rows" }
+
+func TestPrepareTableName(t *testing.T) {
+	type noTableNameRow struct {
+		ID int `sql:"primary key"`
+	}
+	tests := []struct {
+		row     interface{}
+		query   string
+		want    string
+		errText string
+	}{
+		{
+			row:   tableNameRow{},
+			query: "insert",
+			want:  "insert into table_name_rows(`id`,`name`) values(?,?)",
+		},
+		{
+			row:   &tableNameRow{},
+			query: "update",
+			want:  "update table_name_rows set `name`=? where `id`=?",
+		},
+		{
+			row:   []tableNameRow{},
+			query: "select from",
+			want:  "select `id`,`name` from table_name_rows where `id`=?",
+		},
+		{
+			row:   tableNamePtrRow{},
+			query: "select",
+			want:  "select `id` from table_name_ptr_rows where `id`=?",
+		},
+		{
+			// explicit table name takes precedence
+			row:   tableNameRow{},
+			query: "insert other_rows",
+			want:  "insert into other_rows(`id`,`name`) values(?,?)",
+		},
+		{
+			row:     noTableNameRow{},
+			query:   "insert into",
+			errText: `missing table name in "insert into": row type does not implement TableName() string`,
+		},
+	}
+	schema := NewSchema(WithDialect(MySQL))
+	for i, tt := range tests {
+		stmt, err := schema.Prepare(tt.row, tt.query)
+		if tt.errText != "" {
+			if err == nil {
+				t.Errorf("%d: want error %q, got nil", i, tt.errText)
+			} else if got := err.Error(); got != tt.errText {
+				t.Errorf("%d: want=%q, got=%q", i, tt.errText, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		if got := stmt.String(); got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+}
+
+func TestUpdateMany(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL))
+	rows := []*tableNameRow{
+		{ID: 1, Name: "one"},
+		{ID: 2, Name: "two"},
+		{ID: 3, Name: "three"},
+	}
+	query := regexp.QuoteMeta("update table_name_rows set `name`=? where `id`=?")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(query).WithArgs("one", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs("two", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(query).WithArgs("three", 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	counts, err := schema.UpdateMany(db, rows, "update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(counts), "[1 0 1]"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(query).WithArgs("one", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs("two", 2).WillReturnError(errors.New("deadlock"))
+	mock.ExpectRollback()
+
+	counts, err = schema.UpdateMany(db, rows, "update")
+	if err == nil {
+		t.Fatal("want error, got nil")
+	}
+	if got, want := err.Error(), "cannot update row 1: deadlock"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+	if counts != nil {
+		t.Errorf("want nil counts, got %v", counts)
+	}
+
+	if _, err := schema.UpdateMany(db, rows, "select"); err == nil {
+		t.Error("want error for select statement, got nil")
+	}
+	if _, err := schema.UpdateMany(db, rows[0], "update"); err == nil {
+		t.Error("want error for non-slice rows, got nil")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClearTypeCache(t *testing.T) {
+	schema := NewSchema(WithDialect(MySQL))
+	stmt1, err := schema.Prepare(tableNameRow{}, "select")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.TypeCacheLen() == 0 {
+		t.Error("want non-empty type cache")
+	}
+
+	schema.ClearTypeCache()
+	if got, want := schema.TypeCacheLen(), 0; got != want {
+		t.Errorf("want=%d, got=%d", want, got)
+	}
+	stmt2, err := schema.Prepare(tableNameRow{}, "select")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt1 == stmt2 {
+		t.Error("want new statement after clearing cache")
+	}
+	if got, want := stmt2.String(), stmt1.String(); got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
+
+func TestEmbeddedPrefix(t *testing.T) {
+	type Address struct {
+		Street   string
+		Locality string
+	}
+	type Row struct {
+		ID          int     `sql:"primary key"`
+		HomeAddress Address `sql:"embedded_prefix:home_"`
+		WorkAddress Address `sql:"embedded_prefix:work_"`
+	}
+	schema := NewSchema(WithDialect(MySQL))
+	stmt, err := schema.Prepare(Row{}, "insert rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "insert into rows(`id`,`home_street`,`home_locality`,`work_street`,`work_locality`) values(?,?,?,?,?)"
+	if got := stmt.String(); got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
@@ -0,0 +1,114 @@
+package sqlr
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jjeffery/sqlr/private/column"
+)
+
+// SelectAs executes a SELECT query and stores the result in rows, in the same
+// way as Select. The aliases map column names returned by the query to the names
+// of fields in the row type, so that an ad hoc query can use column aliases that
+// do not follow the naming convention:
+//  n, err := schema.SelectAs(db, &users, map[string]string{"full_name": "Name"},
+//      "select id, first_name || ' ' || last_name as full_name from users")
+//
+// Column names in aliases are matched before the column names of the fields,
+// ignoring letter case if there is no exact match. Fields of embedded structs
+// are named by their field names joined by periods, eg "HomeAddress.Street".
+// Columns that are not in aliases are matched to fields using the naming
+// convention. Aliased fields are scanned in the same way as other fields, so a
+// field tagged "json" is unmarshaled from an aliased column, including in a
+// select that joins several tables.
+func (s *Schema) SelectAs(db DB, rows interface{}, aliases map[string]string, sql string, args ...interface{}) (int, error) {
+	stmt, err := s.Prepare(rows, sql)
+	if err != nil {
+		return 0, err
+	}
+	return stmt.SelectAs(db, rows, aliases, args...)
+}
+
+// SelectAs executes the prepared query statement with the given arguments and
+// returns the query results in rows, in the same way as Select. The aliases map
+// column names returned by the query to the names of fields in the row type,
+// and take precedence over the column names of the fields. See Schema.SelectAs.
+func (stmt *Stmt) SelectAs(db DB, rows interface{}, aliases map[string]string, args ...interface{}) (int, error) {
+	if len(aliases) > 0 {
+		variant, err := stmt.aliasing(aliases)
+		if err != nil {
+			return 0, err
+		}
+		stmt = variant
+	}
+	return stmt.Select(db, rows, args...)
+}
+
+// aliasing returns a variant of the select statement that matches result
+// columns to fields using the aliases, before the column names of the fields.
+// Variants are prepared once and remembered, so that the output columns are
+// only worked out once for each set of aliases.
+func (stmt *Stmt) aliasing(aliases map[string]string) (*Stmt, error) {
+	if stmt.queryType != querySelect {
+		return nil, errors.New("cannot use column aliases: not a select statement")
+	}
+	pairs := make([]string, 0, len(aliases))
+	copied := make(map[string]string, len(aliases))
+	for columnName, fieldName := range aliases {
+		if stmt.fieldColumn(fieldName) == nil {
+			return nil, fmt.Errorf("cannot use column alias %q: unknown field %q", columnName, fieldName)
+		}
+		pairs = append(pairs, columnName+"="+fieldName)
+		copied[columnName] = fieldName
+	}
+	sort.Strings(pairs)
+	// the prefix distinguishes the key from the keys of omitting variants
+	key := "as:" + strings.Join(pairs, ",")
+
+	stmt.variants.mutex.Lock()
+	defer stmt.variants.mutex.Unlock()
+	if variant, ok := stmt.variants.stmts[key]; ok {
+		return variant, nil
+	}
+
+	variant := stmt.clone()
+	variant.aliases = copied
+
+	if stmt.variants.stmts == nil {
+		stmt.variants.stmts = make(map[string]*Stmt)
+	}
+	stmt.variants.stmts[key] = variant
+	return variant, nil
+}
+
+// fieldColumn returns the column for the named field, or nil if the
+// statement's row type does not have a column for the field.
+func (stmt *Stmt) fieldColumn(fieldName string) *column.Info {
+	for _, col := range stmt.columns {
+		if col.FieldNames == fieldName {
+			return col
+		}
+	}
+	return nil
+}
+
+// aliasColumn returns the column for the field that the result column is an
+// alias for, or nil if the result column is not in the aliases. The letter
+// case of the result column name is ignored if there is no exact match, as some
+// drivers change the case of column aliases, eg to upper case.
+func (stmt *Stmt) aliasColumn(columnName string) *column.Info {
+	if len(stmt.aliases) == 0 {
+		return nil
+	}
+	if fieldName, ok := stmt.aliases[columnName]; ok {
+		return stmt.fieldColumn(fieldName)
+	}
+	for alias, fieldName := range stmt.aliases {
+		if strings.EqualFold(alias, columnName) {
+			return stmt.fieldColumn(fieldName)
+		}
+	}
+	return nil
+}
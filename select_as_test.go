@@ -0,0 +1,122 @@
+package sqlr
+
+import (
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestSelectAs(t *testing.T) {
+	type Address struct {
+		Suburb string
+	}
+	type User struct {
+		ID          int `sql:"primary key"`
+		Name        string
+		HomeAddress Address
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	const query = "select id, first_name || ' ' || last_name as full_name, suburb as home from users"
+	aliases := map[string]string{"full_name": "Name", "home": "HomeAddress.Suburb"}
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "full_name", "home"}).
+				AddRow(1, "Alice Smith", "Ashgrove").
+				AddRow(2, "Bob Jones", "Bardon"))
+
+		var users []User
+		n, err := schema.SelectAs(db, &users, aliases, query)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if got, want := n, 2; got != want {
+			t.Errorf("%d: want=%d, got=%d", i, want, got)
+		}
+		if got, want := users[1], (User{ID: 2, Name: "Bob Jones", HomeAddress: Address{Suburb: "Bardon"}}); got != want {
+			t.Errorf("%d: want=%+v, got=%+v", i, want, got)
+		}
+	}
+
+	// the aliases do not affect the statement without aliases
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "full_name", "home"}).AddRow(1, "Alice Smith", "Ashgrove"))
+	var users []User
+	if _, err := schema.Select(db, &users, query); err == nil {
+		t.Error("want error for unknown columns, got nil")
+	}
+
+	var user User
+	_, err = schema.SelectAs(db, &user, map[string]string{"full_name": "FullName"}, query)
+	if want := `cannot use column alias "full_name": unknown field "FullName"`; err == nil || err.Error() != want {
+		t.Errorf("want=%q, got=%v", want, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSelectAsJSONJoin(t *testing.T) {
+	type Prefs struct {
+		Theme string
+	}
+	type User struct {
+		ID    int `sql:"primary key"`
+		Name  string
+		Prefs Prefs `sql:"json"`
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	tests := []struct {
+		query   string
+		columns []string
+	}{
+		{
+			query:   "select u.id, u.name, s.data as settings from users u join settings s on s.user_id = u.id",
+			columns: []string{"id", "name", "settings"},
+		},
+		{
+			// some drivers return column aliases in upper case
+			query:   "select u.id, u.name, s.data as settings from users u left join settings s on s.user_id = u.id",
+			columns: []string{"ID", "NAME", "SETTINGS"},
+		},
+	}
+	for i, tt := range tests {
+		mock.ExpectQuery(regexp.QuoteMeta(tt.query)).
+			WillReturnRows(sqlmock.NewRows(tt.columns).
+				AddRow(1, "Alice", []byte(`{"Theme":"dark"}`)).
+				AddRow(2, "Bob", nil))
+		var users []User
+		if _, err := schema.SelectAs(db, &users, map[string]string{"settings": "Prefs"}, tt.query); err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		want := []User{
+			{ID: 1, Name: "Alice", Prefs: Prefs{Theme: "dark"}},
+			{ID: 2, Name: "Bob"},
+		}
+		if len(users) != len(want) {
+			t.Errorf("%d: want=%d rows, got=%d", i, len(want), len(users))
+			continue
+		}
+		for j := range want {
+			if users[j] != want[j] {
+				t.Errorf("%d/%d: want=%+v, got=%+v", i, j, want[j], users[j])
+			}
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,122 @@
+package sqlr
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+// SelectBuilder builds a select statement for a row type without writing
+// the SQL by hand. It is intended for simple queries: anything more complex
+// is better written as an SQL string and passed to Prepare.
+//
+// Each method returns the builder, so that calls can be chained:
+//  stmt, err := schema.SelectBuilder(User{}).
+//      Where("name like ?").
+//      OrderBy("name").
+//      Limit(10).
+//      Build()
+//  n, err := stmt.Select(db, &users, "A%")
+type SelectBuilder struct {
+	schema  *Schema
+	row     interface{}
+	table   string
+	where   []string
+	orderBy []string
+	limit   int
+}
+
+// SelectBuilder returns a builder for a select statement that returns rows of
+// the same type as row. The row argument can be a struct, a pointer to a struct,
+// or a slice of structs.
+//
+// The table name is obtained from the row type's TableName() method if it
+// has one, otherwise it is the name of the row type converted using the
+// schema's naming convention. Call From to specify a different table name.
+func (s *Schema) SelectBuilder(row interface{}) *SelectBuilder {
+	return &SelectBuilder{
+		schema: s,
+		row:    row,
+	}
+}
+
+// From sets the table name.
+func (b *SelectBuilder) From(table string) *SelectBuilder {
+	b.table = table
+	return b
+}
+
+// Where adds a condition to the where clause. Conditions added by multiple
+// calls to Where are combined using "and". The condition can contain
+// placeholders, whose values are passed as args when the statement is
+// executed, and column expansions, eg "{pk}".
+func (b *SelectBuilder) Where(condition string) *SelectBuilder {
+	b.where = append(b.where, condition)
+	return b
+}
+
+// OrderBy adds columns to the order by clause, eg "name" or "created_at desc".
+func (b *SelectBuilder) OrderBy(columns ...string) *SelectBuilder {
+	b.orderBy = append(b.orderBy, columns...)
+	return b
+}
+
+// Limit limits the number of rows returned by the query. A value of
+// zero means no limit.
+func (b *SelectBuilder) Limit(n int) *SelectBuilder {
+	b.limit = n
+	return b
+}
+
+// Query returns the SQL query for the statement, before the columns
+// have been expanded.
+func (b *SelectBuilder) Query() (string, error) {
+	rowType, err := inferRowType(b.row)
+	if err != nil {
+		return "", err
+	}
+	if b.limit < 0 {
+		return "", errors.New("expected limit to be zero or greater")
+	}
+	table := b.table
+	if table == "" {
+		table = b.schema.tableName(rowType)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("select {} from ")
+	buf.WriteString(table)
+	for i, condition := range b.where {
+		if i == 0 {
+			buf.WriteString(" where ")
+		} else {
+			buf.WriteString(" and ")
+		}
+		if len(b.where) > 1 {
+			buf.WriteString("(" + condition + ")")
+		} else {
+			buf.WriteString(condition)
+		}
+	}
+	if len(b.orderBy) > 0 {
+		buf.WriteString(" order by ")
+		buf.WriteString(strings.Join(b.orderBy, ","))
+	}
+	query := buf.String()
+	if b.limit > 0 {
+		if query, err = limitQuery(b.schema.getDialect(), query, b.limit); err != nil {
+			return "", err
+		}
+	}
+	return query, nil
+}
+
+// Build returns the prepared statement. It is the same as calling
+// Prepare with the query returned by Query.
+func (b *SelectBuilder) Build() (*Stmt, error) {
+	query, err := b.Query()
+	if err != nil {
+		return nil, err
+	}
+	return b.schema.Prepare(b.row, query)
+}
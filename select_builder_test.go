@@ -0,0 +1,99 @@
+package sqlr
+
+import (
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestSelectBuilder(t *testing.T) {
+	type User struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	tests := []struct {
+		dialect Dialect
+		build   func(b *SelectBuilder) *SelectBuilder
+		want    string
+	}{
+		{
+			dialect: MySQL,
+			build:   func(b *SelectBuilder) *SelectBuilder { return b },
+			want:    "select `id`,`name` from user",
+		},
+		{
+			dialect: MySQL,
+			build: func(b *SelectBuilder) *SelectBuilder {
+				return b.Where("name like ?").OrderBy("name").Limit(10)
+			},
+			want: "select `id`,`name` from user where name like ? order by name limit 10",
+		},
+		{
+			dialect: Postgres,
+			build: func(b *SelectBuilder) *SelectBuilder {
+				return b.From("users").Where("name = ? or name = ?").Where("id > ?").OrderBy("name", "id desc")
+			},
+			want: `select "id","name" from users where (name = $1 or name = $2) and (id > $3) order by name,id desc`,
+		},
+		{
+			dialect: MSSQL,
+			build: func(b *SelectBuilder) *SelectBuilder {
+				return b.Where("{}").Limit(1)
+			},
+			want: "select top 1 [id],[name] from user where [id]=?",
+		},
+	}
+	for i, tt := range tests {
+		schema := NewSchema(WithDialect(tt.dialect))
+		stmt, err := tt.build(schema.SelectBuilder(User{})).Build()
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := stmt.String(), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+
+	schema := NewSchema(WithDialect(MySQL))
+	if _, err := schema.SelectBuilder(User{}).Limit(-1).Build(); err == nil {
+		t.Error("want error for negative limit, got nil")
+	}
+	if _, err := schema.SelectBuilder(1).Build(); err == nil {
+		t.Error("want error for invalid row type, got nil")
+	}
+}
+
+func TestSelectBuilderSelect(t *testing.T) {
+	type User struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL))
+
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name` from users where name like ? order by name limit 2")).
+		WithArgs("A%").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Alice").AddRow(2, "Andrew"))
+
+	stmt, err := schema.SelectBuilder(User{}).From("users").Where("name like ?").OrderBy("name").Limit(2).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var users []User
+	n, err := stmt.Select(db, &users, "A%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := n, 2; got != want {
+		t.Errorf("want=%d, got=%d", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,172 @@
+package sqlr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// mapRow is the row type used to prepare queries for SelectMaps.
+// It has no columns, so queries cannot use "{}" column expansion.
+type mapRow struct{}
+
+// SelectMaps executes a SELECT query and returns each row as a map of column
+// name to value. It is useful for queries whose results do not correspond to a
+// struct type. The query uses the schema's dialect and identifier renaming, and
+// args that are slices are expanded as for other queries (eg "where id in (?)").
+//
+// Unlike selecting into structs, duplicate column names in the results are not
+// an error. This is common with joins, such as "select * from a join b on ...".
+// The first occurrence of a column name is used as is, and any subsequent
+// occurrences have an index appended, eg "id", "id_2", "id_3".
+//
+// Values of type []byte are returned as strings, because many drivers return
+// text and numeric values as byte slices.
+func (s *Schema) SelectMaps(db DB, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	_, result, err := s.selectMaps(db, query, args)
+	return result, err
+}
+
+// SelectMapsBy executes a SELECT query in the same way as SelectMaps, and groups the
+// rows by the value of the key column. The rows in each group are in the order that
+// they were returned by the query. This is useful for reporting over tables that do
+// not have a corresponding struct type:
+//  byStatus, err := schema.SelectMapsBy(db, "status", "select * from orders")
+//
+// The key column is identified by its name in the row maps, so the second occurrence
+// of a duplicate column name "id" is identified as "id_2". An error is returned if
+// the query results do not include the key column. Rows where the key column is NULL
+// are grouped under the nil key.
+func (s *Schema) SelectMapsBy(db DB, keyColumn string, query string, args ...interface{}) (map[interface{}][]map[string]interface{}, error) {
+	keys, rows, err := s.selectMaps(db, query, args)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, key := range keys {
+		if key == keyColumn {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("key column %q not found in query results", keyColumn)
+	}
+	result := make(map[interface{}][]map[string]interface{})
+	for _, row := range rows {
+		keyValue := row[keyColumn]
+		result[keyValue] = append(result[keyValue], row)
+	}
+	return result, nil
+}
+
+// SelectMap executes a SELECT query and returns a map from the value of the key
+// column to the value of the value column, for each row. It is useful for simple
+// two-column queries, such as lookup tables:
+//  names, err := schema.SelectMap(db, "id", "name", "select id, name from users")
+//
+// The columns are identified by their names in the query results, in the same way
+// as for SelectMapsBy, and an error is returned if either column is not found. If
+// more than one row has the same key, the value from the last row is used.
+//
+// The keys and values have the types returned by the driver, eg int64 for integer
+// columns, except that []byte values are returned as strings. Callers use type
+// assertions to convert the keys and values:
+//  for k, v := range names {
+//      id, name := k.(int64), v.(string)
+//  }
+func (s *Schema) SelectMap(db DB, keyColumn string, valueColumn string, query string, args ...interface{}) (map[interface{}]interface{}, error) {
+	keys, rows, err := s.selectMaps(db, query, args)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range []string{keyColumn, valueColumn} {
+		found := false
+		for _, key := range keys {
+			if key == column {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("column %q not found in query results", column)
+		}
+	}
+	result := make(map[interface{}]interface{}, len(rows))
+	for _, row := range rows {
+		result[row[keyColumn]] = row[valueColumn]
+	}
+	return result, nil
+}
+
+// selectMaps executes the query and returns the unique column names of the
+// results, and each row as a map of column name to value.
+func (s *Schema) selectMaps(db DB, query string, args []interface{}) ([]string, []map[string]interface{}, error) {
+	stmt, err := s.Prepare(mapRow{}, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	expanded, err := stmt.expandQuery(stmt.query, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := expanded.QueryOn(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	columnNames, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := uniqueColumnNames(columnNames)
+	values := make([]interface{}, len(keys))
+	scanValues := make([]interface{}, len(keys))
+	for i := range values {
+		scanValues[i] = &values[i]
+	}
+
+	var result []map[string]interface{}
+	for rows.Next() {
+		if err := rows.Scan(scanValues...); err != nil {
+			return nil, nil, err
+		}
+		m := make(map[string]interface{}, len(keys))
+		for i, key := range keys {
+			if b, ok := values[i].([]byte); ok {
+				m[key] = string(b)
+			} else {
+				m[key] = values[i]
+			}
+		}
+		result = append(result, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return keys, result, nil
+}
+
+// uniqueColumnNames returns the column names with an index appended
+// to any duplicate names, so that each name is unique. An appended index
+// never produces the name of another column in the results.
+func uniqueColumnNames(columnNames []string) []string {
+	seen := make(map[string]bool, len(columnNames))
+	for _, name := range columnNames {
+		seen[name] = true
+	}
+	used := make(map[string]bool, len(columnNames))
+	names := make([]string, len(columnNames))
+	for i, name := range columnNames {
+		unique := name
+		for n := 2; used[unique]; n++ {
+			if unique = name + "_" + strconv.Itoa(n); seen[unique] {
+				// never use the name of another column
+				unique = name
+			}
+		}
+		used[unique] = true
+		names[i] = unique
+	}
+	return names
+}
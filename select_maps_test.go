@@ -0,0 +1,158 @@
+package sqlr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestUniqueColumnNames(t *testing.T) {
+	tests := []struct {
+		names string
+		want  string
+	}{
+		{
+			names: "id,name",
+			want:  "id,name",
+		},
+		{
+			names: "id,name,id,id",
+			want:  "id,name,id_2,id_3",
+		},
+		{
+			names: "id,id,id_2",
+			want:  "id,id_3,id_2",
+		},
+	}
+	for i, tt := range tests {
+		got := strings.Join(uniqueColumnNames(strings.Split(tt.names, ",")), ",")
+		if got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+}
+
+func TestSelectMaps(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	mock.ExpectQuery(regexp.QuoteMeta("select * from orders o join customers c on c.id = o.customer_id where o.id in ($1,$2)")).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "id", "name"}).
+			AddRow(1, 10, 10, []byte("Alice")).
+			AddRow(2, 11, 11, "Bob"))
+
+	rows, err := schema.SelectMaps(db, "select * from orders o join customers c on c.id = o.customer_id where o.id in (?)", []int{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(rows), 2; got != want {
+		t.Fatalf("want=%d, got=%d", want, got)
+	}
+	for i, want := range []string{"1 10 10 Alice\n", "2 11 11 Bob\n"} {
+		row := rows[i]
+		got := fmt.Sprintln(row["id"], row["customer_id"], row["id_2"], row["name"])
+		if got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSelectMapsBy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	mock.ExpectQuery(regexp.QuoteMeta("select id, status from orders")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).
+			AddRow(1, []byte("open")).
+			AddRow(2, "closed").
+			AddRow(3, nil).
+			AddRow(4, "open"))
+
+	groups, err := schema.SelectMapsBy(db, "status", "select id, status from orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key interface{}
+		ids string
+	}{
+		{"open", "[1 4]"},
+		{"closed", "[2]"},
+		{nil, "[3]"},
+	}
+	if got, want := len(groups), len(tests); got != want {
+		t.Errorf("want=%d groups, got=%d", want, got)
+	}
+	for i, tt := range tests {
+		var ids []interface{}
+		for _, row := range groups[tt.key] {
+			ids = append(ids, row["id"])
+		}
+		if got := fmt.Sprint(ids); got != tt.ids {
+			t.Errorf("%d: want=%q, got=%q", i, tt.ids, got)
+		}
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("select id from orders")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = schema.SelectMapsBy(db, "status", "select id from orders")
+	if got, want := fmt.Sprint(err), `key column "status" not found in query results`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSelectMap(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	mock.ExpectQuery(regexp.QuoteMeta("select id, name from users where id in ($1,$2,$3)")).
+		WithArgs(1, 2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(int64(1), []byte("Alice")).
+			AddRow(int64(2), "Bob").
+			AddRow(int64(1), "Alison"))
+
+	names, err := schema.SelectMap(db, "id", "name", "select id, name from users where id in (?)", []int{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[interface{}]interface{}{
+		int64(1): "Alison",
+		int64(2): "Bob",
+	}
+	if got, want := fmt.Sprint(names), fmt.Sprint(want); got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("select id from users")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = schema.SelectMap(db, "id", "name", "select id from users")
+	if got, want := fmt.Sprint(err), `column "name" not found in query results`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
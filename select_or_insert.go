@@ -0,0 +1,113 @@
+package sqlr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// maxSelectOrInsertAttempts is the number of times SelectOrInsert attempts
+// to select or insert the row, when a concurrent insert of the same row
+// causes a duplicate key error.
+const maxSelectOrInsertAttempts = 3
+
+// SelectOrInsert selects the row in the table with the same primary key as row,
+// which must be a pointer to a struct. If the row is found, it is scanned into
+// row and found is true. Otherwise row is inserted into the table, and found is
+// false. This provides "get or create" semantics:
+//  user := User{ID: 42, Name: "Default"}
+//  found, err := schema.SelectOrInsert(db, &user, "users")
+//
+// If tableName is empty, the table name is obtained from the row type's
+// TableName method if it has one, otherwise it is the name of the row type
+// converted using the schema's naming convention.
+//
+// If db is a *sql.DB, the select and insert are performed in a single
+// transaction. If another program inserts the same row after the select,
+// the insert fails with a duplicate key error, so the transaction is rolled
+// back and the select is attempted again. If db is a *sql.Tx, the calling
+// program is responsible for committing or rolling back the transaction,
+// and a duplicate key error is returned to the caller.
+func (s *Schema) SelectOrInsert(db DB, row interface{}, tableName string) (found bool, err error) {
+	rowValue := reflect.ValueOf(row)
+	if rowValue.Kind() != reflect.Ptr || rowValue.IsNil() || rowValue.Elem().Kind() != reflect.Struct {
+		return false, errors.New("expected row to be a pointer to a struct")
+	}
+	rowType := rowValue.Elem().Type()
+	if tableName == "" {
+		tableName = s.tableName(rowType)
+	}
+
+	var keys []interface{}
+	for _, col := range s.columnsForType(rowType) {
+		if !col.Tag.PrimaryKey {
+			continue
+		}
+		var key interface{}
+		if v := fieldValue(col, rowValue.Elem()); v.IsValid() {
+			key = v.Interface()
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return false, fmt.Errorf("no primary key for type %s", rowType.Name())
+	}
+
+	selectStmt, err := s.Prepare(row, fmt.Sprintf(selectFormat, tableName))
+	if err != nil {
+		return false, err
+	}
+	insertStmt, err := s.Prepare(row, fmt.Sprintf(insertFormat, tableName))
+	if err != nil {
+		return false, err
+	}
+
+	beginner, ok := db.(interface {
+		Begin() (*sql.Tx, error)
+	})
+	if !ok {
+		// the caller owns the transaction, so there is no retry
+		return selectOrInsert(db, row, keys, selectStmt, insertStmt)
+	}
+	for attempt := 1; ; attempt++ {
+		found, err = selectOrInsertTx(beginner, row, keys, selectStmt, insertStmt)
+		if attempt == maxSelectOrInsertAttempts || !s.isDuplicateKey(err) {
+			return found, err
+		}
+	}
+}
+
+// selectOrInsertTx performs selectOrInsert in a new transaction.
+func selectOrInsertTx(beginner interface {
+	Begin() (*sql.Tx, error)
+}, row interface{}, keys []interface{}, selectStmt, insertStmt *Stmt) (bool, error) {
+	tx, err := beginner.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	found, err := selectOrInsert(tx, row, keys, selectStmt, insertStmt)
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return found, nil
+}
+
+// selectOrInsert selects the row by its primary key, and inserts it if it is not found.
+func selectOrInsert(db DB, row interface{}, keys []interface{}, selectStmt, insertStmt *Stmt) (bool, error) {
+	err := selectStmt.SelectOne(db, row, keys...)
+	if err == nil {
+		return true, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+	if _, err := insertStmt.Exec(db, row); err != nil {
+		return false, err
+	}
+	return false, nil
+}
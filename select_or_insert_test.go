@@ -0,0 +1,140 @@
+package sqlr
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+type selectOrInsertRow struct {
+	ID   int64 `sql:"primary key"`
+	Name string
+}
+
+var errDuplicateKey = errors.New("duplicate key")
+
+// duplicateDialect is a dialect that reports errDuplicateKey as a duplicate key error
+type duplicateDialect struct {
+	Dialect
+}
+
+func (d duplicateDialect) IsDuplicateKey(err error) bool {
+	return err == errDuplicateKey
+}
+
+func TestSelectOrInsert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	selectQuery := regexp.QuoteMeta("select `id`,`name` from users where `id`=?")
+	insertQuery := regexp.QuoteMeta("insert into users(`id`,`name`) values(?,?)")
+	tests := []struct {
+		expect    func()
+		wantFound bool
+		wantName  string
+		wantErr   error
+	}{
+		{
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Found"))
+				mock.ExpectCommit()
+			},
+			wantFound: true,
+			wantName:  "Found",
+		},
+		{
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+				mock.ExpectExec(insertQuery).WithArgs(1, "New").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantFound: false,
+			wantName:  "New",
+		},
+		{
+			// concurrent insert, so the select is attempted again
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+				mock.ExpectExec(insertQuery).WithArgs(1, "New").WillReturnError(errDuplicateKey)
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectQuery).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Other"))
+				mock.ExpectCommit()
+			},
+			wantFound: true,
+			wantName:  "Other",
+		},
+		{
+			// gives up after the maximum number of attempts
+			expect: func() {
+				for i := 0; i < maxSelectOrInsertAttempts; i++ {
+					mock.ExpectBegin()
+					mock.ExpectQuery(selectQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+					mock.ExpectExec(insertQuery).WithArgs(1, "New").WillReturnError(errDuplicateKey)
+					mock.ExpectRollback()
+				}
+			},
+			wantName: "New",
+			wantErr:  errDuplicateKey,
+		},
+	}
+
+	schema := NewSchema(WithDialect(duplicateDialect{MySQL}))
+	for i, tt := range tests {
+		tt.expect()
+		row := selectOrInsertRow{ID: 1, Name: "New"}
+		found, err := schema.SelectOrInsert(db, &row, "users")
+		if err != tt.wantErr {
+			t.Errorf("%d: want err=%v, got=%v", i, tt.wantErr, err)
+		}
+		if got, want := found, tt.wantFound; got != want {
+			t.Errorf("%d: found: want=%v, got=%v", i, want, got)
+		}
+		if got, want := row.Name, tt.wantName; got != want {
+			t.Errorf("%d: name: want=%q, got=%q", i, want, got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%d: %v", i, err)
+		}
+	}
+}
+
+func TestSelectOrInsertErrors(t *testing.T) {
+	type noPK struct {
+		Name string
+	}
+	schema := NewSchema(WithDialect(MySQL))
+	tests := []struct {
+		row  interface{}
+		want string
+	}{
+		{
+			row:  selectOrInsertRow{},
+			want: "expected row to be a pointer to a struct",
+		},
+		{
+			row:  &noPK{},
+			want: "no primary key for type noPK",
+		},
+	}
+	for i, tt := range tests {
+		_, err := schema.SelectOrInsert(nil, tt.row, "users")
+		if err == nil {
+			t.Errorf("%d: want error, got nil", i)
+			continue
+		}
+		if got, want := err.Error(), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
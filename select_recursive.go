@@ -0,0 +1,175 @@
+package sqlr
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/jjeffery/sqlr/private/column"
+)
+
+// SelectRecursive selects the rows of a table that has a parent-child
+// relationship with itself, and stores them in rows as a tree. The root
+// rows are selected by the rootWhere condition, and the query is a recursive
+// common table expression that also selects all of their descendants:
+//
+//  with recursive sqlr_tree as (
+//      select {} from categories where parent_id is null
+//      union all
+//      select {alias sqlr_child} from categories sqlr_child
+//      inner join sqlr_tree on sqlr_child.parent_id = sqlr_tree.id
+//  ) select {} from sqlr_tree
+//
+// The rows argument must be a pointer to a slice of pointers to structs,
+// which receives the root rows. The row type must have a single primary key
+// field, eg ID, and a field with the parent's primary key named "Parent"
+// followed by the name of the primary key field, eg ParentID. The childrenField
+// argument is the name of the field that receives the children of each row,
+// and must have the same type as the slice, eg:
+//
+//  type Category struct {
+//      ID       int `sql:"primary key"`
+//      ParentID *int
+//      Name     string
+//      Children []*Category
+//  }
+//
+//  var roots []*Category
+//  err := schema.SelectRecursive(db, &roots, "parent_id is null", "Children")
+//
+// The table name is obtained in the same way as for SelectBuilder. The args
+// are the values for the placeholders in rootWhere. Children are in the order
+// returned by the database. A row that is a descendant of another root row
+// appears only once in the tree, but the data must not contain cycles.
+func (s *Schema) SelectRecursive(db DB, rows interface{}, rootWhere string, childrenField string, args ...interface{}) error {
+	rowsValue := reflect.ValueOf(rows)
+	if rowsValue.Kind() != reflect.Ptr ||
+		rowsValue.Elem().Kind() != reflect.Slice ||
+		rowsValue.Elem().Type().Elem().Kind() != reflect.Ptr ||
+		rowsValue.Elem().Type().Elem().Elem().Kind() != reflect.Struct {
+		return errors.New("expected rows to be a pointer to a slice of pointers to structs")
+	}
+	sliceType := rowsValue.Elem().Type()
+	rowType := sliceType.Elem().Elem()
+	children, ok := rowType.FieldByName(childrenField)
+	if !ok || children.Type != sliceType {
+		return fmt.Errorf("expected field %q of %s to be of type %s", childrenField, rowType, sliceType)
+	}
+	pkCol, parentCol, err := s.recursiveColumns(rowType, childrenField)
+	if err != nil {
+		return err
+	}
+
+	table := s.tableName(rowType)
+	namer := s.columnNamerForTable(table)
+	dialect := s.getDialect()
+	keywords := "with recursive"
+	if d, ok := dialect.(interface {
+		RecursiveCTE() string
+	}); ok {
+		keywords = d.RecursiveCTE()
+	}
+	query := fmt.Sprintf("%s sqlr_tree as ("+
+		"select {} from %s where %s"+
+		" union all "+
+		"select {alias sqlr_child} from %s sqlr_child"+
+		" inner join sqlr_tree on sqlr_child.%s = sqlr_tree.%s"+
+		") select {} from sqlr_tree",
+		keywords, table, rootWhere, table,
+		s.quoteMode.quote(dialect, namer.ColumnName(parentCol)), s.quoteMode.quote(dialect, namer.ColumnName(pkCol)))
+	stmt, err := s.Prepare(rows, query)
+	if err != nil {
+		return err
+	}
+	if db, err = s.lazyDB(db); err != nil {
+		return err
+	}
+
+	nodes := reflect.New(sliceType)
+	if _, err := stmt.Select(db, nodes.Interface(), args...); err != nil {
+		return err
+	}
+	rowsValue.Elem().Set(buildTree(nodes.Elem(), pkCol, parentCol, children.Index))
+	return nil
+}
+
+// recursiveColumns returns the primary key column and the parent column
+// for a row type used with SelectRecursive.
+func (s *Schema) recursiveColumns(rowType reflect.Type, childrenField string) (pkCol, parentCol *column.Info, err error) {
+	cols := s.columnsForType(rowType)
+	for _, col := range cols {
+		if col.Tag.PrimaryKey {
+			if pkCol != nil {
+				return nil, nil, fmt.Errorf("expected %s to have a single primary key field", rowType)
+			}
+			pkCol = col
+		}
+		if col.FieldNames == childrenField {
+			return nil, nil, fmt.Errorf("field %q of %s is a column, use `sql:\"-\"` to ignore it", childrenField, rowType)
+		}
+	}
+	if pkCol == nil {
+		return nil, nil, fmt.Errorf("expected %s to have a primary key field", rowType)
+	}
+	parentName := "Parent" + pkCol.Field.Name
+	for _, col := range cols {
+		if col.FieldNames == parentName {
+			parentCol = col
+		}
+	}
+	if parentCol == nil {
+		return nil, nil, fmt.Errorf("expected %s to have a %s field", rowType, parentName)
+	}
+	return pkCol, parentCol, nil
+}
+
+// buildTree appends each of the nodes to the children of its parent, and
+// returns the nodes that do not have a parent in nodes. Nodes that appear
+// more than once are only added to the tree the first time.
+func buildTree(nodes reflect.Value, pkCol, parentCol *column.Info, childrenIndex []int) reflect.Value {
+	byKey := make(map[interface{}]reflect.Value, nodes.Len())
+	for i := 0; i < nodes.Len(); i++ {
+		node := nodes.Index(i)
+		if key, ok := treeKey(pkCol.Index.ValueRO(node.Elem())); ok {
+			if _, found := byKey[key]; !found {
+				byKey[key] = node
+			}
+		}
+	}
+
+	roots := reflect.MakeSlice(nodes.Type(), 0, 0)
+	for i := 0; i < nodes.Len(); i++ {
+		node := nodes.Index(i)
+		if key, ok := treeKey(pkCol.Index.ValueRO(node.Elem())); ok && byKey[key].Pointer() != node.Pointer() {
+			// duplicate of an earlier node
+			continue
+		}
+		var parent reflect.Value
+		var ok bool
+		if key, hasParent := treeKey(parentCol.Index.ValueRO(node.Elem())); hasParent {
+			parent, ok = byKey[key]
+		}
+		if ok && parent.Pointer() != node.Pointer() {
+			children := parent.Elem().FieldByIndex(childrenIndex)
+			children.Set(reflect.Append(children, node))
+		} else {
+			roots = reflect.Append(roots, node)
+		}
+	}
+	return roots
+}
+
+// treeKey returns a key for the primary key or parent value v, so that the
+// values can be compared even if the fields have different types, eg int
+// and *int64. It returns false if the value is NULL.
+func treeKey(v reflect.Value) (interface{}, bool) {
+	value, err := driver.DefaultParameterConverter.ConvertValue(v.Interface())
+	if err != nil || value == nil {
+		return nil, false
+	}
+	if b, ok := value.([]byte); ok {
+		return string(b), true
+	}
+	return value, true
+}
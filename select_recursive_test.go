@@ -0,0 +1,172 @@
+package sqlr
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+type recursiveCategory struct {
+	ID       int `sql:"primary key"`
+	ParentID *int
+	Name     string
+	Children []*recursiveCategory
+}
+
+func (c *recursiveCategory) TableName() string {
+	return "categories"
+}
+
+// String returns the tree of categories, eg "a(b,c(d))".
+func (c *recursiveCategory) String() string {
+	if len(c.Children) == 0 {
+		return c.Name
+	}
+	var children []string
+	for _, child := range c.Children {
+		children = append(children, child.String())
+	}
+	return c.Name + "(" + strings.Join(children, ",") + ")"
+}
+
+func TestSelectRecursive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		dialect   Dialect
+		quote     QuoteMode
+		rootWhere string
+		args      []interface{}
+		wantSQL   string
+		rows      [][]driver.Value
+		want      string
+	}{
+		{
+			dialect:   Postgres,
+			rootWhere: "parent_id is null",
+			wantSQL: `with recursive sqlr_tree as (` +
+				`select "id","parent_id","name" from categories where parent_id is null` +
+				` union all ` +
+				`select sqlr_child."id",sqlr_child."parent_id",sqlr_child."name" from categories sqlr_child` +
+				` inner join sqlr_tree on sqlr_child."parent_id" = sqlr_tree."id"` +
+				`) select "id","parent_id","name" from sqlr_tree`,
+			rows: [][]driver.Value{
+				{1, nil, "a"},
+				{5, nil, "e"},
+				{2, 1, "b"},
+				{3, 1, "c"},
+				{4, 3, "d"},
+			},
+			want: "[a(b,c(d)) e]",
+		},
+		{
+			dialect:   MSSQL,
+			rootWhere: "id in (?,?)",
+			args:      []interface{}{1, 3},
+			wantSQL: `with sqlr_tree as (` +
+				`select [id],[parent_id],[name] from categories where id in (?,?)` +
+				` union all ` +
+				`select sqlr_child.[id],sqlr_child.[parent_id],sqlr_child.[name] from categories sqlr_child` +
+				` inner join sqlr_tree on sqlr_child.[parent_id] = sqlr_tree.[id]` +
+				`) select [id],[parent_id],[name] from sqlr_tree`,
+			rows: [][]driver.Value{
+				{1, nil, "a"},
+				{3, 1, "c"},
+				{2, 1, "b"},
+				{3, 1, "c"},
+				{4, 3, "d"},
+				{4, 3, "d"},
+			},
+			want: "[a(c(d),b)]",
+		},
+		{
+			dialect:   Postgres,
+			quote:     QuoteNever,
+			rootWhere: "id = $1",
+			args:      []interface{}{3},
+			wantSQL: `with recursive sqlr_tree as (` +
+				`select id,parent_id,name from categories where id = $1` +
+				` union all ` +
+				`select sqlr_child.id,sqlr_child.parent_id,sqlr_child.name from categories sqlr_child` +
+				` inner join sqlr_tree on sqlr_child.parent_id = sqlr_tree.id` +
+				`) select id,parent_id,name from sqlr_tree`,
+			rows: [][]driver.Value{
+				{3, 1, "c"},
+				{4, 3, "d"},
+			},
+			want: "[c(d)]",
+		},
+	}
+	for i, tt := range tests {
+		schema := NewSchema(WithDialect(tt.dialect), WithIdentifierQuoting(tt.quote))
+		rows := sqlmock.NewRows([]string{"id", "parent_id", "name"})
+		for _, row := range tt.rows {
+			rows.AddRow(row...)
+		}
+		expect := mock.ExpectQuery(regexp.QuoteMeta(tt.wantSQL))
+		if len(tt.args) > 0 {
+			args := make([]driver.Value, len(tt.args))
+			for j, arg := range tt.args {
+				args[j] = arg
+			}
+			expect.WithArgs(args...)
+		}
+		expect.WillReturnRows(rows)
+
+		var roots []*recursiveCategory
+		if err := schema.SelectRecursive(db, &roots, tt.rootWhere, "Children", tt.args...); err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := fmt.Sprint(roots), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSelectRecursiveErrors(t *testing.T) {
+	type NoParent struct {
+		ID       int `sql:"primary key"`
+		Children []*NoParent
+	}
+	type Category struct {
+		ID       int `sql:"primary key"`
+		ParentID int
+		Children []Category
+	}
+	schema := NewSchema(WithDialect(Postgres))
+	tests := []struct {
+		rows    interface{}
+		wantErr string
+	}{
+		{
+			rows:    &[]recursiveCategory{},
+			wantErr: "expected rows to be a pointer to a slice of pointers to structs",
+		},
+		{
+			rows:    &[]*NoParent{},
+			wantErr: "expected sqlr.NoParent to have a ParentID field",
+		},
+		{
+			rows:    &[]*Category{},
+			wantErr: `expected field "Children" of sqlr.Category to be of type []*sqlr.Category`,
+		},
+	}
+	for i, tt := range tests {
+		err := schema.SelectRecursive(nil, tt.rows, "parent_id is null", "Children")
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%d: want=%q, got=%v", i, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package sqlr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// shardKey identifies the field used to compute the table name
+// for a row in a sharded table.
+type shardKey struct {
+	field string
+	fn    func(value interface{}) string
+}
+
+// shardTableName returns the table name for the row computed by the schema's
+// shard key function, or an empty string if the schema does not have a shard
+// key, or the row is not a struct with the shard key field.
+func (s *Schema) shardTableName(row interface{}) string {
+	if s.shardKey == nil || row == nil {
+		return ""
+	}
+	v := reflect.ValueOf(row)
+	for _, name := range strings.Split(s.shardKey.field, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return ""
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return ""
+		}
+		if v = v.FieldByName(name); !v.IsValid() {
+			return ""
+		}
+	}
+	if !v.CanInterface() {
+		return ""
+	}
+	return s.shardKey.fn(v.Interface())
+}
+
+// ShardTable returns the table name for the shard key value, computed by the
+// function passed to WithShardKey, or an empty string if the schema does not
+// have a shard key. Use it to name the table in a select query, because select
+// shorthands are not routed by shard key:
+//  n, err := schema.Select(db, &user, "select "+schema.ShardTable(userID), userID)
+func (s *Schema) ShardTable(value interface{}) string {
+	if s.shardKey == nil {
+		return ""
+	}
+	return s.shardKey.fn(value)
+}
+
+// shardTableForQuery returns the table name for a shorthand query computed
+// from the row's shard key, or an empty string if the shard key does not apply.
+// Only insert and update shorthands are routed by shard key. The row passed to
+// a select is normally the destination for the results, so its shard key field
+// is not set, and a select shorthand for a sharded row type is an error.
+func (s *Schema) shardTableForQuery(row interface{}, rowType reflect.Type, query string) (string, error) {
+	if s.shardKey == nil {
+		return "", nil
+	}
+	switch shorthandVerb(query) {
+	case "insert", "update":
+		return s.shardTableName(row), nil
+	case "select":
+		if s.hasShardKey(rowType) {
+			return "", fmt.Errorf("cannot use %q for sharded row type %s: name the table, eg using ShardTable",
+				strings.TrimSpace(query), rowType.Name())
+		}
+	}
+	return "", nil
+}
+
+// hasShardKey reports whether the row type has the shard key field.
+func (s *Schema) hasShardKey(rowType reflect.Type) bool {
+	t := rowType
+	for _, name := range strings.Split(s.shardKey.field, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			return false
+		}
+		t = field.Type
+	}
+	return true
+}
+
+// shorthandVerb returns "insert", "update" or "select" if the query is a
+// shorthand notation without a table name, otherwise an empty string.
+func shorthandVerb(query string) string {
+	words := strings.Fields(strings.ToLower(query))
+	switch {
+	case len(words) == 1:
+		switch words[0] {
+		case "insert", "update", "select":
+			return words[0]
+		}
+	case len(words) == 2:
+		if (words[0] == "insert" && words[1] == "into") || (words[0] == "select" && words[1] == "from") {
+			return words[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package sqlr
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestWithShardKey(t *testing.T) {
+	type UserKey struct {
+		UserID int
+	}
+	type User struct {
+		UserID int `sql:"primary key"`
+		Name   string
+	}
+	type Profile struct {
+		Key  UserKey
+		Bio  string
+		Skip int `sql:"-"`
+	}
+	schema := NewSchema(
+		WithDialect(MySQL),
+		WithShardKey("UserID", func(v interface{}) string {
+			return fmt.Sprintf("users_%d", v.(int)%4)
+		}),
+	)
+	tests := []struct {
+		row   interface{}
+		query string
+		want  string
+	}{
+		{
+			row:   &User{UserID: 42, Name: "A"},
+			query: "insert",
+			want:  "insert into users_2(`user_id`,`name`) values(?,?)",
+		},
+		{
+			row:   User{UserID: 7},
+			query: "update",
+			want:  "update users_3 set `name`=? where `user_id`=?",
+		},
+		{
+			row:   &User{UserID: 8},
+			query: "select " + schema.ShardTable(42),
+			want:  "select `user_id`,`name` from users_2 where `user_id`=?",
+		},
+		{
+			row:   &User{UserID: 9},
+			query: "insert into users({}) values({})",
+			want:  "insert into users(`user_id`,`name`) values(?,?)",
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := schema.Prepare(tt.row, tt.query)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := stmt.String(), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+
+	// the destination of a select does not determine the shard
+	for _, query := range []string{"select", "select from"} {
+		_, err := schema.Prepare(&User{UserID: 8}, query)
+		if got, want := fmt.Sprint(err), `cannot use "`+query+`" for sharded row type User: name the table, eg using ShardTable`; got != want {
+			t.Errorf("want=%q, got=%q", want, got)
+		}
+	}
+	if got, want := NewSchema().ShardTable(1), ""; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	// row types without the shard key field are not affected
+	if _, err := schema.Prepare(&Profile{}, "insert"); err == nil {
+		t.Error("want error for missing table name, got nil")
+	}
+	nested := schema.Clone(WithShardKey("Key.UserID", func(v interface{}) string {
+		return fmt.Sprintf("profiles_%d", v.(int)%2)
+	}))
+	stmt, err := nested.Prepare(&Profile{Key: UserKey{UserID: 5}}, "insert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), "insert into profiles_1(`key_user_id`,`bio`) values(?,?)"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
+
+func TestShardKeyExec(t *testing.T) {
+	type User struct {
+		UserID int `sql:"primary key"`
+		Name   string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(
+		WithDialect(MySQL),
+		WithShardKey("UserID", func(v interface{}) string {
+			return fmt.Sprintf("users_%d", v.(int)%4)
+		}),
+	)
+	for _, id := range []int{1, 2, 5} {
+		mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf("insert into users_%d(`user_id`,`name`) values(?,?)", id%4))).
+			WithArgs(id, "N").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		if _, err := schema.Exec(db, &User{UserID: id, Name: "N"}, "insert"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
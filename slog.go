@@ -0,0 +1,42 @@
+//go:build go1.21
+// +build go1.21
+
+package sqlr
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithSlogLogger creates an option that logs each execution of a statement
+// to logger at the given level. Each record has the message "sqlr query" and
+// the attributes "query", "args", "duration" and "rows", and "error" if the
+// execution failed.
+//
+// The args are logged as they are sent to the database, so consider the level
+// carefully if queries include sensitive values. Logging is disabled if logger
+// is nil, which is the default.
+func WithSlogLogger(logger *slog.Logger, level slog.Level) SchemaOption {
+	return func(schema *Schema) {
+		schema.queryLogger = nil
+		if logger != nil {
+			schema.queryLogger = func(event *QueryEvent) {
+				ctx := context.Background()
+				if !logger.Enabled(ctx, level) {
+					return
+				}
+				attrs := []slog.Attr{
+					slog.String("query", event.Query),
+					slog.Any("args", event.Args),
+					slog.Duration("duration", event.Duration),
+					slog.Int("rows", event.Rows),
+				}
+				if event.Err != nil {
+					attrs = append(attrs, slog.Any("error", event.Err))
+				}
+				logger.LogAttrs(ctx, level, "sqlr query", attrs...)
+			}
+		}
+		schema.cache.clear()
+	}
+}
@@ -0,0 +1,103 @@
+//go:build go1.21
+// +build go1.21
+
+package sqlr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestWithSlogLogger(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	schema := NewSchema(WithDialect(MySQL), WithSlogLogger(logger, slog.LevelInfo))
+
+	mock.ExpectExec(regexp.QuoteMeta("update rows set `name`=? where `id`=?")).
+		WithArgs("Alice", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := schema.Exec(db, &Row{ID: 1, Name: "Alice"}, "update rows"); err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name` from rows where id > ?")).
+		WithArgs(0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Alice").AddRow(2, "Bob"))
+	var rows []Row
+	if _, err := schema.Select(db, &rows, "select {} from rows where id > ?", 0); err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name` from rows where `id`=?")).
+		WithArgs(3).
+		WillReturnError(errors.New("test error"))
+	var row Row
+	if err := schema.SelectOne(db, &row, "select {} from rows where {}", 3); err == nil {
+		t.Fatal("want error, got nil")
+	}
+	// not logged, not sent to the database
+	if err := schema.SelectOne(db, row, "select {} from rows where {}", 3); err == nil {
+		t.Fatal("want error, got nil")
+	}
+
+	type record struct {
+		Msg   string        `json:"msg"`
+		Query string        `json:"query"`
+		Args  []interface{} `json:"args"`
+		Rows  int           `json:"rows"`
+		Error string        `json:"error"`
+	}
+	var records []record
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r record
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	want := []record{
+		{Msg: "sqlr query", Query: "update rows set `name`=? where `id`=?", Args: []interface{}{"Alice", float64(1)}, Rows: 1},
+		{Msg: "sqlr query", Query: "select `id`,`name` from rows where id > ?", Args: []interface{}{float64(0)}, Rows: 2},
+		{Msg: "sqlr query", Query: "select `id`,`name` from rows where `id`=?", Args: []interface{}{float64(3)}, Error: "test error"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("want %d records, got %d: %s", len(want), len(records), buf.String())
+	}
+	for i := range want {
+		if got, want := records[i], want[i]; got.Msg != want.Msg || got.Query != want.Query ||
+			got.Rows != want.Rows || got.Error != want.Error || len(got.Args) != len(want.Args) {
+			t.Errorf("%d: want=%+v, got=%+v", i, want, got)
+		}
+	}
+
+	// below the logger's level
+	buf.Reset()
+	schema = NewSchema(WithDialect(MySQL), WithSlogLogger(logger, slog.LevelDebug))
+	mock.ExpectExec(regexp.QuoteMeta("update rows set `name`=? where `id`=?")).
+		WithArgs("Alice", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := schema.Exec(db, &Row{ID: 1, Name: "Alice"}, "update rows"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("want nothing logged, got %s", buf.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -174,3 +174,19 @@ const (
 	queryDelete
 	querySelect
 )
+
+// String returns the SQL keyword for the query type, or an empty
+// string if the query type is not known.
+func (qt queryType) String() string {
+	switch qt {
+	case queryInsert:
+		return "insert"
+	case queryUpdate:
+		return "update"
+	case queryDelete:
+		return "delete"
+	case querySelect:
+		return "select"
+	}
+	return ""
+}
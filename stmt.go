@@ -3,18 +3,25 @@ package sqlr
 import (
 	"bytes"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/jjeffery/sqlr/private/column"
 	"github.com/jjeffery/sqlr/private/scanner"
-	"github.com/jjeffery/sqlr/private/wherein"
 )
 
+// ErrMultipleRows is returned when selecting into a single struct, and the query
+// returns more than one row. It is only returned by schemas created with the
+// WithErrorOnMultipleRows option.
+var ErrMultipleRows = errors.New("sql: more than one row in result set")
+
 // Stmt is a prepared statement. A Stmt is safe for concurrent use by multiple goroutines.
 type Stmt struct {
 	rowType     reflect.Type
@@ -26,10 +33,51 @@ type Stmt struct {
 	inputs      []inputSource
 	argCount    int      // the number of args expected in addition to fields from the row
 	output      struct { // outputs from a select query are determined the first time it is run
-		mutex   sync.RWMutex
-		columns []*column.Info
+		mutex       sync.RWMutex
+		columns     []*column.Info
+		columnNames []string // column names returned by the database, see LastColumns
+	}
+	autoIncrColumn   *column.Info
+	identityInsert   bool   // insert statement sets the auto-increment column explicitly
+	insertTable      string // table name for an insert statement
+	wrapIdentity     bool   // identity inserts are wrapped in "set identity_insert", see WithIdentityInsert
+	jsonErrorContext bool   // include JSON text in unmarshal errors
+	argCoercion      bool   // convert string args to the type of the compared column
+	encryptor        ColumnEncryptor
+	encryptionKeyID  string      // active encryption key ID
+	emptyStringNull  bool        // all string fields are stored as NULL when empty
+	enumValidation   bool        // values of enum fields are checked, see WithEnumValidation
+	preserveComments bool        // line comments are kept in the query, see WithPreserveComments
+	quoteMode        QuoteMode   // when column names are quoted, see WithIdentifierQuoting
+	columnCase       columnCase  // case of column names returned by the driver
+	queryLogger      queryLogger // called after each execution, see WithSlogLogger
+
+	// placeholderOffset is the number of placeholders that precede the
+	// statement's query, see WithPlaceholderOffset
+	placeholderOffset int
+
+	// errorOnMultipleRows is set if selecting into a single struct is an
+	// error when more than one row is returned, see WithErrorOnMultipleRows
+	errorOnMultipleRows bool
+
+	// inStrategy determines how args that are slices of tuples are
+	// expanded, see WithInStrategy
+	inStrategy InStrategy
+
+	// aliases maps result column names to field names, and is consulted
+	// before the column names of the fields, see SelectAs
+	aliases map[string]string
+
+	// source and renamer are used to prepare variants of the statement
+	// that omit fields from the update set clause, see WithOmit
+	source   string
+	renamer  identRenamer
+	omit     []string
+	tokens   *sqlTokens // source query scanned into tokens, shared by variants
+	variants struct {
+		mutex sync.Mutex
+		stmts map[string]*Stmt
 	}
-	autoIncrColumn *column.Info
 }
 
 // inputSource describes where to source the input to an SQL query. (There is
@@ -39,12 +87,42 @@ type Stmt struct {
 // associated with the column.
 //
 // If col is nil, then argIndex is the index into the args array, and the
-// corresponding arg should be used as input.
+// corresponding arg should be used as input. If the placeholder is compared
+// with a column in the query (eg "where id = ?"), then argCol is that column.
+//
+// If timestamp is true, then the input is the current time, and col is
+// the column for the "{ts}" expansion that the placeholder replaced.
 type inputSource struct {
-	col      *column.Info
-	argIndex int // used only if col == nil
+	col       *column.Info
+	field     *inputField  // used only if col != nil, resolved by resolveInputs
+	argIndex  int          // used only if col == nil
+	argCol    *column.Info // used only if col == nil, can be nil
+	timestamp bool         // input is the current time
 }
 
+// argType returns the type expected for the input's arg, or nil if not known.
+func (input inputSource) argType() reflect.Type {
+	if input.col != nil {
+		return input.col.Field.Type
+	}
+	if input.argCol != nil {
+		return input.argCol.Field.Type
+	}
+	return nil
+}
+
+// nanoTime returns true if the arg is compared with a time column that
+// is stored as Unix nanoseconds.
+func (input inputSource) nanoTime() bool {
+	if input.col != nil {
+		return isNanoTime(input.col)
+	}
+	return input.argCol != nil && isNanoTime(input.argCol)
+}
+
+// timeNow returns the current time, and can be replaced for testing.
+var timeNow = time.Now
+
 // identRenamer renames identifiers
 type identRenamer interface {
 	renameIdent(ident string) (string, bool)
@@ -55,15 +133,19 @@ type identRenamer interface {
 
 // inferRowType returns the type for the row parameter. It returns an
 // error if row is not a struct, or a pointer to struct, or a slice of
-// structs.
+// structs. Any number of pointers are dereferenced, so Row{}, &Row{} and
+// a **Row all have the same row type, even if the pointers are nil.
 func inferRowType(row interface{}) (reflect.Type, error) {
 	rowType := reflect.TypeOf(row)
-	if rowType.Kind() == reflect.Ptr {
+	if rowType == nil {
+		return nil, errors.New("expected arg to refer to a struct type")
+	}
+	for rowType.Kind() == reflect.Ptr {
 		rowType = rowType.Elem()
 	}
 	if rowType.Kind() == reflect.Slice {
 		rowType = rowType.Elem()
-		if rowType.Kind() == reflect.Ptr {
+		for rowType.Kind() == reflect.Ptr {
 			rowType = rowType.Elem()
 		}
 	}
@@ -73,23 +155,157 @@ func inferRowType(row interface{}) (reflect.Type, error) {
 	return rowType, nil
 }
 
-// newStmt creates a new statement for the row type and query. Panics if rowType does not
-// refer to a struct type.
-func newStmt(dialect Dialect, colNamer columnNamer, renamer identRenamer, rowType reflect.Type, sql string) (*Stmt, error) {
+// tableNamer is implemented by row types that know the name of their
+// database table. This is a common convention in other Go packages.
+type tableNamer interface {
+	TableName() string
+}
+
+// inferTableName returns the table name for the row type if it implements
+// the tableNamer interface with either a value or a pointer receiver.
+// Returns an empty string otherwise.
+func inferTableName(rowType reflect.Type) string {
+	if tn, ok := reflect.New(rowType).Interface().(tableNamer); ok {
+		return tn.TableName()
+	}
+	return ""
+}
+
+// tableAliaser is implemented by row types that declare the alias used
+// for their table in queries, eg "u" for "users u".
+type tableAliaser interface {
+	TableAlias() string
+}
+
+// inferTableAlias returns the table alias for the row type if it implements
+// the tableAliaser interface with either a value or a pointer receiver.
+// Returns an empty string otherwise.
+func inferTableAlias(rowType reflect.Type) string {
+	if ta, ok := reflect.New(rowType).Interface().(tableAliaser); ok {
+		return ta.TableAlias()
+	}
+	return ""
+}
+
+// newStmt creates a new statement for the row type and query. If preserveComments
+// is set, line comments in the query are kept. Column names are quoted according
+// to quoteMode. Panics if rowType does not refer to a struct type.
+func newStmt(dialect Dialect, colNamer columnNamer, renamer identRenamer, rowType reflect.Type, columns []*column.Info, sql string, preserveComments bool, quoteMode QuoteMode, wrapIdentity bool) (*Stmt, error) {
 	stmt := &Stmt{
-		dialect:     dialect,
-		columnNamer: colNamer,
-		rowType:     rowType,
+		dialect:          dialect,
+		columnNamer:      colNamer,
+		rowType:          rowType,
+		source:           sql,
+		renamer:          renamer,
+		preserveComments: preserveComments,
+		quoteMode:        quoteMode,
+		wrapIdentity:     wrapIdentity,
 	}
 	if stmt.rowType.Kind() != reflect.Struct {
 		// should never happen, calls inferRowType before calling this function
 		panic("not a struct")
 	}
-	stmt.columns = column.ListForType(stmt.rowType)
-	if err := stmt.scanSQL(sql, renamer); err != nil {
+	if err := checkBitGroups(columns); err != nil {
 		return nil, err
 	}
+	stmt.columns = columns
+	if err := stmt.render(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// clone returns a copy of the statement, which can be modified and then rendered
+// to create a variant of the statement. The variants of the statement and the
+// outputs of a select query are not copied.
+func (stmt *Stmt) clone() *Stmt {
+	return &Stmt{
+		rowType:          stmt.rowType,
+		queryType:        stmt.queryType,
+		query:            stmt.query,
+		dialect:          stmt.dialect,
+		columnNamer:      stmt.columnNamer,
+		columns:          stmt.columns,
+		inputs:           stmt.inputs,
+		argCount:         stmt.argCount,
+		autoIncrColumn:   stmt.autoIncrColumn,
+		identityInsert:   stmt.identityInsert,
+		insertTable:      stmt.insertTable,
+		wrapIdentity:     stmt.wrapIdentity,
+		jsonErrorContext: stmt.jsonErrorContext,
+		argCoercion:      stmt.argCoercion,
+		encryptor:        stmt.encryptor,
+		encryptionKeyID:  stmt.encryptionKeyID,
+		emptyStringNull:  stmt.emptyStringNull,
+		enumValidation:   stmt.enumValidation,
+		preserveComments: stmt.preserveComments,
+		quoteMode:        stmt.quoteMode,
+		columnCase:       stmt.columnCase,
+		queryLogger:      stmt.queryLogger,
+
+		placeholderOffset:   stmt.placeholderOffset,
+		errorOnMultipleRows: stmt.errorOnMultipleRows,
+		inStrategy:          stmt.inStrategy,
+		aliases:             stmt.aliases,
+		source:              stmt.source,
+		renamer:             stmt.renamer,
+		omit:                stmt.omit,
+		tokens:              stmt.tokens,
+	}
+}
 
+// render renders the statement's source query for the statement's dialect,
+// and works out the inputs for its placeholders. The source query is only
+// scanned the first time, and variants of the statement reuse the tokens.
+func (stmt *Stmt) render() error {
+	colon := colonPlaceholders(stmt.dialect)
+	if stmt.tokens == nil || stmt.tokens.colonPlaceholders != colon {
+		// ":1" is only a placeholder token for some dialects
+		stmt.tokens = scanTokens(stmt.source, colon)
+	}
+	stmt.queryType = queryUnknown
+	stmt.query = ""
+	stmt.inputs = nil
+	stmt.argCount = 0
+	stmt.autoIncrColumn = nil
+	stmt.identityInsert = false
+	stmt.insertTable = ""
+	if err := stmt.renderTokens(); err != nil {
+		return err
+	}
+	stmt.setAutoIncrColumn()
+	stmt.wrapIdentityInsert()
+	stmt.resolveInputs()
+	return nil
+}
+
+// wrapIdentityInsert wraps the query of an insert statement that sets the
+// auto-increment column explicitly, if the statement has the WithIdentityInsert
+// option and the dialect requires "set identity_insert" (ie MSSQL).
+func (stmt *Stmt) wrapIdentityInsert() {
+	if !stmt.wrapIdentity || !stmt.identityInsert || stmt.insertTable == "" {
+		return
+	}
+	if d, ok := stmt.dialect.(interface {
+		IdentityInsert() bool
+	}); ok && d.IdentityInsert() {
+		stmt.query = fmt.Sprintf("set identity_insert %s on; %s; set identity_insert %s off",
+			stmt.insertTable, stmt.query, stmt.insertTable)
+	}
+}
+
+// resolveInputs resolves the fields for inputs sourced from columns.
+func (stmt *Stmt) resolveInputs() {
+	for i := range stmt.inputs {
+		if col := stmt.inputs[i].col; col != nil {
+			stmt.inputs[i].field = newInputField(col, stmt.rowType)
+		}
+	}
+}
+
+// setAutoIncrColumn sets the auto-increment column for an insert statement,
+// unless the statement sets the auto-increment column explicitly.
+func (stmt *Stmt) setAutoIncrColumn() {
 	if stmt.queryType == queryInsert {
 		for _, col := range stmt.columns {
 			if col.Tag.AutoIncrement {
@@ -106,13 +322,12 @@ func newStmt(dialect Dialect, colNamer columnNamer, renamer identRenamer, rowTyp
 				if col.col == stmt.autoIncrColumn {
 					// this statement is setting the auto-increment column explicitly
 					stmt.autoIncrColumn = nil
+					stmt.identityInsert = true
 					break
 				}
 			}
 		}
 	}
-
-	return stmt, nil
 }
 
 // String prints the SQL query associated with the statement.
@@ -120,16 +335,121 @@ func (stmt *Stmt) String() string {
 	return stmt.query
 }
 
+// Columns returns information about all of the columns associated
+// with the statement's row type, in the order that the fields are declared.
+func (stmt *Stmt) Columns() []ColumnInfo {
+	infos := make([]ColumnInfo, 0, len(stmt.columns))
+	for _, col := range stmt.columns {
+		infos = append(infos, newColumnInfo(col, stmt.columnNamer))
+	}
+	return infos
+}
+
+// InputColumns returns information about the columns whose field values are
+// sent to the database as inputs when the statement is executed, in the order
+// that they first appear in the query. For example, a prepared update statement
+// returns the columns in the set clause and the where clause, but not any columns
+// that are only read from the database.
+//
+// Columns set to the current time with a "{ts}" expansion are not included,
+// because their field values are not used.
+func (stmt *Stmt) InputColumns() []ColumnInfo {
+	var infos []ColumnInfo
+	seen := make(map[*column.Info]bool)
+	for _, input := range stmt.inputs {
+		if input.col == nil || input.timestamp || seen[input.col] {
+			continue
+		}
+		seen[input.col] = true
+		infos = append(infos, newColumnInfo(input.col, stmt.columnNamer))
+	}
+	return infos
+}
+
+// Prepare creates a prepared statement at the driver level for the statement's
+// query, which has already been adapted for the dialect and has any columns expanded.
+// The caller is responsible for closing the returned statement.
+//
+// The returned statement accepts the args for the query in the order that the
+// placeholders appear, including args for any columns. It does not expand
+// args that are slices, so it cannot be used for queries like "where id in (?)"
+// with a slice arg.
+func (stmt *Stmt) Prepare(db *sql.DB) (*sql.Stmt, error) {
+	return db.Prepare(stmt.query)
+}
+
+// ForDialect returns a new statement for the same query and row type, with the
+// placeholders and quoted column names rendered for dialect d. This is useful
+// for tools that run the same logical query against more than one database.
+//
+// The new statement is rendered from the tokens of the statement's source query,
+// which are not scanned again. It does not include changes made for the schema's
+// dialect before the query was parsed, such as the row limit added by WithDefaultLimit.
+func (stmt *Stmt) ForDialect(d Dialect) (*Stmt, error) {
+	if d == nil {
+		return nil, errors.New("cannot render statement: dialect is nil")
+	}
+	return stmt.renderVariant(d, stmt.placeholderOffset)
+}
+
+// WithPlaceholderOffset returns a new statement for the same query and row type,
+// with numbered placeholders starting after offset. This is useful for composing
+// the statement's query into a hand-written query that already has placeholders.
+// For example, if the hand-written query uses $1, $2 and $3, then with an offset
+// of 3 the first placeholder of the statement is rendered as $4:
+//
+//  stmt, err := schema.Prepare(Row{}, "select {} from rows where {} and name <> ?")
+//  variant, err := stmt.WithPlaceholderOffset(3)
+//  // variant.String() is `select "id","name" from rows where "id"=$4 and name <> $5`
+//
+// The offset has no effect for dialects with positional placeholders, such as
+// MySQL. The new statement is only for rendering using String: it returns an error
+// if it is executed, because the args for the preceding placeholders are not known.
+func (stmt *Stmt) WithPlaceholderOffset(offset int) (*Stmt, error) {
+	if offset < 0 {
+		return nil, errors.New("cannot render statement: placeholder offset is negative")
+	}
+	return stmt.renderVariant(stmt.dialect, offset)
+}
+
+// renderVariant returns a new statement prepared from the statement's source query,
+// with the placeholders and column names rendered for dialect d, and numbered
+// placeholders starting after offset.
+func (stmt *Stmt) renderVariant(d Dialect, offset int) (*Stmt, error) {
+	if stmt.source == "" {
+		return nil, errors.New("cannot render statement: statement query is not known")
+	}
+	variant := stmt.clone()
+	variant.dialect = d
+	variant.placeholderOffset = offset
+	if err := variant.render(); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
 // Exec executes the prepared statement with the given row and optional arguments.
 // It returns the number of rows affected by the statement.
 //
 // If the statement is an INSERT statement and the row has an auto-increment field,
 // then the row is updated with the value of the auto-increment column as long as
 // the SQL driver supports this functionality.
-func (stmt *Stmt) Exec(db DB, row interface{}, args ...interface{}) (int, error) {
+//
+// Args can include options that apply to this execution only, such as WithOmit.
+func (stmt *Stmt) Exec(db DB, row interface{}, args ...interface{}) (rowsAffected int, err error) {
 	if stmt.queryType == querySelect {
 		return 0, errors.New("attempt to call Exec on select statement")
 	}
+	args, opts := splitStmtOptions(args)
+	if len(opts.omit) > 0 {
+		variant, err := stmt.omitting(opts.omit)
+		if err != nil {
+			return 0, err
+		}
+		stmt = variant
+	}
+	qlog := stmt.newQueryLog()
+	defer func() { qlog.done(rowsAffected, err) }()
 
 	// field for setting the auto-increment value
 	var field reflect.Value
@@ -141,15 +461,16 @@ func (stmt *Stmt) Exec(db DB, row interface{}, args ...interface{}) (int, error)
 		}
 	}
 
-	args, err := stmt.getArgs(row, args)
+	args, err = stmt.getArgs(row, args)
 	if err != nil {
 		return 0, err
 	}
-	expandedQuery, expandedArgs, err := wherein.Expand(stmt.query, args)
+	query, err := stmt.expandQuery(stmt.query, args)
 	if err != nil {
 		return 0, err
 	}
-	result, err := db.Exec(expandedQuery, expandedArgs...)
+	qlog.executing(query)
+	result, err := query.ExecOn(db)
 	if err != nil {
 		return 0, err
 	}
@@ -167,7 +488,7 @@ func (stmt *Stmt) Exec(db DB, row interface{}, args ...interface{}) (int, error)
 		field.SetInt(n)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	n, err := result.RowsAffected()
 	if err != nil {
 		// The statement was successful but getting the row count failed.
 		// Return error with the expectation that the calling program will
@@ -176,7 +497,7 @@ func (stmt *Stmt) Exec(db DB, row interface{}, args ...interface{}) (int, error)
 	}
 
 	// assuming that rows affected fits in an int
-	return int(rowsAffected), nil
+	return int(n), nil
 }
 
 // Select executes the prepared query statement with the given arguments and
@@ -185,7 +506,11 @@ func (stmt *Stmt) Exec(db DB, row interface{}, args ...interface{}) (int, error)
 // is a pointer to a struct then that struct is filled with the result of the first
 // row returned by the query. In both cases Select returns the number of rows returned
 // by the query.
-func (stmt *Stmt) Select(db DB, rows interface{}, args ...interface{}) (int, error) {
+//
+// If the statement was prepared by a schema created with the WithErrorOnMultipleRows
+// option, and row is a pointer to a struct, Select returns ErrMultipleRows if the
+// query returns more than one row. The struct is not modified.
+func (stmt *Stmt) Select(db DB, rows interface{}, args ...interface{}) (rowCount int, err error) {
 	if rows == nil {
 		return 0, errors.New("nil pointer")
 	}
@@ -206,9 +531,11 @@ func (stmt *Stmt) Select(db DB, rows interface{}, args ...interface{}) (int, err
 
 	destValue = reflect.Indirect(destValue)
 	destType := destValue.Type()
+	qlog := stmt.newQueryLog()
+	defer func() { qlog.done(rowCount, err) }()
 	if destType == stmt.rowType {
 		// pointer to row struct, so only fetch one row
-		return stmt.selectOne(db, rows, destValue, args)
+		return stmt.selectOne(db, qlog, destValue, args)
 	}
 
 	// if not a pointer to a struct, should be a pointer to a
@@ -227,11 +554,16 @@ func (stmt *Stmt) Select(db DB, rows interface{}, args ...interface{}) (int, err
 		return 0, errorPtrType()
 	}
 
-	expandedQuery, expandedArgs, err := wherein.Expand(stmt.query, args)
+	args, err = stmt.coerceSelectArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	query, err := stmt.expandQuery(stmt.query, args)
 	if err != nil {
 		return 0, err
 	}
-	sqlRows, err := db.Query(expandedQuery, expandedArgs...)
+	qlog.executing(query)
+	sqlRows, err := query.QueryOn(db)
 	if err != nil {
 		return 0, err
 	}
@@ -241,35 +573,12 @@ func (stmt *Stmt) Select(db DB, rows interface{}, args ...interface{}) (int, err
 		return 0, err
 	}
 
-	var rowCount = 0
-	scanValues := make([]interface{}, len(stmt.columns))
-
 	for sqlRows.Next() {
 		rowCount++
 		rowValuePtr := reflect.New(rowType)
 		rowValue := reflect.Indirect(rowValuePtr)
-		var jsonCells []*jsonCell
-		for i, col := range outputs {
-			cellValue := col.Index.ValueRW(rowValue)
-			cellPtr := cellValue.Addr().Interface()
-			if col.Tag.JSON {
-				jc := newJSONCell(col.Field.Name, cellPtr)
-				jsonCells = append(jsonCells, jc)
-				scanValues[i] = jc.ScanValue()
-			} else if col.Tag.EmptyNull {
-				scanValues[i] = newNullCell(col.Field.Name, cellValue, cellPtr)
-			} else {
-				scanValues[i] = cellPtr
-			}
-		}
-		err = sqlRows.Scan(scanValues...)
-		if err != nil {
-			return 0, err
-		}
-		for _, jc := range jsonCells {
-			if err := jc.Unmarshal(); err != nil {
-				return rowCount, err
-			}
+		if err := stmt.scanRow(sqlRows, outputs, rowValue); err != nil {
+			return rowCount, err
 		}
 		if isPtr {
 			sliceValue.Set(reflect.Append(sliceValue, rowValuePtr))
@@ -297,12 +606,17 @@ func (stmt *Stmt) Select(db DB, rows interface{}, args ...interface{}) (int, err
 
 // TODO(jpj): need to merge the common code in Select and selectOne
 
-func (stmt *Stmt) selectOne(db DB, dest interface{}, rowValue reflect.Value, args []interface{}) (int, error) {
-	expandedQuery, expandedArgs, err := wherein.Expand(stmt.query, args)
+func (stmt *Stmt) selectOne(db DB, qlog *queryLog, rowValue reflect.Value, args []interface{}) (int, error) {
+	args, err := stmt.coerceSelectArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	query, err := stmt.expandQuery(stmt.query, args)
 	if err != nil {
 		return 0, err
 	}
-	rows, err := db.Query(expandedQuery, expandedArgs...)
+	qlog.executing(query)
+	rows, err := query.QueryOn(db)
 	if err != nil {
 		return 0, err
 	}
@@ -312,9 +626,6 @@ func (stmt *Stmt) selectOne(db DB, dest interface{}, rowValue reflect.Value, arg
 		return 0, err
 	}
 
-	scanValues := make([]interface{}, len(outputs))
-	var jsonCells []*jsonCell
-
 	if !rows.Next() {
 		// no rows returned
 		return 0, nil
@@ -323,35 +634,387 @@ func (stmt *Stmt) selectOne(db DB, dest interface{}, rowValue reflect.Value, arg
 	// at least one row returned
 	rowCount := 1
 
+	dest := rowValue
+	if stmt.errorOnMultipleRows {
+		// scan into a new value so that row is not modified if there are more rows
+		dest = reflect.New(stmt.rowType).Elem()
+	}
+	if err := stmt.scanRow(rows, outputs, dest); err != nil {
+		return rowCount, err
+	}
+
+	// count any additional rows
+	for rows.Next() {
+		rowCount++
+	}
+
+	if stmt.errorOnMultipleRows {
+		if rowCount > 1 {
+			return rowCount, ErrMultipleRows
+		}
+		rowValue.Set(dest)
+	}
+	return rowCount, nil
+}
+
+// SelectOne executes the prepared query statement with the given arguments and
+// stores the first row returned by the query in row, which must be a pointer to
+// a struct of the statement's row type. Any additional rows returned by the query
+// are ignored. If the query returns no rows, SelectOne returns sql.ErrNoRows and
+// row is not modified.
+//
+// If the statement was prepared by a schema created with the WithErrorOnMultipleRows
+// option, SelectOne returns ErrMultipleRows if the query returns more than one row,
+// and row is not modified.
+func (stmt *Stmt) SelectOne(db DB, row interface{}, args ...interface{}) (err error) {
+	rowValue := reflect.ValueOf(row)
+	if rowValue.Kind() != reflect.Ptr || rowValue.IsNil() || rowValue.Elem().Type() != stmt.rowType {
+		return fmt.Errorf("expected row to be *%s", stmt.expectedTypeName())
+	}
+	qlog := stmt.newQueryLog()
+	defer func() {
+		var rowCount int
+		if err == nil {
+			rowCount = 1
+		}
+		qlog.done(rowCount, err)
+	}()
+	args, err = stmt.coerceSelectArgs(args)
+	if err != nil {
+		return err
+	}
+	query, err := stmt.expandQuery(stmt.query, args)
+	if err != nil {
+		return err
+	}
+	qlog.executing(query)
+	rows, err := query.QueryOn(db)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	outputs, err := stmt.getOutputs(rows)
+	if err != nil {
+		return err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	// scan into a new value so that row is not modified if there is an error
+	newValue := reflect.New(stmt.rowType).Elem()
+	if err := stmt.scanRow(rows, outputs, newValue); err != nil {
+		return err
+	}
+	if stmt.errorOnMultipleRows && rows.Next() {
+		return ErrMultipleRows
+	}
+	rowValue.Elem().Set(newValue)
+	return nil
+}
+
+// SelectScalar executes the prepared query statement with the given arguments.
+// The query must return a single column, and the value of that column in the first
+// row returned is stored in dest, which must be a non-nil pointer. If the query
+// returns no rows, dest is not modified. SelectScalar returns the number of rows
+// returned by the query.
+//
+// SelectScalar is useful for queries that return a single value, such as
+//  select count(*) from users where {}
+func (stmt *Stmt) SelectScalar(db DB, dest interface{}, args ...interface{}) (rowCount int, err error) {
+	if dest == nil {
+		return 0, errors.New("nil pointer")
+	}
+	if destValue := reflect.ValueOf(dest); destValue.Kind() != reflect.Ptr || destValue.IsNil() {
+		return 0, errors.New("expected dest to be a non-nil pointer")
+	}
+	qlog := stmt.newQueryLog()
+	defer func() { qlog.done(rowCount, err) }()
+	args, err = stmt.coerceSelectArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	query, err := stmt.expandQuery(stmt.query, args)
+	if err != nil {
+		return 0, err
+	}
+	qlog.executing(query)
+	rows, err := query.QueryOn(db)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	columnNames, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if len(columnNames) != 1 {
+		return 0, fmt.Errorf("expected one column, got %d", len(columnNames))
+	}
+
+	for rows.Next() {
+		rowCount++
+		if rowCount == 1 {
+			if err := rows.Scan(dest); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	return rowCount, nil
+}
+
+// SelectEach executes the prepared query statement with the given arguments and
+// calls fn once for each row returned by the query. The row passed to fn is a pointer
+// to a newly allocated struct of the statement's row type, so fn can safely retain it.
+//
+// If fn returns an error, iteration stops and SelectEach returns that error.
+// Unlike Select, the query results are never accumulated in a slice, which makes
+// SelectEach a good choice for queries that return a large number of rows.
+func (stmt *Stmt) SelectEach(db DB, fn func(row interface{}) error, args ...interface{}) (err error) {
+	if fn == nil {
+		return errors.New("nil func")
+	}
+	var rowCount int
+	qlog := stmt.newQueryLog()
+	defer func() { qlog.done(rowCount, err) }()
+	args, err = stmt.coerceSelectArgs(args)
+	if err != nil {
+		return err
+	}
+	query, err := stmt.expandQuery(stmt.query, args)
+	if err != nil {
+		return err
+	}
+	qlog.executing(query)
+	rows, err := query.QueryOn(db)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	outputs, err := stmt.getOutputs(rows)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		rowCount++
+		rowValuePtr := reflect.New(stmt.rowType)
+		if err := stmt.scanRow(rows, outputs, rowValuePtr.Elem()); err != nil {
+			return err
+		}
+		if err := fn(rowValuePtr.Interface()); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// SelectJSON executes the prepared query statement with the given arguments and
+// writes the rows returned by the query to w as a JSON array, with one JSON object
+// for each row. Rows are written as they are read, so the query results are never
+// accumulated in memory. SelectJSON returns the number of rows returned by the query.
+//
+// The name of each property in a JSON object is taken from the field's "json" struct
+// tag if it has one, otherwise it is the column name. Fields with a "json" struct tag
+// of "-" are omitted. Properties appear in the order that the fields are declared in
+// the row type, regardless of the order of the columns returned by the query.
+func (stmt *Stmt) SelectJSON(db DB, w io.Writer, args ...interface{}) (rowCount int, err error) {
+	qlog := stmt.newQueryLog()
+	defer func() { qlog.done(rowCount, err) }()
+	args, err = stmt.coerceSelectArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	query, err := stmt.expandQuery(stmt.query, args)
+	if err != nil {
+		return 0, err
+	}
+	qlog.executing(query)
+	rows, err := query.QueryOn(db)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	outputs, err := stmt.getOutputs(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	// work out the JSON property name for each output column
+	names := make([][]byte, len(outputs))
+	for i, col := range outputs {
+		name := stmt.columnNamer.ColumnName(col)
+		if tag := col.Field.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if tagName := strings.Split(tag, ",")[0]; tagName != "" {
+				name = tagName
+			}
+		}
+		if names[i], err = json.Marshal(name); err != nil {
+			return 0, err
+		}
+	}
+
+	// properties are written in the order the columns are declared, which
+	// is not necessarily the order of the columns returned by the driver
+	order := stmt.declaredOrder(outputs)
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for rows.Next() {
+		if rowCount > 0 {
+			buf.WriteByte(',')
+		}
+		rowCount++
+		rowValue := reflect.New(stmt.rowType).Elem()
+		if err := stmt.scanRow(rows, outputs, rowValue); err != nil {
+			return rowCount, err
+		}
+		buf.WriteByte('{')
+		var needComma bool
+		for _, i := range order {
+			col := outputs[i]
+			if names[i] == nil {
+				continue
+			}
+			data, err := json.Marshal(col.Index.ValueRO(rowValue).Interface())
+			if err != nil {
+				return rowCount, fmt.Errorf("cannot marshal field %q: %v", col.Field.Name, err)
+			}
+			if needComma {
+				buf.WriteByte(',')
+			}
+			needComma = true
+			buf.Write(names[i])
+			buf.WriteByte(':')
+			buf.Write(data)
+		}
+		buf.WriteByte('}')
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return rowCount, err
+		}
+		buf.Reset()
+	}
+	if err := rows.Err(); err != nil {
+		return rowCount, err
+	}
+	buf.WriteByte(']')
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return rowCount, err
+	}
+	return rowCount, nil
+}
+
+// declaredOrder returns the indexes of outputs in the order that the columns
+// are declared in the row type, which is the same order as a "{}" column
+// expansion. This keeps output deterministic regardless of the order of
+// the columns returned by the driver.
+func (stmt *Stmt) declaredOrder(outputs []*column.Info) []int {
+	pos := make(map[*column.Info]int, len(stmt.columns))
+	for i, col := range stmt.columns {
+		pos[col] = i
+	}
+	order := make([]int, len(outputs))
+	for i := range order {
+		order[i] = i
+	}
+	// insertion sort is fine for the number of columns in a table
+	for i := 1; i < len(order); i++ {
+		for j := i; j > 0 && pos[outputs[order[j]]] < pos[outputs[order[j-1]]]; j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+	return order
+}
+
+// emptyNull returns true if the column stores the empty value as NULL,
+// either because it is tagged, or because it is a string field and the
+// schema treats empty strings as NULL. Primary key columns never store NULL,
+// so a zero-valued key is always passed as its value.
+func (stmt *Stmt) emptyNull(col *column.Info) bool {
+	if col.Tag.PrimaryKey {
+		return false
+	}
+	if col.Tag.EmptyNull {
+		return true
+	}
+	if !stmt.emptyStringNull || col.Tag.NotNull {
+		return false
+	}
+	fieldType := col.Field.Type
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType.Kind() == reflect.String
+}
+
+// scanRow scans the current row in rows into rowValue, which must be an
+// addressable value of the statement's row type. It handles the columns that
+// require special treatment, such as JSON columns and columns where NULL
+// is stored as the empty value.
+func (stmt *Stmt) scanRow(rows *sql.Rows, outputs []*column.Info, rowValue reflect.Value) error {
+	scanValues := make([]interface{}, len(outputs))
+	var jsonCells []*jsonCell
+
 	for i, col := range outputs {
 		cellValue := col.Index.ValueRW(rowValue)
 		cellPtr := cellValue.Addr().Interface()
-		if col.Tag.JSON {
+		if col.BitGroup != "" {
+			scanValues[i] = &bitGroupCell{colname: col.BitGroup, cols: stmt.bitGroupColumns(col.BitGroup), rowValue: rowValue}
+		} else if col.Tag.Encrypt {
+			scanValues[i] = &encryptedCell{colname: col.Field.Name, cellValue: cellValue, encryptor: stmt.encryptor}
+		} else if col.Tag.JSON {
 			jc := newJSONCell(col.Field.Name, cellPtr)
+			jc.errorContext = stmt.jsonErrorContext
 			jsonCells = append(jsonCells, jc)
 			scanValues[i] = jc.ScanValue()
-		} else if col.Tag.EmptyNull {
+		} else if isNanoTime(col) {
+			scanValues[i] = &nanoTimeCell{colname: col.Field.Name, cellValue: cellValue}
+		} else if stmt.emptyNull(col) {
 			scanValues[i] = newNullCell(col.Field.Name, cellValue, cellPtr)
 		} else {
 			scanValues[i] = cellPtr
 		}
 	}
-	err = rows.Scan(scanValues...)
-	if err != nil {
-		return 0, err
+	if err := rows.Scan(scanValues...); err != nil {
+		return err
 	}
 	for _, jc := range jsonCells {
 		if err := jc.Unmarshal(); err != nil {
-			return rowCount, err
+			return err
 		}
 	}
+	return nil
+}
 
-	// count any additional rows
-	for rows.Next() {
-		rowCount++
-	}
+// columnCase is the letter case of the column names returned by the database driver.
+type columnCase int
 
-	return rowCount, nil
+const (
+	columnCaseAsIs columnCase = iota
+	columnCaseLower
+	columnCaseUpper
+)
+
+// normalize converts a column name to the letter case.
+func (c columnCase) normalize(name string) string {
+	switch c {
+	case columnCaseLower:
+		return strings.ToLower(name)
+	case columnCaseUpper:
+		return strings.ToUpper(name)
+	}
+	return name
 }
 
 func (stmt *Stmt) getOutputs(rows *sql.Rows) ([]*column.Info, error) {
@@ -371,7 +1034,7 @@ func (stmt *Stmt) getOutputs(rows *sql.Rows) ([]*column.Info, error) {
 
 	columnMap := make(map[string]*column.Info)
 	for _, col := range stmt.columns {
-		columnName := stmt.columnNamer.ColumnName(col)
+		columnName := stmt.columnCase.normalize(stmt.columnNamer.ColumnName(col))
 		columnMap[columnName] = col
 	}
 
@@ -379,10 +1042,17 @@ func (stmt *Stmt) getOutputs(rows *sql.Rows) ([]*column.Info, error) {
 	if err != nil {
 		return nil, err
 	}
+	stmt.output.columnNames = columnNames
 
 	outputs = make([]*column.Info, len(columnNames))
 	var columnNotFound = false
 	for i, columnName := range columnNames {
+		if col := stmt.aliasColumn(columnName); col != nil {
+			outputs[i] = col
+			delete(columnMap, stmt.columnCase.normalize(stmt.columnNamer.ColumnName(col)))
+			continue
+		}
+		columnName = stmt.columnCase.normalize(columnName)
 		col := columnMap[columnName]
 		if col == nil {
 			columnNotFound = true
@@ -414,7 +1084,7 @@ func (stmt *Stmt) getOutputs(rows *sql.Rows) ([]*column.Info, error) {
 			}
 			outputs[i] = col
 			delete(lowerColumnMap, columnNameLower)
-			delete(columnMap, stmt.columnNamer.ColumnName(col))
+			delete(columnMap, stmt.columnCase.normalize(stmt.columnNamer.ColumnName(col)))
 		}
 
 		if len(unknownColumnNames) == 1 {
@@ -424,14 +1094,19 @@ func (stmt *Stmt) getOutputs(rows *sql.Rows) ([]*column.Info, error) {
 			return nil, fmt.Errorf("unknown columns names=%q", strings.Join(unknownColumnNames, ","))
 		}
 	}
-	if len(columnMap) > 0 {
-		missingColumnNames := make([]string, 0, len(columnMap))
-		for columnName := range columnMap {
-			missingColumnNames = append(missingColumnNames, columnName)
-		}
-		if len(missingColumnNames) == 1 {
-			return nil, fmt.Errorf("missing column name=%q", missingColumnNames[0])
+	var missingColumnNames []string
+	for columnName, col := range columnMap {
+		if col.Tag.Lazy || col.Tag.Window {
+			// lazy columns are not selected by "select {}", and window
+			// columns are only present when the query computes them
+			continue
 		}
+		missingColumnNames = append(missingColumnNames, columnName)
+	}
+	if len(missingColumnNames) == 1 {
+		return nil, fmt.Errorf("missing column name=%q", missingColumnNames[0])
+	}
+	if len(missingColumnNames) > 0 {
 		return nil, fmt.Errorf("missing columns names=%s", strings.Join(missingColumnNames, ","))
 	}
 
@@ -439,11 +1114,52 @@ func (stmt *Stmt) getOutputs(rows *sql.Rows) ([]*column.Info, error) {
 	return stmt.output.columns, nil
 }
 
-func (stmt *Stmt) scanSQL(query string, renamer identRenamer) error {
-	query = strings.TrimSpace(query)
-	scan := scanner.New(strings.NewReader(query))
+// LastColumns returns the names of the columns returned by the database the
+// last time the statement's query was run, as reported by the driver. This is
+// useful for diagnosing errors such as "unknown column name", as it shows the
+// columns that the query actually returned. It returns nil if the query has
+// not been run.
+//
+// Once the columns have been successfully matched with the fields of the row
+// type, the statement remembers them, and the column names do not change.
+func (stmt *Stmt) LastColumns() []string {
+	stmt.output.mutex.RLock()
+	defer stmt.output.mutex.RUnlock()
+	if stmt.output.columnNames == nil {
+		return nil
+	}
+	return append([]string(nil), stmt.output.columnNames...)
+}
+
+// sqlTokens is a query scanned into tokens.
+type sqlTokens struct {
+	tokens            []sqlToken
+	colonPlaceholders bool // ":1" was scanned as a placeholder
+}
+
+type sqlToken struct {
+	tok scanner.Token
+	lit string
+}
+
+// scanTokens scans the query into tokens.
+func scanTokens(query string, colonPlaceholders bool) *sqlTokens {
+	scan := scanner.New(strings.NewReader(strings.TrimSpace(query)))
+	scan.ColonPlaceholders = colonPlaceholders
+	tokens := &sqlTokens{colonPlaceholders: colonPlaceholders}
+	for scan.Scan() {
+		tokens.tokens = append(tokens.tokens, sqlToken{tok: scan.Token(), lit: scan.Text()})
+	}
+	return tokens
+}
+
+// renderTokens renders the tokens of the source query as the statement's query,
+// expanding columns and numbering placeholders for the statement's dialect.
+func (stmt *Stmt) renderTokens() error {
+	renamer := stmt.renamer
 	columns := newColumns(stmt.columns)
-	var counter int
+	columns.tableAlias = inferTableAlias(stmt.rowType)
+	counter := stmt.placeholderOffset
 	counterNext := func() int { counter++; return counter }
 	var insertColumns *columnList
 	var clause sqlClause
@@ -455,23 +1171,93 @@ func (stmt *Stmt) scanSQL(query string, renamer identRenamer) error {
 		return name
 	}
 
-	for scan.Scan() {
-		tok, lit := scan.Token(), scan.Text()
+	// Keep track of the column most recently compared with, so that
+	// the type of the arg for a placeholder like "where id = ?" is known.
+	var columnMap map[string]*column.Info
+	lookupColumn := func(name string) *column.Info {
+		if columnMap == nil {
+			columnMap = make(map[string]*column.Info)
+			for _, col := range stmt.columns {
+				columnMap[strings.ToLower(stmt.columnNamer.ColumnName(col))] = col
+			}
+		}
+		return columnMap[strings.ToLower(name)]
+	}
+	var compareCol *column.Info
+	var comparing bool
+	insertTableStart := -1 // position in buf of the insert table name
+
+	for _, token := range stmt.tokens.tokens {
+		tok, lit := token.tok, token.lit
 		switch tok {
 		case scanner.WS:
 			buf.WriteRune(' ')
 		case scanner.COMMENT:
-			// strip comment
-		case scanner.LITERAL, scanner.OP:
+			if strings.HasPrefix(lit, "/*") {
+				// block comments are kept, as they may be optimizer hints
+				buf.WriteString(lit)
+			} else if stmt.preserveComments {
+				buf.WriteString(blockComment(lit))
+			}
+			// otherwise strip comment
+		case scanner.LITERAL:
 			buf.WriteString(lit)
+			compareCol = nil
+		case scanner.OP:
+			if lit == "??" {
+				// escaped question mark, eg for the postgres jsonb "?" operators
+				if stmt.dialect.Placeholder(1) == "?" {
+					return errors.New(`cannot use "??" for a question mark: dialect uses "?" for placeholders`)
+				}
+				buf.WriteRune('?')
+				compareCol = nil
+				continue
+			}
+			if lit == "(" && clause == clauseInsertColumns && insertTableStart >= 0 && stmt.insertTable == "" {
+				stmt.insertTable = strings.TrimSpace(buf.String()[insertTableStart:])
+			}
+			buf.WriteString(lit)
+			if compareCol != nil && strings.Trim(lit, "=<>") == "" {
+				comparing = true
+			} else {
+				compareCol = nil
+			}
 		case scanner.PLACEHOLDER:
 			// TODO(jpj): should parse the placeholder in case it is positional
 			// instead of just allocating it a number assuming it is not positional
 			buf.WriteString(stmt.dialect.Placeholder(counterNext()))
-			stmt.inputs = append(stmt.inputs, inputSource{argIndex: stmt.argCount})
+			input := inputSource{argIndex: stmt.argCount}
+			if comparing {
+				input.argCol = compareCol
+			}
+			stmt.inputs = append(stmt.inputs, input)
 			stmt.argCount++
+			compareCol = nil
 		case scanner.IDENT:
-			if lit[0] == '{' {
+			compareCol = nil
+			if lit[0] == '{' && isTimestampExpansion(lit) {
+				if clause != clauseInsertValues && clause != clauseUpdateSet {
+					return fmt.Errorf("cannot expand %q in %q clause", lit, clause)
+				}
+				col, err := stmt.timestampColumn(lit)
+				if err != nil {
+					return err
+				}
+				buf.WriteString(stmt.dialect.Placeholder(counterNext()))
+				stmt.inputs = append(stmt.inputs, inputSource{col: col, timestamp: true})
+			} else if lit[0] == '{' && isReturningExpansion(lit) {
+				returning, err := stmt.returningClause(lit, clause)
+				if err != nil {
+					return err
+				}
+				buf.WriteString(returning)
+			} else if lit[0] == '{' {
+				if clause == clauseUpdateTable && isSetExpansion(lit) {
+					// "{set ...}" includes the set keyword, eg "update t {set omit Role} where {}"
+					buf.WriteString("set ")
+					clause = clauseUpdateSet
+					lit = "{" + strings.TrimSpace(scanner.Unquote(lit))[len("set"):] + "}"
+				}
 				if !clause.acceptsColumns() {
 					// invalid place to insert columns
 					return fmt.Errorf("cannot expand %q in %q clause", lit, clause)
@@ -490,39 +1276,107 @@ func (stmt *Stmt) scanSQL(query string, renamer identRenamer) error {
 					// change the clause but keep the filter and generate string
 					cols := *insertColumns
 					cols.clause = clause
-					buf.WriteString(cols.String(stmt.dialect, stmt.columnNamer, counterNext))
+					buf.WriteString(cols.String(stmt.dialect, stmt.quoteMode, stmt.columnNamer, counterNext))
 					stmt.addInputColumns(cols)
 				} else {
 					cols, err := columns.Parse(clause, lit)
 					if err != nil {
 						return fmt.Errorf("cannot expand %q in %q clause: %v", lit, clause, err)
 					}
-					buf.WriteString(cols.String(stmt.dialect, stmt.columnNamer, counterNext))
+					if clause == clauseUpdateSet && len(stmt.omit) > 0 {
+						if cols, err = cols.omitting(stmt.omit); err != nil {
+							return err
+						}
+					}
+					if clause == clauseUpdateSet && len(cols.omit) > 0 && len(cols.filtered()) == 0 {
+						return fmt.Errorf("cannot expand %q in %q clause: all columns omitted", lit, clause)
+					}
+					buf.WriteString(cols.String(stmt.dialect, stmt.quoteMode, stmt.columnNamer, counterNext))
 					stmt.addInputColumns(cols)
 					if clause == clauseInsertColumns {
 						insertColumns = &cols
 					}
 				}
 			} else if scanner.IsQuoted(lit) {
+				if clause == clauseInsertColumns && insertTableStart < 0 {
+					insertTableStart = buf.Len()
+				}
 				lit = rename(scanner.Unquote(lit))
 				buf.WriteString(stmt.dialect.Quote(lit))
+				compareCol = lookupColumn(lit)
 			} else {
 				lit = rename(lit)
 				buf.WriteString(lit)
+				compareCol = lookupColumn(lit)
 
 				// An unquoted identifer might be an SQL keyword.
 				// Attempt to infer the SQL clause and query type.
 				clause = clause.nextClause(lit)
+				if clause == clauseInsertColumns && insertTableStart < 0 && !strings.EqualFold(lit, "insert") && !strings.EqualFold(lit, "into") {
+					insertTableStart = buf.Len() - len(lit)
+				}
 				if stmt.queryType == queryUnknown {
 					stmt.queryType = clause.queryType()
 				}
 			}
 		}
+		if compareCol == nil {
+			comparing = false
+		}
 	}
 	stmt.query = strings.TrimSpace(buf.String())
 	return nil
 }
 
+// isSetExpansion reports whether lit is a "{set ...}" expansion.
+func isSetExpansion(lit string) bool {
+	fields := strings.Fields(scanner.Unquote(lit))
+	return len(fields) > 0 && strings.ToLower(fields[0]) == "set"
+}
+
+// isTimestampExpansion reports whether lit is a "{ts FieldName}" expansion.
+func isTimestampExpansion(lit string) bool {
+	fields := strings.Fields(scanner.Unquote(lit))
+	return len(fields) > 0 && strings.ToLower(fields[0]) == "ts"
+}
+
+// blockComment converts a line comment, eg "-- comment\n", into a block
+// comment, eg "/* comment */", because white space (including the newline
+// that ends a line comment) is collapsed in the query.
+func blockComment(lineComment string) string {
+	text := strings.TrimSpace(strings.TrimPrefix(lineComment, "--"))
+	text = strings.Replace(text, "*/", "* /", -1)
+	comment := "/* " + text + " */"
+	if strings.HasSuffix(lineComment, "\n") {
+		comment += " "
+	}
+	return comment
+}
+
+// timestampColumn returns the column for a "{ts FieldName}" expansion, which is
+// replaced by a placeholder for the current time. The field can be identified by
+// its field name or its column name, and must have type time.Time or *time.Time.
+func (stmt *Stmt) timestampColumn(lit string) (*column.Info, error) {
+	fields := strings.Fields(scanner.Unquote(lit))
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("cannot expand %q: expected {ts FieldName}", lit)
+	}
+	name := fields[1]
+	for _, col := range stmt.columns {
+		if col.FieldNames == name || stmt.columnNamer.ColumnName(col) == name {
+			fieldType := col.Field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType != timeType {
+				return nil, fmt.Errorf("cannot expand %q: field %s is not time.Time", lit, col.FieldNames)
+			}
+			return col, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot expand %q: unknown field %q", lit, name)
+}
+
 func (stmt *Stmt) addInputColumns(cols columnList) {
 	if cols.clause.isInput() {
 		for _, col := range cols.filtered() {
@@ -539,7 +1393,7 @@ func (stmt *Stmt) getArgs(row interface{}, argv []interface{}) ([]interface{}, e
 	if len(argv) != stmt.argCount {
 		return nil, fmt.Errorf("expected arg count=%d, actual=%d", stmt.argCount, len(argv))
 	}
-	var args []interface{}
+	args := make([]interface{}, 0, len(stmt.inputs))
 
 	rowVal := reflect.ValueOf(row)
 	for rowVal.Type().Kind() == reflect.Ptr {
@@ -551,10 +1405,31 @@ func (stmt *Stmt) getArgs(row interface{}, argv []interface{}) ([]interface{}, e
 		return nil, fmt.Errorf("expected type %s or *(%s)", expectedType, expectedType)
 	}
 
+	// all timestamp inputs have the same value
+	var now time.Time
+
 	for _, input := range stmt.inputs {
-		if input.col != nil {
-			colVal := input.col.Index.ValueRO(rowVal)
-			if input.col.Tag.JSON {
+		if input.timestamp {
+			if now.IsZero() {
+				now = timeNow().UTC()
+			}
+			args = append(args, now)
+		} else if input.col != nil && input.col.BitGroup != "" {
+			args = append(args, stmt.bitGroupValue(rowVal, input.col.BitGroup))
+		} else if input.col != nil {
+			colVal := input.field.value(rowVal)
+			if stmt.enumValidation && len(input.col.Tag.Enum) > 0 {
+				if err := validateEnum(input.col, colVal, stmt.emptyNull(input.col)); err != nil {
+					return nil, err
+				}
+			}
+			if input.col.Tag.Encrypt {
+				arg, err := stmt.encryptValue(input.col.Field.Name, input.col.Tag.EncryptKeyID, colVal)
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+			} else if input.col.Tag.JSON {
 				// marshal field contents into JSON and pass as a byte array
 				valueRO := colVal.Interface()
 				if valueRO == nil {
@@ -568,26 +1443,100 @@ func (stmt *Stmt) getArgs(row interface{}, argv []interface{}) ([]interface{}, e
 					}
 					args = append(args, data)
 				}
-			} else if input.col.Tag.EmptyNull {
-				// TODO: store zero value with the column
-				zero := reflect.Zero(colVal.Type()).Interface()
-				ival := colVal.Interface()
-				if ival == zero {
-					args = append(args, nil)
-				} else {
-					args = append(args, ival)
-				}
+			} else if stmt.emptyNull(input.col) && input.field.isEmpty(colVal) {
+				args = append(args, nil)
+			} else if isNanoTime(input.col) {
+				args = append(args, nanoTimeValue(colVal))
 			} else {
-				args = append(args, colVal.Interface())
+				args = append(args, input.field.primitive(colVal))
 			}
 		} else {
-			args = append(args, argv[input.argIndex])
+			arg := argv[input.argIndex]
+			if stmt.argCoercion {
+				var err error
+				if arg, err = coerceArg(arg, input.argType()); err != nil {
+					return nil, fmt.Errorf("arg %d: %v", input.argIndex+1, err)
+				}
+			}
+			if input.nanoTime() {
+				arg = nanoTimeArg(arg)
+			}
+			args = append(args, primitiveValue(arg))
 		}
 	}
 
 	return args, nil
 }
 
+// primitiveValue converts a value whose type is a named type based on
+// a boolean, numeric or string type (eg "type Role int8") into a value of the
+// underlying primitive type. Some database drivers reject named types.
+// Values that implement the driver.Valuer interface are not converted.
+func primitiveValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if _, ok := v.(driver.Valuer); ok {
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type().PkgPath() == "" {
+		// not a named type, or a predeclared type such as int
+		return v
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	}
+	return v
+}
+
+// coerceSelectArgs returns args converted to the types expected by the
+// statement, if the statement has argument coercion enabled. For a select
+// statement, args are supplied for all inputs, including any inputs for
+// columns, eg "select {} from users where {}".
+//
+// Args compared with time columns that are stored as Unix nanoseconds are
+// converted, whether or not argument coercion is enabled.
+func (stmt *Stmt) coerceSelectArgs(args []interface{}) ([]interface{}, error) {
+	if len(args) != len(stmt.inputs) {
+		return args, nil
+	}
+	var coerced []interface{}
+	for i, arg := range args {
+		input := stmt.inputs[i]
+		if !stmt.argCoercion && !input.nanoTime() {
+			continue
+		}
+		if coerced == nil {
+			coerced = make([]interface{}, len(args))
+			copy(coerced, args)
+		}
+		if stmt.argCoercion {
+			var err error
+			if arg, err = coerceArg(arg, input.argType()); err != nil {
+				return nil, fmt.Errorf("arg %d: %v", i+1, err)
+			}
+		}
+		if input.nanoTime() {
+			arg = nanoTimeArg(arg)
+		}
+		coerced[i] = arg
+	}
+	if coerced == nil {
+		return args, nil
+	}
+	return coerced, nil
+}
+
 func (stmt *Stmt) expectedTypeName() string {
 	return fmt.Sprintf("%s.%s", stmt.rowType.PkgPath(), stmt.rowType.Name())
 }
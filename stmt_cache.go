@@ -3,12 +3,26 @@ package sqlr
 import (
 	"reflect"
 	"sync"
+
+	"github.com/jjeffery/sqlr/private/column"
 )
 
-// stmtCache is a cache of statements for a schema.
+// stmtCache is a cache of statements for a schema. It also caches
+// the column names determined by the schema's naming rules.
 type stmtCache struct {
 	mu    sync.RWMutex
 	stmts map[stmtKey]*Stmt
+
+	namesMu sync.RWMutex
+	names   map[columnNameKey]string
+	tables  map[reflect.Type]string // table names, guarded by namesMu
+}
+
+// columnNameKey identifies a cached column name. The table is only
+// set for tables that have their own naming convention.
+type columnNameKey struct {
+	col   *column.Info
+	table string
 }
 
 // stmtKey is the unique key used to identify statements within
@@ -24,6 +38,61 @@ func (c *stmtCache) clear() {
 	c.mu.Lock()
 	c.stmts = nil
 	c.mu.Unlock()
+	c.namesMu.Lock()
+	c.names = nil
+	c.tables = nil
+	c.namesMu.Unlock()
+}
+
+// columnName returns the column name for col in the table, calling fn if
+// the name is not in the cache. Calls to fn are serialized, and fn is called
+// at most once for each column and table, so fn does not have to be safe for
+// concurrent use.
+func (c *stmtCache) columnName(col *column.Info, table string, fn func(col *column.Info) string) string {
+	key := columnNameKey{col: col, table: table}
+	c.namesMu.RLock()
+	name, ok := c.names[key]
+	c.namesMu.RUnlock()
+	if ok {
+		return name
+	}
+
+	c.namesMu.Lock()
+	defer c.namesMu.Unlock()
+	if name, ok := c.names[key]; ok {
+		// another goroutine beat us to naming the column
+		return name
+	}
+	if c.names == nil {
+		c.names = make(map[columnNameKey]string)
+	}
+	name = fn(col)
+	c.names[key] = name
+	return name
+}
+
+// tableName returns the table name for the row type, calling fn if the name
+// is not in the cache. Calls to fn are serialized with the calls that name
+// columns, because both use the schema's naming convention.
+func (c *stmtCache) tableName(rowType reflect.Type, fn func(rowType reflect.Type) string) string {
+	c.namesMu.RLock()
+	name, ok := c.tables[rowType]
+	c.namesMu.RUnlock()
+	if ok {
+		return name
+	}
+
+	c.namesMu.Lock()
+	defer c.namesMu.Unlock()
+	if name, ok := c.tables[rowType]; ok {
+		return name
+	}
+	if c.tables == nil {
+		c.tables = make(map[reflect.Type]string)
+	}
+	name = fn(rowType)
+	c.tables[rowType] = name
+	return name
 }
 
 func (c *stmtCache) lookup(rowType reflect.Type, query string) (*Stmt, bool) {
@@ -0,0 +1,89 @@
+//go:build go1.8
+// +build go1.8
+
+package sqlr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PrepareContext creates a prepared statement at the driver level for the
+// statement's query. It is the same as Prepare, except that the context is
+// used for the preparation of the statement.
+func (stmt *Stmt) PrepareContext(ctx context.Context, db *sql.DB) (*sql.Stmt, error) {
+	return db.PrepareContext(ctx, stmt.query)
+}
+
+// SelectContext is the same as Select, except that the context is used for
+// the query. The db must be a *sql.DB, a *sql.Tx or another type with
+// QueryContext and ExecContext methods.
+func (stmt *Stmt) SelectContext(ctx context.Context, db DB, rows interface{}, args ...interface{}) (int, error) {
+	cdb, err := withContext(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	return stmt.Select(cdb, rows, args...)
+}
+
+// ExecContext is the same as Exec, except that the context is used for the
+// statement. The db must be a *sql.DB, a *sql.Tx or another type with
+// QueryContext and ExecContext methods.
+func (stmt *Stmt) ExecContext(ctx context.Context, db DB, row interface{}, args ...interface{}) (int, error) {
+	cdb, err := withContext(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	return stmt.Exec(cdb, row, args...)
+}
+
+// SelectTimeout is the same as Select, except that the query is canceled if it
+// does not complete within the timeout, including scanning the rows:
+//  n, err := stmt.SelectTimeout(db, &rows, 5*time.Second, args...)
+// It is shorthand for calling SelectContext with a context from context.WithTimeout.
+func (stmt *Stmt) SelectTimeout(db DB, rows interface{}, timeout time.Duration, args ...interface{}) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return stmt.SelectContext(ctx, db, rows, args...)
+}
+
+// ExecTimeout is the same as Exec, except that the statement is canceled if it
+// does not complete within the timeout. It is shorthand for calling ExecContext
+// with a context from context.WithTimeout.
+func (stmt *Stmt) ExecTimeout(db DB, row interface{}, timeout time.Duration, args ...interface{}) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return stmt.ExecContext(ctx, db, row, args...)
+}
+
+// contextDB implements the DB interface by calling the context methods
+// of a *sql.DB or *sql.Tx with a context.
+type contextDB struct {
+	ctx context.Context
+	db  interface {
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	}
+}
+
+// withContext returns a DB that uses ctx for the queries sent to db.
+func withContext(ctx context.Context, db DB) (DB, error) {
+	cdb, ok := db.(interface {
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	})
+	if !ok {
+		return nil, errors.New("db does not support contexts")
+	}
+	return contextDB{ctx: ctx, db: cdb}, nil
+}
+
+func (c contextDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.db.ExecContext(c.ctx, query, args...)
+}
+
+func (c contextDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.db.QueryContext(c.ctx, query, args...)
+}
@@ -0,0 +1,106 @@
+//go:build go1.8
+// +build go1.8
+
+package sqlr
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestStmtPrepare(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+	stmt, err := schema.Prepare(Row{}, "update rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := regexp.QuoteMeta(`update rows set "name"=$1 where "id"=$2`)
+
+	mock.ExpectPrepare(query).ExpectExec().WithArgs("one", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlStmt, err := stmt.Prepare(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlStmt.Exec("one", 1); err != nil {
+		t.Fatal(err)
+	}
+	sqlStmt.Close()
+
+	mock.ExpectPrepare(query)
+	sqlStmt, err = stmt.PrepareContext(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlStmt.Close()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStmtTimeout(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	selectStmt, err := schema.Prepare(Row{}, "select {} from rows where id = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`select "id","name" from rows where id = $1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "one"))
+	var rows []Row
+	n, err := selectStmt.SelectTimeout(db, &rows, time.Second, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := n, 1; got != want {
+		t.Errorf("want=%d, got=%d", want, got)
+	}
+
+	updateStmt, err := schema.Prepare(Row{}, "update rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectExec(regexp.QuoteMeta(`update rows set "name"=$1 where "id"=$2`)).
+		WithArgs("one", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if n, err = updateStmt.ExecTimeout(db, &Row{ID: 1, Name: "one"}, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := n, 1; got != want {
+		t.Errorf("want=%d, got=%d", want, got)
+	}
+
+	// the query is not sent if the context has already expired
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := selectStmt.SelectContext(ctx, db, &rows, 1); err != context.Canceled {
+		t.Errorf("want=%v, got=%v", context.Canceled, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,201 @@
+package sqlr
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/jjeffery/sqlr/private/column"
+)
+
+// StatementCache is an interface for a cache of statement metadata. A schema
+// consults its statement cache before parsing a query, which can be useful for
+// reducing the work performed when a program starts, for example in a serverless
+// environment. Implementations must be safe for concurrent use by multiple goroutines.
+//
+// The key is derived from the row type, the query, the column names and the schema
+// options that affect the rendered query, such as the dialect. Schemas with different
+// options, including clones of a schema, can share a statement cache.
+type StatementCache interface {
+	Get(key string) (*StmtMetadata, bool)
+	Set(key string, m *StmtMetadata)
+}
+
+// StmtMetadata is a serializable form of a prepared statement. It contains
+// the information obtained by parsing the query, so that a statement can be
+// created without parsing the query again.
+type StmtMetadata struct {
+	Query     string      `json:"query"`               // query with columns expanded
+	QueryType string      `json:"queryType,omitempty"` // "insert", "update", "delete", "select" or empty
+	Inputs    []StmtInput `json:"inputs,omitempty"`    // one for each placeholder in Query
+	ArgCount  int         `json:"argCount"`            // number of args expected
+	Source    string      `json:"source,omitempty"`    // query before columns were expanded
+	Table     string      `json:"table,omitempty"`     // table name for an insert statement
+}
+
+// StmtInput describes the source of the value for a placeholder in a statement.
+// Columns are identified by their field names, joined by periods.
+type StmtInput struct {
+	Field     string `json:"field,omitempty"`     // field for the column, empty if an arg
+	Arg       int    `json:"arg,omitempty"`       // index into the args, used only if Field is empty
+	ArgField  string `json:"argField,omitempty"`  // field for the column compared with the arg, if known
+	Timestamp bool   `json:"timestamp,omitempty"` // value is the current time, for a "{ts}" expansion
+}
+
+var queryTypeNames = map[queryType]string{
+	queryInsert: "insert",
+	queryUpdate: "update",
+	queryDelete: "delete",
+	querySelect: "select",
+}
+
+// metadata returns the statement's metadata.
+func (stmt *Stmt) metadata() *StmtMetadata {
+	m := &StmtMetadata{
+		Query:     stmt.query,
+		QueryType: queryTypeNames[stmt.queryType],
+		ArgCount:  stmt.argCount,
+		Source:    stmt.source,
+		Table:     stmt.insertTable,
+	}
+	for _, input := range stmt.inputs {
+		var mi StmtInput
+		if input.col != nil {
+			mi.Field = input.col.FieldNames
+			mi.Timestamp = input.timestamp
+		} else {
+			mi.Arg = input.argIndex
+			if input.argCol != nil {
+				mi.ArgField = input.argCol.FieldNames
+			}
+		}
+		m.Inputs = append(m.Inputs, mi)
+	}
+	return m
+}
+
+// newStmtFromMetadata creates a new statement for the row type from
+// metadata previously obtained by parsing the query. It returns an error
+// if the metadata does not match the row type.
+func newStmtFromMetadata(dialect Dialect, colNamer columnNamer, renamer identRenamer, rowType reflect.Type, columns []*column.Info, m *StmtMetadata, preserveComments bool, quoteMode QuoteMode, wrapIdentity bool) (*Stmt, error) {
+	stmt := &Stmt{
+		dialect:          dialect,
+		columnNamer:      colNamer,
+		rowType:          rowType,
+		query:            m.Query,
+		argCount:         m.ArgCount,
+		source:           m.Source,
+		renamer:          renamer,
+		insertTable:      m.Table,
+		preserveComments: preserveComments,
+		quoteMode:        quoteMode,
+		wrapIdentity:     wrapIdentity,
+	}
+	stmt.columns = columns
+	for qt, name := range queryTypeNames {
+		if name == m.QueryType {
+			stmt.queryType = qt
+		}
+	}
+	columnMap := make(map[string]*column.Info, len(stmt.columns))
+	for _, col := range stmt.columns {
+		columnMap[col.FieldNames] = col
+	}
+	lookup := func(field string) (*column.Info, error) {
+		if field == "" {
+			return nil, nil
+		}
+		col, ok := columnMap[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q for type %s", field, stmt.expectedTypeName())
+		}
+		return col, nil
+	}
+	for _, mi := range m.Inputs {
+		input := inputSource{timestamp: mi.Timestamp}
+		var err error
+		if input.col, err = lookup(mi.Field); err != nil {
+			return nil, err
+		}
+		if input.col == nil {
+			if mi.Arg < 0 || mi.Arg >= m.ArgCount {
+				return nil, fmt.Errorf("invalid arg index %d", mi.Arg)
+			}
+			input.argIndex = mi.Arg
+			if input.argCol, err = lookup(mi.ArgField); err != nil {
+				return nil, err
+			}
+		}
+		stmt.inputs = append(stmt.inputs, input)
+	}
+	stmt.setAutoIncrColumn()
+	stmt.resolveInputs()
+	return stmt, nil
+}
+
+// statementCacheKey returns the key used to identify a statement in a
+// StatementCache. The key includes a description of the row type's columns,
+// because different row types can have the same name, and the column names,
+// because they depend on the naming convention and other schema options.
+func statementCacheKey(rowType reflect.Type, columns []*column.Info, namer columnNamer, schemaKey string, query string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s.%s\x00%s\x00%s\x00", rowType.PkgPath(), rowType.Name(), schemaKey, query)
+	for _, col := range columns {
+		fmt.Fprintf(h, "%s\x00%s\x00%s\x00%t\x00%s\x00", col.FieldNames, col.Field.Type, col.Field.Tag, col.Tag.JSON, namer.ColumnName(col))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// inMemoryCache is a StatementCache that stores statement metadata in memory.
+type inMemoryCache struct {
+	mu      sync.Mutex
+	maxSize int
+	list    *list.List // most recently used at the front
+	items   map[string]*list.Element
+}
+
+type inMemoryCacheItem struct {
+	key string
+	m   *StmtMetadata
+}
+
+// NewInMemoryCache returns a statement cache that stores statement metadata in
+// memory. If maxSize is greater than zero, the least recently used metadata is
+// discarded when the cache holds more than maxSize items.
+func NewInMemoryCache(maxSize int) StatementCache {
+	return &inMemoryCache{
+		maxSize: maxSize,
+		list:    list.New(),
+		items:   make(map[string]*list.Element),
+	}
+}
+
+func (c *inMemoryCache) Get(key string) (*StmtMetadata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.list.MoveToFront(elem)
+	return elem.Value.(*inMemoryCacheItem).m, true
+}
+
+func (c *inMemoryCache) Set(key string, m *StmtMetadata) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*inMemoryCacheItem).m = m
+		c.list.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.list.PushFront(&inMemoryCacheItem{key: key, m: m})
+	for c.maxSize > 0 && c.list.Len() > c.maxSize {
+		elem := c.list.Back()
+		c.list.Remove(elem)
+		delete(c.items, elem.Value.(*inMemoryCacheItem).key)
+	}
+}
@@ -0,0 +1,187 @@
+package sqlr
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestStmtMetadata(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key autoincrement"`
+		Name string
+	}
+	schema := NewSchema(WithDialect(Postgres))
+	tests := []string{
+		"insert into rows({}) values({})",
+		"update rows set {} where {} and name <> ?",
+		"select {} from rows where name = ? limit ?",
+		"delete from rows where {}",
+	}
+	for i, query := range tests {
+		stmt, err := schema.Prepare(Row{}, query)
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		// round trip via JSON to ensure that the metadata is serializable
+		data, err := json.Marshal(stmt.metadata())
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		var m StmtMetadata
+		if err := json.Unmarshal(data, &m); err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		stmt2, err := newStmtFromMetadata(stmt.dialect, stmt.columnNamer, stmt.renamer, stmt.rowType, stmt.columns, &m, stmt.preserveComments, stmt.quoteMode, stmt.wrapIdentity)
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		if got, want := stmt2.query, stmt.query; got != want {
+			t.Errorf("%d: query: want=%q, got=%q", i, want, got)
+		}
+		if got, want := stmt2.queryType, stmt.queryType; got != want {
+			t.Errorf("%d: query type: want=%v, got=%v", i, want, got)
+		}
+		if got, want := stmt2.argCount, stmt.argCount; got != want {
+			t.Errorf("%d: arg count: want=%d, got=%d", i, want, got)
+		}
+		if got, want := stmt2.autoIncrColumn, stmt.autoIncrColumn; got != want {
+			t.Errorf("%d: auto-increment column: want=%v, got=%v", i, want, got)
+		}
+		if got, want := len(stmt2.inputs), len(stmt.inputs); got != want {
+			t.Errorf("%d: inputs: want=%d, got=%d", i, want, got)
+			continue
+		}
+		for j := range stmt.inputs {
+			if got, want := stmt2.inputs[j], stmt.inputs[j]; !reflect.DeepEqual(got, want) {
+				t.Errorf("%d: input %d: want=%+v, got=%+v", i, j, want, got)
+			}
+		}
+	}
+}
+
+func TestWithCache(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	cache := NewInMemoryCache(0)
+	schema := NewSchema(WithDialect(MySQL), WithCache(cache))
+	stmt, err := schema.Prepare(Row{}, "select {} from rows where {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := statementCacheKey(stmt.rowType, stmt.columns, stmt.columnNamer, schema.statementSchemaKey(), "select {} from rows where {}")
+	m, ok := cache.Get(key)
+	if !ok {
+		t.Fatal("want statement metadata in cache, got none")
+	}
+	if got, want := m.Query, stmt.String(); got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	// another schema with the same cache uses the metadata without parsing
+	m.Query = "select `id`,`name` from cached_rows where `id`=?"
+	stmt, err = NewSchema(WithDialect(MySQL), WithCache(cache)).Prepare(Row{}, "select {} from rows where {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), m.Query; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	// metadata that does not match the row type is ignored
+	cache.Set(key, &StmtMetadata{
+		Query:    "select {} from rows where {}",
+		Inputs:   []StmtInput{{Field: "Missing"}},
+		ArgCount: 0,
+	})
+	stmt, err = NewSchema(WithDialect(MySQL), WithCache(cache)).Prepare(Row{}, "select {} from rows where {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), "select `id`,`name` from rows where `id`=?"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
+
+func TestWithCacheQuoteNever(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	cache := NewInMemoryCache(0)
+	newSchema := func() *Schema {
+		return NewSchema(WithDialect(Postgres), WithIdentifierQuoting(QuoteNever), WithCache(cache))
+	}
+	want := "update rows set name=$1 where id=$2"
+	for i := 0; i < 2; i++ {
+		// the second schema creates the statement from the cached metadata
+		stmt, err := newSchema().Prepare(Row{}, "update rows set {} where {}")
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if got := stmt.String(); got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		variant, err := stmt.WithPlaceholderOffset(2)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if got, want := variant.String(), "update rows set name=$3 where id=$4"; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+}
+
+func TestWithCacheClone(t *testing.T) {
+	type Row struct {
+		ID       int `sql:"primary key"`
+		FullName string
+	}
+	parent := NewSchema(WithDialect(MySQL), WithCache(NewInMemoryCache(0)))
+	tests := []struct {
+		schema *Schema
+		want   string
+	}{
+		{parent, "select `id`,`full_name` from rows where `id`=?"},
+		{parent.Clone(WithDialect(Postgres), WithNamingConvention(SameCase)), `select "ID","FullName" from rows where "ID"=$1`},
+		{parent.Clone(WithDialect(Postgres)), `select "id","full_name" from rows where "id"=$1`},
+		{parent.Clone(WithField("FullName", "name")), "select `id`,`name` from rows where `id`=?"},
+		{parent.Clone(WithIdentifier("row_table", "rows")), "select `id`,`full_name` from row_table where `id`=?"},
+		{parent.Clone(WithDefaultLimit(10)), "select `id`,`full_name` from rows where `id`=? limit 10"},
+		{parent.Clone(), "select `id`,`full_name` from rows where `id`=?"},
+	}
+	for i, tt := range tests {
+		stmt, err := tt.schema.Prepare(Row{}, "select {} from rows where {}")
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got := stmt.String(); got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+}
+
+func TestInMemoryCache(t *testing.T) {
+	cache := NewInMemoryCache(2)
+	cache.Set("a", &StmtMetadata{Query: "a"})
+	cache.Set("b", &StmtMetadata{Query: "b"})
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("want a in cache")
+	}
+	cache.Set("c", &StmtMetadata{Query: "c"})
+	if _, ok := cache.Get("b"); ok {
+		t.Error("want b discarded from cache")
+	}
+	for _, key := range []string{"a", "c"} {
+		if m, ok := cache.Get(key); !ok || m.Query != key {
+			t.Errorf("want %s in cache", key)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package sqlr
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// A StmtOption is an option that applies to a single execution of a
+// statement. Statement options are passed to Exec along with any args.
+type StmtOption func(opts *stmtOptions)
+
+// stmtOptions contains the options for executing a statement.
+type stmtOptions struct {
+	omit []string
+}
+
+// WithOmit creates an option that excludes the columns for the named fields
+// from the set clause of an update statement. This makes it possible to use
+// one prepared update statement to update different subsets of columns:
+//  n, err := stmt.Exec(db, row, sqlr.WithOmit("Role", "CreatedAt"))
+//
+// Fields can also be omitted when the statement is prepared, using the
+// "omit" keyword in the column expansion:
+//  stmt, err := schema.Prepare(row, "update users set {omit Role} where {}")
+func WithOmit(fieldNames ...string) StmtOption {
+	return func(opts *stmtOptions) {
+		opts.omit = append(opts.omit, fieldNames...)
+	}
+}
+
+// splitStmtOptions separates any statement options from the args.
+func splitStmtOptions(args []interface{}) ([]interface{}, stmtOptions) {
+	var opts stmtOptions
+	var found bool
+	for _, arg := range args {
+		if _, ok := arg.(StmtOption); ok {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return args, opts
+	}
+	remaining := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		if opt, ok := arg.(StmtOption); ok {
+			if opt != nil {
+				opt(&opts)
+			}
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return remaining, opts
+}
+
+// omitting returns a variant of the update statement that excludes the
+// columns for the named fields from the set clause. Variants are prepared
+// once and remembered.
+func (stmt *Stmt) omitting(fieldNames []string) (*Stmt, error) {
+	if stmt.queryType != queryUpdate {
+		return nil, errors.New("cannot omit fields: not an update statement")
+	}
+	if stmt.source == "" {
+		return nil, errors.New("cannot omit fields: statement query is not known")
+	}
+	omit := make([]string, 0, len(stmt.omit)+len(fieldNames))
+	omit = append(omit, stmt.omit...)
+	omit = append(omit, fieldNames...)
+	sort.Strings(omit)
+	key := strings.Join(omit, ",")
+
+	stmt.variants.mutex.Lock()
+	defer stmt.variants.mutex.Unlock()
+	if variant, ok := stmt.variants.stmts[key]; ok {
+		return variant, nil
+	}
+
+	variant := stmt.clone()
+	variant.omit = omit
+	if err := variant.render(); err != nil {
+		return nil, err
+	}
+
+	if stmt.variants.stmts == nil {
+		stmt.variants.stmts = make(map[string]*Stmt)
+	}
+	stmt.variants.stmts[key] = variant
+	return variant, nil
+}
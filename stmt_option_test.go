@@ -0,0 +1,135 @@
+package sqlr
+
+import (
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestOmitExpansion(t *testing.T) {
+	type Row struct {
+		ID        int `sql:"primary key"`
+		Name      string
+		Email     string
+		Role      string
+		CreatedAt string
+	}
+	tests := []struct {
+		query   string
+		want    string
+		errText string
+	}{
+		{
+			query: "update users set {omit Role} where {}",
+			want:  "update users set `name`=?,`email`=?,`created_at`=? where `id`=?",
+		},
+		{
+			query: "update users {set omit Role, CreatedAt} where {}",
+			want:  "update users set `name`=?,`email`=? where `id`=?",
+		},
+		{
+			query: "update users {set} where {}",
+			want:  "update users set `name`=?,`email`=?,`role`=?,`created_at`=? where `id`=?",
+		},
+		{
+			query:   "update users set {omit Missing} where {}",
+			errText: `cannot expand "omit Missing" in "update set" clause: cannot omit unknown field "Missing"`,
+		},
+		{
+			query:   "update users set {omit} where {}",
+			errText: `cannot expand "omit" in "update set" clause: missing field names after 'omit'`,
+		},
+		{
+			query:   "update users set {omit Name,Email,Role,CreatedAt} where {}",
+			errText: `cannot expand "omit Name,Email,Role,CreatedAt" in "update set" clause: all columns omitted`,
+		},
+	}
+	schema := NewSchema(WithDialect(MySQL))
+	for i, tt := range tests {
+		stmt, err := schema.Prepare(Row{}, tt.query)
+		if err != nil {
+			if got, want := err.Error(), tt.errText; got != want {
+				t.Errorf("%d: want=%q, got=%q", i, want, got)
+			}
+			continue
+		}
+		if tt.errText != "" {
+			t.Errorf("%d: want error %q, got none", i, tt.errText)
+			continue
+		}
+		if got := stmt.String(); got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+	}
+}
+
+func TestWithOmit(t *testing.T) {
+	type Row struct {
+		ID    int `sql:"primary key"`
+		Name  string
+		Email string
+		Role  string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL))
+	stmt, err := schema.Prepare(Row{}, "update users set {} where {} and role <> ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := Row{ID: 1, Name: "Alice", Email: "alice@example.com", Role: "admin"}
+
+	mock.ExpectExec(regexp.QuoteMeta("update users set `name`=?,`email`=? where `id`=? and role <> ?")).
+		WithArgs("Alice", "alice@example.com", 1, "root").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := stmt.Exec(db, &row, WithOmit("Role"), "root"); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("update users set `name`=? where `id`=? and role <> ?")).
+		WithArgs("Alice", 1, "root").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := stmt.Exec(db, &row, "root", WithOmit("Role", "Email")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the original statement is unchanged
+	mock.ExpectExec(regexp.QuoteMeta("update users set `name`=?,`email`=?,`role`=? where `id`=? and role <> ?")).
+		WithArgs("Alice", "alice@example.com", "admin", 1, "root").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := stmt.Exec(db, &row, "root"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	// variants are remembered
+	v1, err := stmt.omitting([]string{"Role", "Email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := stmt.omitting([]string{"Email", "Role"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v1 != v2 {
+		t.Error("want same variant for the same fields")
+	}
+
+	if _, err := stmt.Exec(db, &row, WithOmit("Unknown"), "root"); err == nil {
+		t.Error("want error for unknown field, got nil")
+	}
+	insert, err := schema.Prepare(Row{}, "insert users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := insert.Exec(db, &row, WithOmit("Role")); err == nil {
+		t.Error("want error for insert statement, got nil")
+	}
+}
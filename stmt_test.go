@@ -1,8 +1,19 @@
 package sqlr
 
 import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/jjeffery/sqlr/private/column"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
 )
 
 func TestInferRowType(t *testing.T) {
@@ -31,6 +42,26 @@ func TestInferRowType(t *testing.T) {
 			row:     []*Row{},
 			rowType: reflect.TypeOf(Row{}),
 		},
+		{
+			row:     (*Row)(nil),
+			rowType: reflect.TypeOf(Row{}),
+		},
+		{
+			row:     new(*Row),
+			rowType: reflect.TypeOf(Row{}),
+		},
+		{
+			row:     &[]*Row{},
+			rowType: reflect.TypeOf(Row{}),
+		},
+		{
+			row:     nil,
+			errText: "expected arg to refer to a struct type",
+		},
+		{
+			row:     new(int),
+			errText: "expected arg to refer to a struct type",
+		},
 	}
 
 	for i, tt := range tests {
@@ -47,10 +78,35 @@ func TestInferRowType(t *testing.T) {
 	}
 }
 
+func TestPreparePointer(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	schema := NewSchema(WithDialect(MySQL))
+	row := &Row{}
+	var stmts []*Stmt
+	for i, r := range []interface{}{Row{}, &Row{}, &row, (*Row)(nil)} {
+		stmt, err := schema.Prepare(r, "select {} from rows where {}")
+		if err != nil {
+			t.Fatalf("%d: want no error, got %v", i, err)
+		}
+		stmts = append(stmts, stmt)
+	}
+	for i, stmt := range stmts {
+		if stmt != stmts[0] {
+			t.Errorf("%d: want same statement, got %q", i, stmt)
+		}
+	}
+}
+
 func TestPrepare(t *testing.T) {
 	dialects := map[string]Dialect{
 		"mysql":    MySQL,
 		"postgres": Postgres,
+		"mssql":    MSSQL,
+		"sqlite":   SQLite,
+		"ansi":     ANSISQL,
 	}
 	tests := []struct {
 		row     interface{}
@@ -125,6 +181,18 @@ func TestPrepare(t *testing.T) {
 				"postgres": `update "xxx" set "name"=$1,"count"=$2 where "id"=$3 and "hash"=$4`,
 			},
 		},
+		{
+			row: struct {
+				ID        string `sql:"primary key auto increment"`
+				Name      string
+				UpdatedAt time.Time `sql:"on_update:CURRENT_TIMESTAMP"`
+			}{},
+			sql: "update tbl",
+			queries: map[string]string{
+				"mysql":    "update tbl set `name`=? where `id`=?",
+				"postgres": `update tbl set "name"=$1 where "id"=$2`,
+			},
+		},
 		{
 			row: struct {
 				ID   string `sql:"primary key auto increment"`
@@ -171,6 +239,17 @@ func TestPrepare(t *testing.T) {
 				"postgres": `select t."id",t."name" from tbl t where t."id"=$1`,
 			},
 		},
+		{
+			row: struct {
+				ID   string `sql:"primary key auto increment"`
+				Name string
+			}{},
+			sql: "select {nopk} from tbl where {}",
+			queries: map[string]string{
+				"mysql":    "select `name` from tbl where `id`=?",
+				"postgres": `select "name" from tbl where "id"=$1`,
+			},
+		},
 		{
 			row: struct {
 				ID   string `sql:"primary key auto increment"`
@@ -197,6 +276,45 @@ func TestPrepare(t *testing.T) {
 				"postgres": `select "id","hash","name","count" from "xxx" where "id"=$1 and "hash"=$2`,
 			},
 		},
+		{
+			row: struct {
+				ID     int     `sql:"primary key"`
+				Tenant *string `sql:"pk nullsafe"`
+				Name   string
+			}{},
+			sql: "update tbl",
+			queries: map[string]string{
+				"mysql":    "update tbl set `name`=? where `id`=? and `tenant`<=>?",
+				"postgres": `update tbl set "name"=$1 where "id"=$2 and ("tenant" = $3 or ("tenant" is null and $3 is null))`,
+				"mssql":    "update tbl set [name]=? where [id]=? and exists (select [tenant] intersect select ?)",
+				"sqlite":   "update tbl set `name`=? where `id`=? and `tenant` is ?",
+				"ansi":     `update tbl set "name"=? where "id"=? and "tenant" is not distinct from ?`,
+			},
+		},
+		{
+			row: struct {
+				ID        string `sql:"primary key auto increment"`
+				Name      string
+				CreatedBy string `sql:"created_by immutable"`
+			}{},
+			sql: "insert tbl",
+			queries: map[string]string{
+				"mysql":    "insert into tbl(`name`,`created_by`) values(?,?)",
+				"postgres": `insert into tbl("name","created_by") values($1,$2)`,
+			},
+		},
+		{
+			row: struct {
+				ID        string `sql:"primary key auto increment"`
+				Name      string
+				CreatedBy string `sql:"created_by immutable"`
+			}{},
+			sql: "update tbl set {all} where {}",
+			queries: map[string]string{
+				"mysql":    "update tbl set `id`=?,`name`=? where `id`=?",
+				"postgres": `update tbl set "id"=$1,"name"=$2 where "id"=$3`,
+			},
+		},
 	}
 
 	for i, tt := range tests {
@@ -214,3 +332,972 @@ func TestPrepare(t *testing.T) {
 		}
 	}
 }
+
+func TestSelectEach(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL))
+
+	mock.ExpectQuery("select `id`,`name` from rows").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(1, "one").
+			AddRow(2, "two").
+			AddRow(3, "three"))
+
+	var names []string
+	err = schema.SelectEach(db, Row{}, func(row interface{}) error {
+		names = append(names, row.(*Row).Name)
+		return nil
+	}, "select {} from rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(names, ","), "one,two,three"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	mock.ExpectQuery("select `id`,`name` from rows").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(1, "one").
+			AddRow(2, "two"))
+
+	stopErr := errors.New("stop")
+	var count int
+	err = schema.SelectEach(db, Row{}, func(row interface{}) error {
+		count++
+		return stopErr
+	}, "select {} from rows")
+	if got, want := err, stopErr; got != want {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+	if got, want := count, 1; got != want {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSelectScalar(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	mock.ExpectQuery(`select count\(\*\) from rows where name = \$1`).
+		WithArgs("xxx").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	var count int
+	n, err := schema.SelectScalar(db, Row{}, &count, "select count(*) from rows where name = ?", "xxx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := n, 1; got != want {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+	if got, want := count, 3; got != want {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+
+	mock.ExpectQuery(`select id, name from rows`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "one"))
+
+	_, err = schema.SelectScalar(db, Row{}, &count, "select id, name from rows")
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+	if got, want := err.Error(), "expected one column, got 2"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSelectJSON(t *testing.T) {
+	type Row struct {
+		ID       int    `sql:"primary key" json:"id"`
+		Name     string `json:",omitempty"`
+		Secret   string `json:"-"`
+		Verified bool
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	columns := []string{"id", "name", "secret", "verified"}
+	mock.ExpectQuery(`select "id","name","secret","verified" from rows where name like \$1`).
+		WithArgs("o%").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "one", "s1", true).
+			AddRow(2, "", "s2", false))
+
+	var buf bytes.Buffer
+	n, err := schema.SelectJSON(db, Row{}, &buf, "select {} from rows where name like ?", "o%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := n, 2; got != want {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+	want := `[{"id":1,"name":"one","verified":true},{"id":2,"name":"","verified":false}]`
+	if got := buf.String(); got != want {
+		t.Errorf("want=%s, got=%s", want, got)
+	}
+
+	// properties follow the declared order, not the order returned by the query
+	mock.ExpectQuery(`select verified, secret, name, id from rows`).
+		WillReturnRows(sqlmock.NewRows([]string{"verified", "secret", "name", "id"}).AddRow(true, "s3", "three", 3))
+
+	buf.Reset()
+	if _, err := schema.SelectJSON(db, Row{}, &buf, "select verified, secret, name, id from rows"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), `[{"id":3,"name":"three","verified":true}]`; got != want {
+		t.Errorf("want=%s, got=%s", want, got)
+	}
+
+	mock.ExpectQuery(`select "id","name","secret","verified" from rows`).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	buf.Reset()
+	if _, err := schema.SelectJSON(db, Row{}, &buf, "select {} from rows"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "[]"; got != want {
+		t.Errorf("want=%s, got=%s", want, got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+type testRole int8
+
+type testCode string
+
+type testValuerCode string
+
+func (c testValuerCode) Value() (driver.Value, error) { return "valuer:" + string(c), nil }
+
+func TestPrimitiveValue(t *testing.T) {
+	var nilPtr *int
+	tests := []struct {
+		v    interface{}
+		want interface{}
+	}{
+		{v: testRole(3), want: int64(3)},
+		{v: testCode("abc"), want: "abc"},
+		{v: testValuerCode("abc"), want: testValuerCode("abc")},
+		{v: 3, want: 3},
+		{v: "abc", want: "abc"},
+		{v: nil, want: nil},
+		{v: nilPtr, want: nilPtr},
+	}
+	for i, tt := range tests {
+		if got := primitiveValue(tt.v); got != tt.want {
+			t.Errorf("%d: want=%#v, got=%#v", i, tt.want, got)
+		}
+	}
+}
+
+func TestNamedTypeArgs(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Role testRole
+		Code testCode `sql:"emptynull"`
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL))
+
+	mock.ExpectExec("insert into rows\\(`id`,`role`,`code`\\) values\\(\\?,\\?,\\?\\)").
+		WithArgs(1, int64(2), "XY").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	row := Row{ID: 1, Role: 2, Code: "XY"}
+	if _, err := schema.Exec(db, &row, "insert rows"); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery("select `id`,`role`,`code` from rows where `id`=\\?").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "role", "code"}).AddRow(1, 2, "XY"))
+
+	var row2 Row
+	if _, err := schema.Select(db, &row2, "select rows", 1); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := row2, row; got != want {
+		t.Errorf("want=%+v, got=%+v", want, got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSelectOne(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL))
+
+	mock.ExpectQuery("select `id`,`name` from rows where `id`=\\?").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(1, "one").
+			AddRow(2, "two"))
+
+	var row Row
+	if err := schema.SelectOne(db, &row, "select rows", 1); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := row, (Row{ID: 1, Name: "one"}); got != want {
+		t.Errorf("want=%+v, got=%+v", want, got)
+	}
+
+	mock.ExpectQuery("select `id`,`name` from rows where `id`=\\?").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+
+	if got, want := schema.SelectOne(db, &row, "select rows", 3), sql.ErrNoRows; got != want {
+		t.Errorf("want=%v, got=%v", want, got)
+	}
+	if got, want := row.Name, "one"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	stmt, err := schema.Prepare(row, "select rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stmt.SelectOne(db, row, 1); err == nil {
+		t.Error("want error for non-pointer row, got nil")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTimestampExpansion(t *testing.T) {
+	type Row struct {
+		ID        int `sql:"primary key"`
+		Name      string
+		CreatedAt time.Time
+		UpdatedAt *time.Time
+	}
+	now := time.Date(2017, 6, 1, 12, 0, 0, 0, time.FixedZone("X", 3600))
+	defer func(f func() time.Time) { timeNow = f }(timeNow)
+	timeNow = func() time.Time { return now }
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+
+	mock.ExpectExec(regexp.QuoteMeta(`insert into rows(id, name, created_at, updated_at) values($1, $2, $3, $4)`)).
+		WithArgs(1, "one", now.UTC(), now.UTC()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	row := Row{ID: 1, Name: "one"}
+	_, err = schema.Exec(db, &row, "insert into rows(id, name, created_at, updated_at) values(?, ?, {ts CreatedAt}, {ts updated_at})", 1, "one")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`update rows set name = $1, updated_at = $2 where "id"=$3`)).
+		WithArgs("two", now.UTC(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	row.Name = "two"
+	_, err = schema.Exec(db, &row, "update rows set name = ?, updated_at = {ts UpdatedAt} where {}", "two")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	for _, query := range []string{
+		"update rows set name = ?, updated_at = {ts Name} where {}",
+		"update rows set name = ?, updated_at = {ts Missing} where {}",
+		"update rows set name = ? where id = {ts CreatedAt}",
+		"update rows set updated_at = {ts} where {}",
+	} {
+		if _, err := schema.Prepare(Row{}, query); err == nil {
+			t.Errorf("%s: want error, got nil", query)
+		}
+	}
+}
+
+func TestInputColumns(t *testing.T) {
+	type Row struct {
+		ID        int `sql:"primary key autoincrement"`
+		Name      string
+		Version   int `sql:"version"`
+		UpdatedAt time.Time
+	}
+	tests := []struct {
+		query  string
+		inputs string
+	}{
+		{
+			query:  "insert rows",
+			inputs: "[Name Version UpdatedAt]",
+		},
+		{
+			query:  "update rows",
+			inputs: "[Name Version UpdatedAt ID]",
+		},
+		{
+			query:  "update rows set name = ?, updated_at = {ts UpdatedAt} where {}",
+			inputs: "[ID]",
+		},
+		{
+			query:  "delete from rows where {}",
+			inputs: "[ID]",
+		},
+		{
+			query:  "select {} from rows where {}",
+			inputs: "[ID]",
+		},
+		{
+			query:  "select {} from rows where name = ?",
+			inputs: "[]",
+		},
+	}
+	schema := NewSchema(WithDialect(MySQL))
+	for i, tt := range tests {
+		stmt, err := schema.Prepare(Row{}, tt.query)
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		var fields []string
+		for _, col := range stmt.InputColumns() {
+			fields = append(fields, col.Field)
+		}
+		if got, want := fmt.Sprint(fields), tt.inputs; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		if got, want := len(stmt.Columns()), 4; got != want {
+			t.Errorf("%d: want=%d, got=%d", i, want, got)
+		}
+	}
+
+	stmt, err := schema.Prepare(Row{}, "select {} from rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ColumnInfo{
+		Field:         "ID",
+		Column:        "id",
+		PrimaryKey:    true,
+		AutoIncrement: true,
+	}
+	if got := stmt.Columns()[0]; got != want {
+		t.Errorf("want=%+v, got=%+v", want, got)
+	}
+}
+
+func BenchmarkGetArgs(b *testing.B) {
+	type Address struct {
+		Street   string
+		Locality string
+	}
+	type Row struct {
+		ID      int64 `sql:"primary key autoincrement"`
+		Name    string
+		Status  testRole
+		Email   string `sql:"null"`
+		Score   float64
+		Created time.Time
+		Address
+	}
+	stmt, err := NewSchema(WithDialect(Postgres)).Prepare(Row{}, "insert rows")
+	if err != nil {
+		b.Fatal(err)
+	}
+	row := &Row{
+		Name:    "Alice",
+		Status:  testRole(2),
+		Score:   1.5,
+		Created: time.Now(),
+		Address: Address{Street: "1 Main St", Locality: "Springfield"},
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := stmt.getArgs(row, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestInputField(t *testing.T) {
+	type Embedded struct {
+		Code testRole
+	}
+	type Row struct {
+		ID      int64 `sql:"primary key"`
+		Role    testRole
+		Name    string `sql:"null"`
+		Time    time.Time
+		NullStr sql.NullString
+		Any     interface{}
+		*Embedded
+	}
+	row := Row{
+		ID:       1,
+		Role:     testRole(2),
+		Time:     time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC),
+		NullStr:  sql.NullString{String: "x", Valid: true},
+		Any:      testRole(3),
+		Embedded: &Embedded{Code: testRole(4)},
+	}
+	rowType := reflect.TypeOf(row)
+	rowVal := reflect.ValueOf(row)
+	for _, col := range column.ListForType(rowType) {
+		field := newInputField(col, rowType)
+		v := field.value(rowVal)
+		if got, want := v.Interface(), col.Index.ValueRO(rowVal).Interface(); got != want {
+			t.Errorf("%s: want=%v, got=%v", col.FieldNames, want, got)
+		}
+		if got, want := field.primitive(v), primitiveValue(v.Interface()); got != want {
+			t.Errorf("%s: want=%#v, got=%#v", col.FieldNames, want, got)
+		}
+	}
+}
+
+func TestTimePointer(t *testing.T) {
+	type Row struct {
+		ID        int `sql:"primary key"`
+		DeletedAt *time.Time
+		ExpiresAt *time.Time `sql:"null"`
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL))
+	deletedAt := time.Date(2018, 3, 1, 10, 11, 12, 0, time.UTC)
+
+	// nil pointers are passed to the driver as untyped nil
+	rowType := reflect.TypeOf(Row{})
+	for _, col := range column.ListForType(rowType)[1:] {
+		field := newInputField(col, rowType)
+		if got := field.primitive(field.value(reflect.ValueOf(Row{}))); got != nil {
+			t.Errorf("%s: want nil, got=%#v", col.FieldNames, got)
+		}
+	}
+
+	insert := regexp.QuoteMeta("insert into rows(`id`,`deleted_at`,`expires_at`) values(?,?,?)")
+	mock.ExpectExec(insert).WithArgs(1, nil, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(insert).WithArgs(2, deletedAt, deletedAt).WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, row := range []*Row{
+		{ID: 1},
+		{ID: 2, DeletedAt: &deletedAt, ExpiresAt: &deletedAt},
+	} {
+		if _, err := schema.Exec(db, row, "insert into rows({}) values({})"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`deleted_at`,`expires_at` from rows order by id")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at", "expires_at"}).
+			AddRow(1, nil, nil).
+			AddRow(2, deletedAt, deletedAt))
+	var rows []*Row
+	if _, err := schema.Select(db, &rows, "select {} from rows order by id"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(rows), 2; got != want {
+		t.Fatalf("want=%d, got=%d", want, got)
+	}
+	if rows[0].DeletedAt != nil || rows[0].ExpiresAt != nil {
+		t.Errorf("want nil, got=%v, %v", rows[0].DeletedAt, rows[0].ExpiresAt)
+	}
+	for _, tm := range []*time.Time{rows[1].DeletedAt, rows[1].ExpiresAt} {
+		if tm == nil || !tm.Equal(deletedAt) {
+			t.Errorf("want=%v, got=%v", deletedAt, tm)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEmptyNullPrimaryKey(t *testing.T) {
+	type Row struct {
+		ID    int    `sql:"primary key null"`
+		Code  string `sql:"primary key"`
+		Name  string
+		Count int `sql:"null"`
+	}
+	stmt, err := NewSchema(WithDialect(MySQL), WithEmptyStringAsNull()).Prepare(Row{}, "update rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err := stmt.getArgs(&Row{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// update set name, count where id, code
+	want := []interface{}{nil, nil, 0, ""}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("want=%#v, got=%#v", want, args)
+	}
+}
+
+func TestForDialect(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key autoincrement"`
+		Name string
+	}
+	stmt, err := NewSchema(WithDialect(MySQL)).Prepare(Row{}, "update users set {} where {} and name <> ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{MySQL, "update users set `name`=? where `id`=? and name <> ?"},
+		{Postgres, `update users set "name"=$1 where "id"=$2 and name <> $3`},
+		{MSSQL, "update users set [name]=? where [id]=? and name <> ?"},
+	}
+	for i, tt := range tests {
+		variant, err := stmt.ForDialect(tt.dialect)
+		if err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+			continue
+		}
+		if got := variant.String(); got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+		if got, want := len(variant.inputs), 3; got != want {
+			t.Errorf("%d: want=%d inputs, got=%d", i, want, got)
+		}
+		if variant.tokens != stmt.tokens {
+			t.Errorf("%d: want the source query tokens to be reused", i)
+		}
+	}
+	colon, err := stmt.ForDialect(NewCustomDialect("oracle", WithPlaceholderStyle(DialectPlaceholderColon)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := colon.String(), `update users set "name"=:1 where "id"=:2 and name <> :3`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+	if stmt.String() != tests[0].want {
+		t.Errorf("original statement changed: %q", stmt.String())
+	}
+	if _, err := stmt.ForDialect(nil); err == nil {
+		t.Error("want error for nil dialect, got nil")
+	}
+}
+
+func TestEscapedQuestionMark(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Data string
+	}
+	schema := NewSchema(WithDialect(Postgres))
+	stmt, err := schema.Prepare(Row{}, "select {} from rows where data ?? 'a' and data ??| '{b,c?}' and name <> '?' and id in (?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), `select "id","data" from rows where data ? 'a' and data ?| '{b,c?}' and name <> '?' and id in ($1)`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+	if got, want := stmt.argCount, 1; got != want {
+		t.Errorf("want=%d args, got=%d", want, got)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(regexp.QuoteMeta(`select "id","data" from rows where data ? 'a' and data ?| '{b,c?}' and name <> '?' and id in ($1,$2)`)).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data"}).AddRow(1, "{}"))
+	var rows []Row
+	if _, err := stmt.Select(db, &rows, []int{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	_, err = NewSchema(WithDialect(MySQL)).Prepare(Row{}, "select {} from rows where data ?? 'a'")
+	if got, want := fmt.Sprint(err), `cannot use "??" for a question mark: dialect uses "?" for placeholders`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
+
+func TestWindowColumns(t *testing.T) {
+	type Row struct {
+		ID    int `sql:"primary key"`
+		Name  string
+		Score int
+		Rank  int `sql:"rn window"`
+	}
+	schema := NewSchema(WithDialect(MySQL))
+	selectQuery := "select {}, row_number() over (order by score desc) as rn from players order by {pk}"
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: "insert players",
+			want:  "insert into players(`id`,`name`,`score`) values(?,?,?)",
+		},
+		{
+			query: "update players",
+			want:  "update players set `name`=?,`score`=? where `id`=?",
+		},
+		{
+			query: "select {all} from players where {}",
+			want:  "select `id`,`name`,`score` from players where `id`=?",
+		},
+		{
+			query: selectQuery,
+			want:  "select `id`,`name`,`score`, row_number() over (order by score desc) as rn from players order by `id`",
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := schema.Prepare(Row{}, tt.query)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := stmt.String(), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name`,`score`, row_number() over")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "score", "rn"}).
+			AddRow(1, "one", 10, 2).
+			AddRow(2, "two", 20, 1))
+
+	var rows []Row
+	if _, err := schema.Select(db, &rows, selectQuery); err != nil {
+		t.Fatal(err)
+	}
+	want := []Row{
+		{ID: 1, Name: "one", Score: 10, Rank: 2},
+		{ID: 2, Name: "two", Score: 20, Rank: 1},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("want=%+v, got=%+v", want, rows)
+	}
+
+	// a query that does not compute the window column leaves it unset
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name`,`score` from players where `id`=?")).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "score"}).
+			AddRow(2, "two", 20))
+	rows = nil
+	if _, err := schema.Select(db, &rows, "select {} from players where {}", 2); err != nil {
+		t.Fatal(err)
+	}
+	want = []Row{
+		{ID: 2, Name: "two", Score: 20},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("want=%+v, got=%+v", want, rows)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLazyColumns(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+		Body string `sql:"body lazy"`
+	}
+	schema := NewSchema(WithDialect(MySQL))
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: "insert documents",
+			want:  "insert into documents(`id`,`name`,`body`) values(?,?,?)",
+		},
+		{
+			query: "update documents",
+			want:  "update documents set `name`=?,`body`=? where `id`=?",
+		},
+		{
+			query: "select {} from documents",
+			want:  "select `id`,`name` from documents",
+		},
+		{
+			query: "select {alias d} from documents d",
+			want:  "select d.`id`,d.`name` from documents d",
+		},
+		{
+			query: "select {all} from documents where {}",
+			want:  "select `id`,`name`,`body` from documents where `id`=?",
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := schema.Prepare(Row{}, tt.query)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := stmt.String(), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name` from documents")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "one"))
+	mock.ExpectQuery(regexp.QuoteMeta("select `id`,`name`,`body` from documents")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "body"}).AddRow(1, "one", "text"))
+
+	var rows []Row
+	if _, err := schema.Select(db, &rows, "select {} from documents"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rows, []Row{{ID: 1, Name: "one"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want=%+v, got=%+v", want, got)
+	}
+	rows = nil
+	if _, err := schema.Select(db, &rows, "select {all} from documents"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rows, []Row{{ID: 1, Name: "one", Body: "text"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want=%+v, got=%+v", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+type tableAliasRow struct {
+	ID   int `sql:"primary key"`
+	Name string
+}
+
+func (r tableAliasRow) TableAlias() string { return "u" }
+
+func TestTableAlias(t *testing.T) {
+	type noAliasRow struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	schema := NewSchema(WithDialect(MySQL))
+	tests := []struct {
+		row     interface{}
+		query   string
+		want    string
+		wantErr string
+	}{
+		{
+			row:   tableAliasRow{},
+			query: "select {alias} from users u where {alias}",
+			want:  "select u.`id`,u.`name` from users u where u.`id`=?",
+		},
+		{
+			row:   &tableAliasRow{},
+			query: "select {alias, pk} from users u order by {alias pk}",
+			want:  "select u.`id` from users u order by u.`id`",
+		},
+		{
+			// an explicit alias takes precedence
+			row:   tableAliasRow{},
+			query: "select {alias x} from users x",
+			want:  "select x.`id`,x.`name` from users x",
+		},
+		{
+			row:     noAliasRow{},
+			query:   "select {alias pk} from users u",
+			wantErr: `cannot expand "alias pk" in "select columns" clause: missing ident after 'alias'`,
+		},
+	}
+	for i, tt := range tests {
+		stmt, err := schema.Prepare(tt.row, tt.query)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%d: want error %q, got %v", i, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := stmt.String(), tt.want; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+	}
+
+	columns, err := schema.ColumnsSQL(tableAliasRow{}, "alias")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := columns, "u.`id`,u.`name`"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
+
+func TestGeneratedIdentity(t *testing.T) {
+	type Row struct {
+		ID   int64 `sql:"primary key generated always as identity"`
+		Name string
+	}
+	schema := NewSchema(WithDialect(Postgres))
+	stmt, err := schema.Prepare(Row{}, "insert into identity_rows({}) values({})")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stmt.String(), `insert into identity_rows("name") values($1)`; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+}
+
+func TestLastColumns(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(Postgres))
+	stmt, err := schema.Prepare(Row{}, "select id, full_name from rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stmt.LastColumns(); got != nil {
+		t.Errorf("want=nil, got=%q", got)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("select id, full_name from rows")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "full_name"}).AddRow(1, "x"))
+	var rows []Row
+	if _, err := stmt.Select(db, &rows); err == nil {
+		t.Error("want error, got nil")
+	}
+	if got, want := strings.Join(stmt.LastColumns(), ","), "id,full_name"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("select id, full_name from rows")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "x"))
+	if _, err := stmt.Select(db, &rows); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(stmt.LastColumns(), ","), "id,name"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithPlaceholderOffset(t *testing.T) {
+	type Row struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	tests := []struct {
+		dialect Dialect
+		offset  int
+		want    string
+	}{
+		{Postgres, 0, `select "id","name" from rows where "id"=$1 and name <> $2`},
+		{Postgres, 3, `select "id","name" from rows where "id"=$4 and name <> $5`},
+		{MySQL, 3, "select `id`,`name` from rows where `id`=? and name <> ?"},
+	}
+	for i, tt := range tests {
+		stmt, err := NewSchema(WithDialect(tt.dialect)).Prepare(Row{}, "select {} from rows where {} and name <> ?")
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		variant, err := stmt.WithPlaceholderOffset(tt.offset)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got := variant.String(); got != tt.want {
+			t.Errorf("%d: want=%q, got=%q", i, tt.want, got)
+		}
+		if tt.offset > 0 {
+			if variant, err = variant.ForDialect(SQLite); err != nil {
+				t.Errorf("%d: %v", i, err)
+				continue
+			}
+			if got, want := variant.placeholderOffset, tt.offset; got != want {
+				t.Errorf("%d: want=%d, got=%d", i, want, got)
+			}
+			var rows []*Row
+			_, err = variant.Select(nil, &rows, "x")
+			if got, want := fmt.Sprint(err), "cannot execute a statement with a placeholder offset"; got != want {
+				t.Errorf("%d: want=%q, got=%q", i, want, got)
+			}
+		}
+	}
+	stmt, err := NewSchema(WithDialect(Postgres)).Prepare(Row{}, "select {} from rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stmt.WithPlaceholderOffset(-1); err == nil {
+		t.Error("want error for negative offset, got nil")
+	}
+}
@@ -0,0 +1,141 @@
+package sqlr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// TransformFunc populates dst from src, where src and dst are pointers to
+// structs of the types passed to RegisterTransform.
+type TransformFunc func(src, dst interface{})
+
+// transformKey identifies a transform by its source and destination types.
+type transformKey struct {
+	from reflect.Type
+	to   reflect.Type
+}
+
+// transformMap contains the transforms registered with a schema.
+type transformMap struct {
+	mu    sync.RWMutex
+	funcs map[transformKey]TransformFunc
+}
+
+func (tm *transformMap) set(key transformKey, fn TransformFunc) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if fn == nil {
+		delete(tm.funcs, key)
+		return
+	}
+	if tm.funcs == nil {
+		tm.funcs = make(map[transformKey]TransformFunc)
+	}
+	tm.funcs[key] = fn
+}
+
+func (tm *transformMap) lookup(key transformKey) TransformFunc {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.funcs[key]
+}
+
+// copyTo copies the transforms into another transform map.
+func (tm *transformMap) copyTo(other *transformMap) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	for key, fn := range tm.funcs {
+		other.set(key, fn)
+	}
+}
+
+// structType returns the struct type for t, which can be a struct
+// type or a pointer to a struct type.
+func structType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// RegisterTransform registers the function that SelectInto uses to populate a
+// struct of type to from a struct of type from. The types can be struct types
+// or pointers to struct types. A nil fn removes the transform.
+func (s *Schema) RegisterTransform(from, to reflect.Type, fn func(src, dst interface{})) {
+	key := transformKey{from: structType(from), to: structType(to)}
+	s.transforms.set(key, fn)
+}
+
+// SelectInto executes a SELECT query and stores the result in src, in the same way
+// as Select. It then populates dst with the result of applying the transform
+// registered for the src and dst types to each row. It returns the number of rows
+// returned by the query.
+//
+// Both src and dst must be pointers to slices (of structs or struct pointers), or
+// both must be pointers to structs. When they are slices, one item is appended to
+// dst for each row returned by the query:
+//  schema.RegisterTransform(reflect.TypeOf(UserRow{}), reflect.TypeOf(UserView{}),
+//      func(src, dst interface{}) {
+//          row, view := src.(*UserRow), dst.(*UserView)
+//          view.DisplayName = row.GivenName + " " + row.FamilyName
+//      })
+//
+//  var rows []UserRow
+//  var views []UserView
+//  n, err := schema.SelectInto(db, &rows, &views, "select {} from users")
+func (s *Schema) SelectInto(db DB, src, dst interface{}, query string, args ...interface{}) (int, error) {
+	srcValue := reflect.ValueOf(src)
+	dstValue := reflect.ValueOf(dst)
+	if srcValue.Kind() != reflect.Ptr || srcValue.IsNil() || dstValue.Kind() != reflect.Ptr || dstValue.IsNil() {
+		return 0, errors.New("expected src and dst to be pointers")
+	}
+	srcValue, dstValue = srcValue.Elem(), dstValue.Elem()
+	isSlice := srcValue.Kind() == reflect.Slice
+	if isSlice != (dstValue.Kind() == reflect.Slice) {
+		return 0, errors.New("expected src and dst to both be slices, or both be structs")
+	}
+	srcType, dstType := srcValue.Type(), dstValue.Type()
+	if isSlice {
+		srcType, dstType = srcType.Elem(), dstType.Elem()
+	}
+	srcStruct, dstStruct := structType(srcType), structType(dstType)
+	if srcStruct.Kind() != reflect.Struct || dstStruct.Kind() != reflect.Struct {
+		return 0, errors.New("expected src and dst to refer to struct types")
+	}
+	fn := s.transforms.lookup(transformKey{from: srcStruct, to: dstStruct})
+	if fn == nil {
+		return 0, fmt.Errorf("no transform registered from %s to %s", srcStruct, dstStruct)
+	}
+
+	// rows are appended to any rows already in src
+	start := 0
+	if isSlice {
+		start = srcValue.Len()
+	}
+	n, err := s.Select(db, src, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	if !isSlice {
+		if n > 0 {
+			fn(src, dst)
+		}
+		return n, nil
+	}
+
+	for i := start; i < srcValue.Len(); i++ {
+		srcRow := srcValue.Index(i)
+		if srcRow.Kind() != reflect.Ptr {
+			srcRow = srcRow.Addr()
+		}
+		dstRow := reflect.New(dstStruct)
+		fn(srcRow.Interface(), dstRow.Interface())
+		if dstType.Kind() != reflect.Ptr {
+			dstRow = dstRow.Elem()
+		}
+		dstValue.Set(reflect.Append(dstValue, dstRow))
+	}
+	return n, nil
+}
@@ -0,0 +1,88 @@
+package sqlr
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+type transformUserRow struct {
+	ID         int `sql:"primary key"`
+	GivenName  string
+	FamilyName string
+}
+
+type transformUserView struct {
+	ID          int
+	DisplayName string
+}
+
+func TestSelectInto(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := NewSchema(WithDialect(MySQL))
+	schema.RegisterTransform(reflect.TypeOf(transformUserRow{}), reflect.TypeOf(&transformUserView{}),
+		func(src, dst interface{}) {
+			row, view := src.(*transformUserRow), dst.(*transformUserView)
+			view.ID = row.ID
+			view.DisplayName = row.GivenName + " " + row.FamilyName
+		})
+	query := regexp.QuoteMeta("select `id`,`given_name`,`family_name` from users")
+	newRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "given_name", "family_name"}).
+			AddRow(1, "Alice", "Smith").
+			AddRow(2, "Bob", "Jones")
+	}
+
+	// slice of structs into slice of struct pointers
+	mock.ExpectQuery(query).WillReturnRows(newRows())
+	var rows []transformUserRow
+	var views []*transformUserView
+	n, err := schema.SelectInto(db, &rows, &views, "select {} from users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := n, 2; got != want {
+		t.Errorf("want=%d, got=%d", want, got)
+	}
+	if got, want := len(views), 2; got != want {
+		t.Fatalf("want=%d, got=%d", want, got)
+	}
+	if got, want := views[1].DisplayName, "Bob Jones"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	// single struct, using a cloned schema
+	mock.ExpectQuery(query + " where `id`=?").WithArgs(1).WillReturnRows(newRows())
+	var row transformUserRow
+	var view transformUserView
+	if _, err := schema.Clone().SelectInto(db, &row, &view, "select {} from users where {}", 1); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := view, (transformUserView{ID: 1, DisplayName: "Alice Smith"}); got != want {
+		t.Errorf("want=%+v, got=%+v", want, got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	errorTests := []struct {
+		src, dst interface{}
+		want     string
+	}{
+		{&rows, views, "expected src and dst to be pointers"},
+		{&rows, &view, "expected src and dst to both be slices, or both be structs"},
+		{&view, &row, "no transform registered from sqlr.transformUserView to sqlr.transformUserRow"},
+	}
+	for i, tt := range errorTests {
+		_, err := schema.SelectInto(db, tt.src, tt.dst, "select {} from users")
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%d: want=%q, got=%v", i, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package sqlr
+
+// Truncate removes all rows from the table for the row type. The row argument
+// can be a struct, a pointer to a struct, or a slice of structs. The table name
+// is worked out in the same way as for the "insert" shorthand: if the schema has
+// a shard key and row has a value for it, the table is the shard's table.
+// Otherwise the table name is obtained from the row type's TableName() method if
+// it has one, or else it is the name of the row type converted using the schema's
+// naming convention, and pluralized if the row type was registered using WithTypes.
+// The table name is renamed by WithIdentifier and quoted in the same way as a
+// table name in any other query.
+//
+// For dialects where "truncate table" is safe to use inside a transaction
+// (Postgres and MSSQL), the table is truncated. For other dialects, the
+// rows are deleted using "delete from". In MySQL "truncate table" commits any
+// active transaction, and SQLite does not support "truncate table".
+//
+// Truncate is intended for test setup and data reset operations.
+func (s *Schema) Truncate(db DB, row interface{}) error {
+	rowType, err := inferRowType(row)
+	if err != nil {
+		return err
+	}
+	table, err := s.rowTableName(row, rowType)
+	if err != nil {
+		return err
+	}
+	query := "delete from " + table
+	if d, ok := s.getDialect().(interface {
+		TruncateTable() bool
+	}); ok && d.TruncateTable() {
+		query = "truncate table " + table
+	}
+
+	// the query is rendered, but not cached or checked like a query passed to
+	// Prepare, which would not permit a delete without a where clause
+	stmt, err := s.newStmt(rowType, query)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(stmt.query)
+	return err
+}
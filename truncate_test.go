@@ -0,0 +1,85 @@
+package sqlr
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestTruncate(t *testing.T) {
+	type UserRow struct {
+		ID   int `sql:"primary key"`
+		Name string
+	}
+	tests := []struct {
+		dialect Dialect
+		row     interface{}
+		query   string
+	}{
+		{
+			dialect: Postgres,
+			row:     UserRow{},
+			query:   "truncate table user_row",
+		},
+		{
+			dialect: MSSQL,
+			row:     &tableNameRow{},
+			query:   "truncate table table_name_rows",
+		},
+		{
+			dialect: MySQL,
+			row:     []tableNameRow{},
+			query:   "delete from table_name_rows",
+		},
+		{
+			dialect: SQLite,
+			row:     UserRow{},
+			query:   "delete from user_row",
+		},
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for i, tt := range tests {
+		mock.ExpectExec(regexp.QuoteMeta(tt.query)).WillReturnResult(sqlmock.NewResult(0, 10))
+		if err := NewSchema(WithDialect(tt.dialect)).Truncate(db, tt.row); err != nil {
+			t.Errorf("%d: want no error, got %v", i, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	// the table name is resolved and renamed in the same way as for "insert"
+	type Widget struct {
+		ID     int `sql:"primary key"`
+		UserID int
+	}
+	schema := NewSchema(
+		WithDialect(Postgres),
+		WithTypes(Widget{}),
+		WithIdentifier("app_widgets", "widgets"),
+	)
+	mock.ExpectExec(regexp.QuoteMeta("truncate table app_widgets")).WillReturnResult(sqlmock.NewResult(0, 10))
+	if err := schema.Truncate(db, Widget{}); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+	sharded := schema.Clone(WithShardKey("UserID", func(v interface{}) string {
+		return fmt.Sprintf("widgets_%d", v.(int)%4)
+	}))
+	mock.ExpectExec(regexp.QuoteMeta("truncate table widgets_1")).WillReturnResult(sqlmock.NewResult(0, 10))
+	if err := sharded.Truncate(db, &Widget{UserID: 5}); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	if err := NewSchema().Truncate(db, 1); err == nil {
+		t.Error("want error for non-struct row, got nil")
+	}
+}
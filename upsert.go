@@ -0,0 +1,238 @@
+package sqlr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jjeffery/sqlr/private/column"
+	"github.com/jjeffery/sqlr/private/scanner"
+)
+
+// Upsert inserts row into the table, or if a row with the same primary key
+// already exists, updates it. The row must be a pointer to a struct, and its
+// primary key fields must be set. The statement depends on the dialect:
+//
+//  Postgres, SQLite: insert into t(...) values(...) on conflict(id) do update set ...
+//  MySQL, MariaDB:   insert into t(...) values(...) on duplicate key update ...
+//  MSSQL:            merge t with (holdlock) as target using ... when matched then
+//                    update set ... when not matched then insert (...) values (...);
+//
+// Columns that are immutable are inserted, but are not updated. SQLite supports
+// upserts from version 3.24.0. Other dialects return an error.
+//
+// If tableName is empty, the table name is obtained from the row type's
+// TableName method if it has one, otherwise it is the name of the row type
+// converted using the schema's naming convention.
+//
+// Upsert returns the number of rows affected reported by the database. Note
+// that MySQL reports two rows affected when an existing row is updated.
+func (s *Schema) Upsert(db DB, row interface{}, tableName string) (int, error) {
+	return s.upsert(db, row, tableName, nil, nil)
+}
+
+// UpsertColumns is like Upsert, but with control over the columns that detect
+// a conflict with an existing row, and the columns that are updated when there
+// is a conflict. Columns are specified by their names in the database, eg:
+//
+//  n, err := schema.UpsertColumns(db, row, "settings",
+//      []string{"key"}, []string{"value", "updated_at"})
+//
+// The conflict columns must have a unique index. MySQL and MariaDB do not
+// support conflict columns in the statement, so any unique index on the table
+// detects the conflict. The update columns must be columns that can appear in
+// the set clause of an update statement, so they cannot be primary key or
+// immutable columns. If there are no update columns, an existing row is left
+// unchanged.
+func (s *Schema) UpsertColumns(db DB, row interface{}, tableName string, conflictCols, updateCols []string) (int, error) {
+	if len(conflictCols) == 0 {
+		return 0, errors.New("expected at least one conflict column")
+	}
+	return s.upsert(db, row, tableName, conflictCols, updateCols)
+}
+
+// upsert implements Upsert and UpsertColumns. If conflictCols is nil, a conflict
+// is detected on the primary key and all of the columns in the update set clause
+// are updated.
+func (s *Schema) upsert(db DB, row interface{}, tableName string, conflictCols, updateCols []string) (int, error) {
+	rowValue := reflect.ValueOf(row)
+	if rowValue.Kind() != reflect.Ptr || rowValue.IsNil() || rowValue.Elem().Kind() != reflect.Struct {
+		return 0, errors.New("expected row to be a pointer to a struct")
+	}
+	rowType := rowValue.Elem().Type()
+	if tableName == "" {
+		tableName = s.tableName(rowType)
+	}
+	dialect := s.getDialect()
+	var style string
+	if d, ok := dialect.(interface {
+		UpsertStyle() string
+	}); ok {
+		style = d.UpsertStyle()
+	}
+	if style == "" {
+		return 0, errors.New("upsert is not supported by the dialect")
+	}
+
+	cols := s.columnsForType(rowType)
+	var pkCols []*column.Info
+	for _, col := range cols {
+		if col.Tag.AutoIncrement {
+			return 0, fmt.Errorf("cannot upsert %s: field %q is auto-increment", rowType.Name(), col.FieldNames)
+		}
+		if col.Tag.PrimaryKey {
+			pkCols = append(pkCols, col)
+		}
+	}
+	setList, err := newColumns(cols).Parse(clauseUpdateSet, "")
+	if err != nil {
+		return 0, err
+	}
+	setCols := setList.filtered()
+	namer := s.columnNamerForTable(tableName)
+
+	keyCols, updCols := pkCols, setCols
+	if conflictCols == nil {
+		if len(pkCols) == 0 {
+			return 0, fmt.Errorf("no primary key for type %s", rowType.Name())
+		}
+	} else {
+		keyCols = nil
+		for _, name := range conflictCols {
+			col := upsertColumn(cols, namer, name)
+			if col == nil {
+				return 0, fmt.Errorf("cannot upsert %s: unknown column %q", rowType.Name(), name)
+			}
+			keyCols = append(keyCols, col)
+		}
+		updCols = nil
+		for _, name := range updateCols {
+			col := upsertColumn(setCols, namer, name)
+			if col == nil {
+				if upsertColumn(cols, namer, name) != nil {
+					return 0, fmt.Errorf("cannot upsert %s: column %q cannot be updated", rowType.Name(), name)
+				}
+				return 0, fmt.Errorf("cannot upsert %s: unknown column %q", rowType.Name(), name)
+			}
+			updCols = append(updCols, col)
+		}
+	}
+
+	quote := func(col *column.Info) string {
+		return s.quoteMode.quote(dialect, namer.ColumnName(col))
+	}
+	query := upsertQuery(style, tableName, keyCols, setCols, updCols, quote)
+	stmt, err := s.Prepare(row, query)
+	if err != nil {
+		return 0, err
+	}
+	if db, err = s.lazyDB(db); err != nil {
+		return 0, err
+	}
+	var keys []interface{}
+	if style == "merge" {
+		// the key values are only used in the merge condition
+		for _, col := range keyCols {
+			var key interface{}
+			if v := fieldValue(col, rowValue.Elem()); v.IsValid() {
+				key = v.Interface()
+			}
+			keys = append(keys, key)
+		}
+	}
+	return stmt.Exec(db, row, keys...)
+}
+
+// upsertColumn returns the column in cols with the column name, which
+// may be quoted, or nil if there is no such column.
+func upsertColumn(cols []*column.Info, namer columnNamer, name string) *column.Info {
+	name = scanner.Unquote(strings.TrimSpace(name))
+	for _, col := range cols {
+		if col.BitGroup == "" && strings.EqualFold(namer.ColumnName(col), name) {
+			return col
+		}
+	}
+	return nil
+}
+
+// upsertQuery returns the query for Upsert in the dialect's style. The merge
+// statement has a placeholder for each of the key columns, in order. The setCols
+// are the columns in the update set clause, and updateCols are the columns that
+// are updated, which is a subset of setCols.
+func upsertQuery(style string, tableName string, keyCols, setCols, updateCols []*column.Info, quote func(*column.Info) string) string {
+	var buf bytes.Buffer
+	switch style {
+	case "merge":
+		fmt.Fprintf(&buf, "merge %s with (holdlock) as target"+
+			" using (values (1)) as source (sqlr_dummy) on ", tableName)
+		for i, col := range keyCols {
+			if i > 0 {
+				buf.WriteString(" and ")
+			}
+			fmt.Fprintf(&buf, "target.%s = ?", quote(col))
+		}
+		if len(updateCols) > 0 {
+			buf.WriteString(" when matched then update set {")
+			if omit := upsertOmit(setCols, updateCols); len(omit) > 0 {
+				buf.WriteString("omit ")
+				buf.WriteString(strings.Join(omit, ","))
+			}
+			buf.WriteString("}")
+		}
+		// merge statements must be terminated with a semicolon
+		buf.WriteString(" when not matched then insert ({}) values ({});")
+	case "on duplicate key":
+		fmt.Fprintf(&buf, insertFormat, tableName)
+		buf.WriteString(" on duplicate key update ")
+		if len(updateCols) == 0 {
+			// there is nothing to update, so leave the key unchanged
+			updateCols = keyCols[:1]
+		}
+		for i, col := range updateCols {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			fmt.Fprintf(&buf, "%s=values(%s)", quote(col), quote(col))
+		}
+	default:
+		var keyNames []string
+		for _, col := range keyCols {
+			keyNames = append(keyNames, quote(col))
+		}
+		fmt.Fprintf(&buf, insertFormat, tableName)
+		fmt.Fprintf(&buf, " on conflict(%s) do ", strings.Join(keyNames, ","))
+		if len(updateCols) == 0 {
+			buf.WriteString("nothing")
+			break
+		}
+		buf.WriteString("update set ")
+		for i, col := range updateCols {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			fmt.Fprintf(&buf, "%s=excluded.%s", quote(col), quote(col))
+		}
+	}
+	return buf.String()
+}
+
+// upsertOmit returns the field names of the columns in setCols
+// that are not in updateCols.
+func upsertOmit(setCols, updateCols []*column.Info) []string {
+	var omit []string
+	for _, col := range setCols {
+		found := false
+		for _, updateCol := range updateCols {
+			if updateCol == col {
+				found = true
+				break
+			}
+		}
+		if !found {
+			omit = append(omit, col.FieldNames)
+		}
+	}
+	return omit
+}
@@ -0,0 +1,232 @@
+package sqlr
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestUpsert(t *testing.T) {
+	type Widget struct {
+		ID      string `sql:"primary key"`
+		Name    string
+		Created int `sql:"created immutable"`
+	}
+	type Tag struct {
+		ID string `sql:"primary key"`
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		dialect  Dialect
+		quote    QuoteMode
+		row      interface{}
+		wantSQL  string
+		wantArgs []driver.Value
+	}{
+		{
+			dialect:  Postgres,
+			row:      &Widget{ID: "w1", Name: "one", Created: 5},
+			wantSQL:  `insert into widgets("id","name","created") values($1,$2,$3) on conflict("id") do update set "name"=excluded."name"`,
+			wantArgs: []driver.Value{"w1", "one", 5},
+		},
+		{
+			dialect:  Postgres,
+			quote:    QuoteNever,
+			row:      &Widget{ID: "w1", Name: "one", Created: 5},
+			wantSQL:  `insert into widgets(id,name,created) values($1,$2,$3) on conflict(id) do update set name=excluded.name`,
+			wantArgs: []driver.Value{"w1", "one", 5},
+		},
+		{
+			dialect:  SQLite,
+			row:      &Tag{ID: "t1"},
+			wantSQL:  "insert into widgets(`id`) values(?) on conflict(`id`) do nothing",
+			wantArgs: []driver.Value{"t1"},
+		},
+		{
+			dialect:  MySQL,
+			row:      &Widget{ID: "w1", Name: "one", Created: 5},
+			wantSQL:  "insert into widgets(`id`,`name`,`created`) values(?,?,?) on duplicate key update `name`=values(`name`)",
+			wantArgs: []driver.Value{"w1", "one", 5},
+		},
+		{
+			dialect: MSSQL,
+			row:     &Widget{ID: "w1", Name: "one", Created: 5},
+			wantSQL: "merge widgets with (holdlock) as target using (values (1)) as source (sqlr_dummy)" +
+				" on target.[id] = ?" +
+				" when matched then update set [name]=?" +
+				" when not matched then insert ([id],[name],[created]) values (?,?,?);",
+			wantArgs: []driver.Value{"w1", "one", "w1", "one", 5},
+		},
+		{
+			dialect: MSSQL,
+			row:     &Tag{ID: "t1"},
+			wantSQL: "merge widgets with (holdlock) as target using (values (1)) as source (sqlr_dummy)" +
+				" on target.[id] = ?" +
+				" when not matched then insert ([id]) values (?);",
+			wantArgs: []driver.Value{"t1", "t1"},
+		},
+	}
+	for i, tt := range tests {
+		schema := NewSchema(WithDialect(tt.dialect), WithIdentifierQuoting(tt.quote))
+		mock.ExpectExec(regexp.QuoteMeta(tt.wantSQL)).
+			WithArgs(tt.wantArgs...).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		n, err := schema.Upsert(db, tt.row, "widgets")
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		if got, want := n, 1; got != want {
+			t.Errorf("%d: want=%d, got=%d", i, want, got)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpsertErrors(t *testing.T) {
+	type Widget struct {
+		ID   int `sql:"primary key autoincrement"`
+		Name string
+	}
+	type NoKey struct {
+		Name string
+	}
+	tests := []struct {
+		dialect Dialect
+		row     interface{}
+		wantErr string
+	}{
+		{
+			dialect: ANSISQL,
+			row:     &NoKey{},
+			wantErr: "upsert is not supported by the dialect",
+		},
+		{
+			dialect: Postgres,
+			row:     &Widget{},
+			wantErr: `cannot upsert Widget: field "ID" is auto-increment`,
+		},
+		{
+			dialect: Postgres,
+			row:     &NoKey{},
+			wantErr: "no primary key for type NoKey",
+		},
+		{
+			dialect: Postgres,
+			row:     NoKey{},
+			wantErr: "expected row to be a pointer to a struct",
+		},
+	}
+	for i, tt := range tests {
+		_, err := NewSchema(WithDialect(tt.dialect)).Upsert(nil, tt.row, "widgets")
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%d: want=%q, got=%v", i, tt.wantErr, err)
+		}
+	}
+}
+
+func TestUpsertColumns(t *testing.T) {
+	type Setting struct {
+		ID      string `sql:"primary key"`
+		Key     string
+		Value   string
+		Note    string
+		Created int `sql:"created immutable"`
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	row := &Setting{ID: "s1", Key: "k", Value: "v", Note: "n", Created: 5}
+	tests := []struct {
+		dialect  Dialect
+		conflict []string
+		update   []string
+		wantSQL  string
+		wantArgs []driver.Value
+	}{
+		{
+			dialect:  Postgres,
+			conflict: []string{"key"},
+			update:   []string{"value"},
+			wantSQL:  `insert into settings("id","key","value","note","created") values($1,$2,$3,$4,$5) on conflict("key") do update set "value"=excluded."value"`,
+			wantArgs: []driver.Value{"s1", "k", "v", "n", 5},
+		},
+		{
+			dialect:  SQLite,
+			conflict: []string{"id", "`key`"},
+			wantSQL:  "insert into settings(`id`,`key`,`value`,`note`,`created`) values(?,?,?,?,?) on conflict(`id`,`key`) do nothing",
+			wantArgs: []driver.Value{"s1", "k", "v", "n", 5},
+		},
+		{
+			dialect:  MySQL,
+			conflict: []string{"key"},
+			update:   []string{"note", "value"},
+			wantSQL:  "insert into settings(`id`,`key`,`value`,`note`,`created`) values(?,?,?,?,?) on duplicate key update `note`=values(`note`),`value`=values(`value`)",
+			wantArgs: []driver.Value{"s1", "k", "v", "n", 5},
+		},
+		{
+			dialect:  MSSQL,
+			conflict: []string{"key"},
+			update:   []string{"value"},
+			wantSQL: "merge settings with (holdlock) as target using (values (1)) as source (sqlr_dummy)" +
+				" on target.[key] = ?" +
+				" when matched then update set [value]=?" +
+				" when not matched then insert ([id],[key],[value],[note],[created]) values (?,?,?,?,?);",
+			wantArgs: []driver.Value{"k", "v", "s1", "k", "v", "n", 5},
+		},
+	}
+	for i, tt := range tests {
+		schema := NewSchema(WithDialect(tt.dialect))
+		mock.ExpectExec(regexp.QuoteMeta(tt.wantSQL)).
+			WithArgs(tt.wantArgs...).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		if _, err := schema.UpsertColumns(db, row, "settings", tt.conflict, tt.update); err != nil {
+			t.Errorf("%d: %v", i, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	errTests := []struct {
+		conflict []string
+		update   []string
+		wantErr  string
+	}{
+		{
+			wantErr: "expected at least one conflict column",
+		},
+		{
+			conflict: []string{"email"},
+			wantErr:  `cannot upsert Setting: unknown column "email"`,
+		},
+		{
+			conflict: []string{"key"},
+			update:   []string{"created"},
+			wantErr:  `cannot upsert Setting: column "created" cannot be updated`,
+		},
+		{
+			conflict: []string{"key"},
+			update:   []string{"value", "missing"},
+			wantErr:  `cannot upsert Setting: unknown column "missing"`,
+		},
+	}
+	for i, tt := range errTests {
+		_, err := NewSchema(WithDialect(Postgres)).UpsertColumns(nil, row, "settings", tt.conflict, tt.update)
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%d: want=%q, got=%v", i, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package sqlr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// VerifyColumns checks that the columns for the row type match the columns
+// in the database table. It returns an error that lists any fields that do not
+// have a matching column in the table, and any columns in the table that do not
+// have a matching field. Column names are compared without regard to case.
+//
+// VerifyColumns is intended to be called when a program starts, so that
+// differences between the row type and the table are detected before they
+// cause statements to fail. If table is empty, the table name is obtained
+// from the row type's TableName() method if it has one, otherwise it is the
+// name of the row type converted using the schema's naming convention. A table
+// name of the form "schema.table" refers to a table in the named schema.
+//
+// The table's columns are obtained from the information schema, or for SQLite
+// the table_info pragma. For a custom dialect, the information schema is used.
+func (s *Schema) VerifyColumns(db DB, row interface{}, table string) error {
+	rowType, err := inferRowType(row)
+	if err != nil {
+		return err
+	}
+	if table == "" {
+		table = s.tableName(rowType)
+	}
+	missingColumns, missingFields, err := s.compareColumns(db, s.getDialect(), rowType, table)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	if len(missingColumns) > 0 {
+		problems = append(problems, "fields without columns: "+strings.Join(missingColumns, ", "))
+	}
+	if len(missingFields) > 0 {
+		problems = append(problems, "columns without fields: "+strings.Join(missingFields, ", "))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("type %s does not match table %s: %s", rowType.Name(), table, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// compareColumns compares the columns for the row type with the columns in
+// the database table. It returns the fields that do not have a matching column,
+// and the columns that do not have a matching field.
+func (s *Schema) compareColumns(db DB, dialect Dialect, rowType reflect.Type, table string) (missingColumns, missingFields []string, err error) {
+	var query string
+	var args []interface{}
+	if d, ok := dialect.(interface {
+		TableColumnsQuery(table string) (string, []interface{})
+	}); ok {
+		query, args = d.TableColumnsQuery(table)
+	} else {
+		var schema, name = "", table
+		if index := strings.LastIndex(table, "."); index >= 0 {
+			schema, name = table[:index], table[index+1:]
+		}
+		query = fmt.Sprintf("select column_name from information_schema.columns"+
+			" where table_name = %s and %s in ('', table_schema) order by ordinal_position",
+			dialect.Placeholder(1), dialect.Placeholder(2))
+		args = []interface{}{name, schema}
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	tableColumns := make(map[string]string)
+	var tableColumnNames []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, nil, err
+		}
+		tableColumns[strings.ToLower(name)] = name
+		tableColumnNames = append(tableColumnNames, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(tableColumns) == 0 {
+		return nil, nil, fmt.Errorf("table %s not found", table)
+	}
+
+	namer := s.columnNamerForTable(table)
+	bitGroups := make(map[string]bool)
+	for _, col := range s.columnsForType(rowType) {
+		if col.Tag.Window {
+			// not a table column
+			continue
+		}
+		if col.BitGroup != "" {
+			// the fields in a bit group share one column
+			if bitGroups[col.BitGroup] {
+				continue
+			}
+			bitGroups[col.BitGroup] = true
+		}
+		name := namer.ColumnName(col)
+		if _, ok := tableColumns[strings.ToLower(name)]; ok {
+			delete(tableColumns, strings.ToLower(name))
+			continue
+		}
+		missingColumns = append(missingColumns, fmt.Sprintf("%s (%s)", col.FieldNames, name))
+	}
+	for _, name := range tableColumnNames {
+		if _, ok := tableColumns[strings.ToLower(name)]; ok {
+			missingFields = append(missingFields, name)
+		}
+	}
+	return missingColumns, missingFields, nil
+}
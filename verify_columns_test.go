@@ -0,0 +1,87 @@
+package sqlr
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestVerifyColumns(t *testing.T) {
+	type User struct {
+		ID       int `sql:"primary key"`
+		Name     string
+		Nickname string
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		dialect Dialect
+		table   string
+		query   string
+		args    []interface{}
+		columns []string
+		wantErr string
+	}{
+		{
+			dialect: Postgres,
+			table:   "users",
+			query: `select column_name from information_schema.columns where table_name = $1` +
+				` and table_schema = coalesce(nullif($2, ''), current_schema()) order by ordinal_position`,
+			args:    []interface{}{"users", ""},
+			columns: []string{"id", "name", "nickname"},
+		},
+		{
+			dialect: MySQL,
+			table:   "app.users",
+			query: "select column_name from information_schema.columns where table_name = ?" +
+				" and table_schema = coalesce(nullif(?, ''), database()) order by ordinal_position",
+			args:    []interface{}{"users", "app"},
+			columns: []string{"ID", "Name", "created_at"},
+			wantErr: "type User does not match table app.users: fields without columns: Nickname (nickname); columns without fields: created_at",
+		},
+		{
+			dialect: SQLite,
+			table:   "",
+			query:   "select name from pragma_table_info(?, coalesce(nullif(?, ''), 'main'))",
+			args:    []interface{}{"user", ""},
+			wantErr: "table user not found",
+		},
+		{
+			dialect: retryDialect{},
+			table:   "users",
+			query: "select column_name from information_schema.columns where table_name = ?" +
+				" and ? in ('', table_schema) order by ordinal_position",
+			args:    []interface{}{"users", ""},
+			columns: []string{"id", "name", "nickname"},
+		},
+	}
+	for i, tt := range tests {
+		rows := sqlmock.NewRows([]string{"column_name"})
+		for _, col := range tt.columns {
+			rows.AddRow(col)
+		}
+		args := make([]driver.Value, len(tt.args))
+		for j, arg := range tt.args {
+			args[j] = arg
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(tt.query)).WithArgs(args...).WillReturnRows(rows)
+		schema := NewSchema(WithDialect(tt.dialect))
+		err := schema.VerifyColumns(db, User{}, tt.table)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%d: unexpected error: %v", i, err)
+			}
+		} else if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%d: want=%q, got=%v", i, tt.wantErr, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%d: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+//go:build go1.8
+// +build go1.8
+
+package sqlr
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// notifyFunction is the trigger function that sends a notification for each
+// row changed. The first trigger argument is the channel, and the optional
+// second argument is the primary key column.
+const notifyFunction = `create or replace function sqlr_notify() returns trigger as $$
+declare
+	rec record;
+	row_id text;
+begin
+	if TG_OP = 'DELETE' then
+		rec := OLD;
+	else
+		rec := NEW;
+	end if;
+	if TG_NARGS > 1 then
+		row_id := to_jsonb(rec)->>TG_ARGV[1];
+	end if;
+	perform pg_notify(TG_ARGV[0], json_build_object('operation', TG_OP, 'row_id', row_id, 'timestamp', now())::text);
+	return null;
+end;
+$$ language plpgsql`
+
+// primaryKeyQuery returns the first primary key column of a table.
+const primaryKeyQuery = `select a.attname from pg_index i
+join pg_attribute a on a.attrelid = i.indrelid and a.attnum = any(i.indkey)
+where i.indrelid = $1::regclass and i.indisprimary
+order by a.attnum`
+
+// WatchTable returns a channel that receives an event each time a row in the
+// table is inserted, updated or deleted. The events are sent until ctx is done,
+// and then the channel is closed.
+//
+// WatchTable requires a dialect that implements NotifyDialect, which is only
+// Postgres. It installs a trigger on the table that calls pg_notify on the
+// channel "sqlr_" + tableName, and the trigger remains after ctx is done. The
+// notifications are received by the listener provided by the
+// WithNotificationListener option, because the "database/sql" package does
+// not provide a way to receive notifications.
+//
+// The RowID of each event is the value of the table's primary key column. If the
+// primary key has more than one column, only the first column is used.
+func (s *Schema) WatchTable(ctx context.Context, db *sql.DB, tableName string) (<-chan ChangeEvent, error) {
+	if d, ok := s.getDialect().(NotifyDialect); !ok || !d.SupportsNotify() {
+		return nil, errors.New("WatchTable requires the postgres dialect")
+	}
+	if s.notificationListener == nil {
+		return nil, errors.New("WatchTable requires a notification listener, see WithNotificationListener")
+	}
+	channel := "sqlr_" + tableName
+
+	var keyColumn string
+	rows, err := db.QueryContext(ctx, primaryKeyQuery, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if rows.Next() {
+		err = rows.Scan(&keyColumn)
+	} else {
+		err = rows.Err()
+	}
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	triggerArgs := quoteLiteral(channel)
+	if keyColumn != "" {
+		triggerArgs += "," + quoteLiteral(keyColumn)
+	}
+	table := s.QuoteIdentifier(tableName)
+	trigger := s.QuoteIdentifier("sqlr_notify_" + strings.Replace(tableName, ".", "_", -1))
+	statements := []string{
+		notifyFunction,
+		"drop trigger if exists " + trigger + " on " + table,
+		"create trigger " + trigger + " after insert or update or delete on " + table +
+			" for each row execute procedure sqlr_notify(" + triggerArgs + ")",
+	}
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return nil, err
+		}
+	}
+
+	payloads, err := s.notificationListener(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan ChangeEvent)
+	go func() {
+		defer close(events)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case payload, ok := <-payloads:
+				if !ok {
+					return
+				}
+				var event ChangeEvent
+				if err := json.Unmarshal([]byte(payload), &event); err != nil {
+					// not sent by the trigger
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// quoteLiteral quotes a string literal for use in an SQL statement.
+func quoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
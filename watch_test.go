@@ -0,0 +1,95 @@
+//go:build go1.8
+// +build go1.8
+
+package sqlr
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestWatchTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	payloads := make(chan string, 3)
+	var listenChannel string
+	listener := func(ctx context.Context, channel string) (<-chan string, error) {
+		listenChannel = channel
+		return payloads, nil
+	}
+	schema := NewSchema(WithDialect(Postgres), WithNotificationListener(listener))
+
+	mock.ExpectQuery(regexp.QuoteMeta(primaryKeyQuery)).
+		WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"attname"}).AddRow("id"))
+	mock.ExpectExec(regexp.QuoteMeta("create or replace function sqlr_notify()")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`drop trigger if exists "sqlr_notify_users" on "users"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`create trigger "sqlr_notify_users" after insert or update or delete on "users" ` +
+		`for each row execute procedure sqlr_notify('sqlr_users','id')`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := schema.WatchTable(ctx, db, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := listenChannel, "sqlr_users"; got != want {
+		t.Errorf("want=%q, got=%q", want, got)
+	}
+
+	payloads <- `{"operation":"INSERT","row_id":"42","timestamp":"2018-03-01T10:11:12.5+00:00"}`
+	payloads <- `not json`
+	payloads <- `{"operation":"DELETE","row_id":"43","timestamp":"2018-03-01T10:11:13+00:00"}`
+	for i, want := range []string{"INSERT 42", "DELETE 43"} {
+		event := <-events
+		if got := event.Operation + " " + event.RowID; got != want {
+			t.Errorf("%d: want=%q, got=%q", i, want, got)
+		}
+		if event.Timestamp.IsZero() {
+			t.Errorf("%d: want timestamp", i)
+		}
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("want channel closed after cancel")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWatchTableErrors(t *testing.T) {
+	listener := func(ctx context.Context, channel string) (<-chan string, error) {
+		return make(chan string), nil
+	}
+	tests := []struct {
+		schema *Schema
+		want   string
+	}{
+		{
+			schema: NewSchema(WithDialect(MySQL), WithNotificationListener(listener)),
+			want:   "WatchTable requires the postgres dialect",
+		},
+		{
+			schema: NewSchema(WithDialect(Postgres)),
+			want:   "WatchTable requires a notification listener, see WithNotificationListener",
+		},
+	}
+	for i, tt := range tests {
+		_, err := tt.schema.WatchTable(context.Background(), (*sql.DB)(nil), "users")
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%d: want=%q, got=%v", i, tt.want, err)
+		}
+	}
+}